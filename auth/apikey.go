@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiKeyPrefix starts every generated key so leaked keys are easy to recognise (and grep for)
+const apiKeyPrefix = "tdk_"
+
+// apiKeyRequest is the JSON body accepted by CreateAPIKey
+type apiKeyRequest struct {
+	Name  string            `json:"name"`
+	Scope model.APIKeyScope `json:"scope"`
+}
+
+// NewAPIKey generates a random API key, the short prefix shown in listings and the hash to store
+func NewAPIKey() (key string, prefix string, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return key, key[:len(apiKeyPrefix)+8], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the SHA-256 hex digest used to store and look up an API key
+func HashAPIKey(key string) string {
+	return sha256Hex(key)
+}
+
+// CreateAPIKey creates a key for the logged in user
+// The plain key is only returned in this response; afterwards only its hash exists
+func (h *Handler) CreateAPIKey(ctx *gin.Context) {
+	user, ok := sessionUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req apiKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Name is required and must be at most 100 characters"})
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = model.APIKeyScopeRead // Least privilege unless asked otherwise
+	}
+	if req.Scope != model.APIKeyScopeRead && req.Scope != model.APIKeyScopeReadWrite {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Scope must be 'read' or 'read-write'"})
+		return
+	}
+
+	plain, prefix, hash, err := NewAPIKey()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	key := model.APIKey{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scope:     req.Scope,
+		CreatedAt: time.Now(),
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	if err := h.APIKeys.CreateAPIKey(dbCtx, &key); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "API key created successfully. Store it now, it won't be shown again",
+		"api_key": key,
+		"key":     plain,
+	})
+}
+
+// ListAPIKeys returns the logged in user's keys (without their values)
+func (h *Handler) ListAPIKeys(ctx *gin.Context) {
+	user, ok := sessionUser(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	keys, err := h.APIKeys.ListAPIKeys(dbCtx, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "API keys retrieved successfully",
+		"api_keys": keys,
+	})
+}
+
+// RevokeAPIKey revokes one of the logged in user's keys
+// The record is kept (with revoked_at set) so the key still shows up in the list
+func (h *Handler) RevokeAPIKey(ctx *gin.Context) {
+	user, ok := sessionUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	key, err := h.APIKeys.RevokeAPIKey(dbCtx, user.ID, id)
+	if errors.Is(err, store.ErrAPIKeyNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+		"api_key": key,
+	})
+}
+
+// sessionUser returns the user for requests authenticated with a login token
+// API keys can't manage API keys, otherwise a leaked read-only key could mint a read-write one
+func sessionUser(ctx *gin.Context) (model.User, bool) {
+	if _, usingKey := CurrentAPIKey(ctx); usingKey {
+		err := helpers.Forbidden("API keys cannot be managed with an API key, log in instead")
+		ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+		return model.User{}, false
+	}
+
+	user, ok := CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return model.User{}, false
+	}
+	return user, true
+}
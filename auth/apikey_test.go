@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createKey makes an API key with the given scope for the session and returns its plain value and ID
+func (api *testAPI) createKey(session, scope string) (string, string) {
+	api.t.Helper()
+	code, body := api.do(http.MethodPost, "/keys", session, gin.H{"name": "script", "scope": scope})
+	if code != http.StatusCreated {
+		api.t.Fatalf("create %s key: got %d %v", scope, code, body)
+	}
+	return body["key"].(string), body["api_key"].(map[string]interface{})["id"].(string)
+}
+
+func TestAPIKeyIsStoredHashed(t *testing.T) {
+	api := newTestAPI(t)
+	session := "Bearer " + api.register("ada@example.com")["token"].(string)
+	plain, _ := api.createKey(session, "read")
+	if !strings.HasPrefix(plain, apiKeyPrefix) {
+		t.Errorf("key %q doesn't start with %q", plain, apiKeyPrefix)
+	}
+
+	stored, err := api.stores.APIKeys.GetAPIKeyByHash(context.Background(), HashAPIKey(plain))
+	if err != nil {
+		t.Fatalf("key not found by its hash: %v", err)
+	}
+	if stored.KeyHash == plain || !strings.HasPrefix(plain, stored.Prefix) {
+		t.Errorf("stored key = %+v, want only the hash and a prefix of the key", stored)
+	}
+
+	_, body := api.do(http.MethodGet, "/keys", session, nil)
+	if listed := body["api_keys"].([]interface{}); len(listed) != 1 || strings.Contains(fmt.Sprint(body), plain) {
+		t.Errorf("listing = %v, want one key without its value", body)
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	api := newTestAPI(t)
+	session := "Bearer " + api.register("ada@example.com")["token"].(string)
+	read, _ := api.createKey(session, "read")
+	readWrite, _ := api.createKey(session, "read-write")
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		t.Run(method, func(t *testing.T) {
+			wantRead := http.StatusForbidden
+			if IsReadOnlyMethod(method) {
+				wantRead = http.StatusOK
+			}
+			if code, _ := api.do(method, "/things", "ApiKey "+read, nil); code != wantRead {
+				t.Errorf("read key: got %d, want %d", code, wantRead)
+			}
+			if code, _ := api.do(method, "/things", "ApiKey "+readWrite, nil); code != http.StatusOK {
+				t.Errorf("read-write key: got %d, want 200", code)
+			}
+		})
+	}
+	if code, _ := api.do(http.MethodGet, "/things", "ApiKey tdk_unknown", nil); code != http.StatusUnauthorized {
+		t.Errorf("unknown key: got %d, want 401", code)
+	}
+}
+
+func TestAPIKeyCannotManageKeys(t *testing.T) {
+	api := newTestAPI(t)
+	session := "Bearer " + api.register("ada@example.com")["token"].(string)
+	key, id := api.createKey(session, "read-write")
+
+	requests := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodPost, "/keys", gin.H{"name": "minted", "scope": "read-write"}},
+		{http.MethodGet, "/keys", nil},
+		{http.MethodDelete, "/keys/" + id, nil},
+	}
+	for _, req := range requests {
+		if code, body := api.do(req.method, req.path, "ApiKey "+key, req.body); code != http.StatusForbidden {
+			t.Errorf("%s %s with an API key: got %d %v, want 403", req.method, req.path, code, body)
+		}
+	}
+
+	// Revoking from a session works, and the key stops working at once
+	if code, body := api.do(http.MethodDelete, "/keys/"+id, session, nil); code != http.StatusOK {
+		t.Fatalf("revoke: got %d %v", code, body)
+	}
+	if code, _ := api.do(http.MethodGet, "/things", "ApiKey "+key, nil); code != http.StatusUnauthorized {
+		t.Errorf("revoked key: got %d, want 401", code)
+	}
+}
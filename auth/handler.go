@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Handler serves the /auth and /keys endpoints
+type Handler struct {
+	Users         store.UserStore
+	RefreshTokens store.RefreshTokenStore
+	APIKeys       store.APIKeyStore
+	Tokens        *TokenIssuer
+}
+
+// NewHandler creates the auth handlers around the stores and token issuer
+func NewHandler(stores store.Stores, tokens *TokenIssuer) *Handler {
+	return &Handler{
+		Users:         stores.Users,
+		RefreshTokens: stores.RefreshTokens,
+		APIKeys:       stores.APIKeys,
+		Tokens:        tokens,
+	}
+}
+
+// registerRequest is the JSON body accepted by Register
+type registerRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// loginRequest is the JSON body accepted by Login
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// refreshRequest is the JSON body accepted by Refresh and Logout
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Register creates a new user account and logs it in straight away
+func (h *Handler) Register(ctx *gin.Context) {
+	var req registerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Normalize the email so "Me@Example.com" and "me@example.com" are the same account
+	req.Name = strings.TrimSpace(req.Name)
+	req.Email = normalizeEmail(req.Email)
+
+	if err := helpers.ValidateRegistration(req.Name, req.Email, req.Password); err != nil {
+		ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Never store the plain password, only its hash
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	user := model.User{
+		ID:           primitive.NewObjectID(),
+		Name:         req.Name,
+		Email:        req.Email,
+		PasswordHash: hash,
+		Role:         initialRole(req.Email),
+		Metadata: model.Metadata{
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	err = h.Users.CreateUser(dbCtx, &user)
+	if errors.Is(err, store.ErrEmailTaken) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user: " + err.Error()})
+		return
+	}
+
+	// A new login starts a new token family
+	h.respondWithTokens(ctx, http.StatusCreated, "User registered successfully", user, primitive.NewObjectID())
+}
+
+// initialRole picks the role of a new account
+// Emails listed in ADMIN_EMAILS (comma separated) become admins, so the first admin
+// can be bootstrapped without touching the database; everyone else is a member
+func initialRole(email string) model.Role {
+	for _, admin := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if normalizeEmail(admin) == email && email != "" {
+			return model.RoleAdmin
+		}
+	}
+	return model.RoleMember
+}
+
+// Login checks the email and password and returns an access token
+func (h *Handler) Login(ctx *gin.Context) {
+	var req loginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Email = normalizeEmail(req.Email)
+	if req.Email == "" || req.Password == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Email and password are required"})
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	user, err := h.Users.GetUserByEmail(dbCtx, req.Email)
+	if err != nil && !errors.Is(err, store.ErrUserNotFound) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up user: " + err.Error()})
+		return
+	}
+
+	// Use the same message for "no such user" and "wrong password", and run bcrypt either way,
+	// so neither the answer nor its timing tells which emails are registered
+	hash := user.PasswordHash
+	if err != nil {
+		hash = unknownUserHash
+	}
+	if !CheckPassword(hash, req.Password) || err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+
+	h.respondWithTokens(ctx, http.StatusOK, "Logged in successfully", user, primitive.NewObjectID())
+}
+
+// Refresh trades a refresh token for a new access token and a new refresh token
+// The old refresh token is revoked (rotation). If a revoked token shows up again,
+// someone is replaying a stolen copy, so the whole token family is revoked
+func (h *Handler) Refresh(ctx *gin.Context) {
+	var req refreshRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	stored, err := h.RefreshTokens.GetRefreshToken(dbCtx, HashRefreshToken(req.RefreshToken))
+	if errors.Is(err, store.ErrTokenNotFound) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up refresh token: " + err.Error()})
+		return
+	}
+
+	// Reuse detection: a rotated token must never be presented again
+	if stored.RevokedAt != nil {
+		_ = h.RefreshTokens.RevokeRefreshFamily(dbCtx, stored.FamilyID)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has been revoked"})
+		return
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has expired"})
+		return
+	}
+
+	// Revoke first: if two requests race with the same token only one of them wins
+	err = h.RefreshTokens.RevokeRefreshToken(dbCtx, stored.ID)
+	if errors.Is(err, store.ErrTokenNotFound) {
+		_ = h.RefreshTokens.RevokeRefreshFamily(dbCtx, stored.FamilyID)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has been revoked"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate refresh token: " + err.Error()})
+		return
+	}
+
+	user, err := h.Users.GetUserByID(dbCtx, stored.UserID)
+	if errors.Is(err, store.ErrUserNotFound) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User no longer exists"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user: " + err.Error()})
+		return
+	}
+
+	// The new refresh token stays in the same family as the one it replaces
+	h.respondWithTokens(ctx, http.StatusOK, "Token refreshed successfully", user, stored.FamilyID)
+}
+
+// Logout revokes the refresh token and every token rotated from the same login
+// Access tokens already handed out stay valid until they expire, which is why they are short lived
+func (h *Handler) Logout(ctx *gin.Context) {
+	var req refreshRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	stored, err := h.RefreshTokens.GetRefreshToken(dbCtx, HashRefreshToken(req.RefreshToken))
+	if errors.Is(err, store.ErrTokenNotFound) {
+		// Logging out with an unknown token is not an error worth reporting
+		ctx.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up refresh token: " + err.Error()})
+		return
+	}
+
+	if err := h.RefreshTokens.RevokeRefreshFamily(dbCtx, stored.FamilyID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// Me returns the user attached to the request by Middleware
+func (h *Handler) Me(ctx *gin.Context) {
+	user, ok := CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User retrieved successfully",
+		"user":    user,
+	})
+}
+
+// respondWithTokens issues an access token and a refresh token for the user
+// and writes the standard auth response
+func (h *Handler) respondWithTokens(ctx *gin.Context, status int, message string, user model.User, familyID primitive.ObjectID) {
+	accessToken, expiresAt, err := h.Tokens.Issue(user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token: " + err.Error()})
+		return
+	}
+
+	refreshToken, refreshHash, err := NewRefreshToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue refresh token: " + err.Error()})
+		return
+	}
+
+	now := time.Now()
+	record := model.RefreshToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: refreshHash, // Only the hash is stored, the plain token goes to the client
+		ExpiresAt: now.Add(h.Tokens.RefreshTTL()),
+		CreatedAt: now,
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	if err := h.RefreshTokens.CreateRefreshToken(dbCtx, &record); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store refresh token: " + err.Error()})
+		return
+	}
+
+	// The client sends the access token back as "Authorization: Bearer <token>"
+	// and uses the refresh token with /auth/refresh when the access token expires
+	ctx.JSON(status, gin.H{
+		"message":            message,
+		"user":               user,
+		"token":              accessToken,
+		"token_type":         "Bearer",
+		"expires_at":         expiresAt,
+		"refresh_token":      refreshToken,
+		"refresh_expires_at": record.ExpiresAt,
+	})
+}
+
+// normalizeEmail trims spaces and lower-cases the address
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testAPI serves the auth handlers on the memory backend
+type testAPI struct {
+	t      *testing.T
+	engine *gin.Engine
+	stores store.Stores
+	tokens *TokenIssuer
+}
+
+// newTestAPI wires the auth routes the way the router does, with an HS256 issuer
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	tokens, err := NewTokenIssuerFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := store.OpenMemory()
+	h := NewHandler(stores, tokens)
+	engine := gin.New()
+	engine.POST("/auth/register", h.Register)
+	engine.POST("/auth/login", h.Login)
+	engine.POST("/auth/refresh", h.Refresh)
+	engine.POST("/auth/logout", h.Logout)
+
+	protected := engine.Group("", Middleware(tokens, stores.Users, stores.APIKeys))
+	protected.GET("/auth/me", h.Me)
+	protected.POST("/keys", h.CreateAPIKey)
+	protected.GET("/keys", h.ListAPIKeys)
+	protected.DELETE("/keys/:id", h.RevokeAPIKey)
+	protected.Any("/things", func(ctx *gin.Context) { ctx.JSON(http.StatusOK, gin.H{"method": ctx.Request.Method}) })
+	return &testAPI{t: t, engine: engine, stores: stores, tokens: tokens}
+}
+
+// do sends a JSON request with the given Authorization header, if any, and decodes the JSON response
+func (api *testAPI) do(method, path, authorization string, body interface{}) (int, map[string]interface{}) {
+	api.t.Helper()
+	raw, err := json.Marshal(body)
+	if err != nil {
+		api.t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	api.engine.ServeHTTP(rec, req)
+
+	var decoded map[string]interface{}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+			api.t.Fatalf("%s %s: response is not JSON: %s", method, path, rec.Body.String())
+		}
+	}
+	return rec.Code, decoded
+}
+
+// register creates an account with password "password1" and returns the auth response
+func (api *testAPI) register(email string) map[string]interface{} {
+	api.t.Helper()
+	code, body := api.do(http.MethodPost, "/auth/register", "", gin.H{"name": "Test", "email": email, "password": "password1"})
+	if code != http.StatusCreated {
+		api.t.Fatalf("register %s: got %d %v", email, code, body)
+	}
+	return body
+}
+
+func TestLogin(t *testing.T) {
+	api := newTestAPI(t)
+	api.register("ada@example.com")
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		want     int
+	}{
+		{"right password", "ada@example.com", "password1", http.StatusOK},
+		{"email in another case", " Ada@Example.com ", "password1", http.StatusOK},
+		{"wrong password", "ada@example.com", "password2", http.StatusUnauthorized},
+		{"unknown email", "bob@example.com", "password1", http.StatusUnauthorized},
+		{"missing password", "ada@example.com", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := api.do(http.MethodPost, "/auth/login", "", gin.H{"email": tt.email, "password": tt.password})
+			if code != tt.want {
+				t.Fatalf("got %d %v, want %d", code, body, tt.want)
+			}
+			if code == http.StatusUnauthorized && body["error"] != "Invalid email or password" {
+				t.Errorf("error = %v, want the same message for unknown emails and wrong passwords", body["error"])
+			}
+		})
+	}
+}
+
+func TestUnknownUserHashCostsLikeARealOne(t *testing.T) {
+	// An unknown email is checked against this hash; a cheaper one would make it answer faster
+	cost, err := bcrypt.Cost([]byte(unknownUserHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Errorf("cost = %d, want %d like HashPassword", cost, bcrypt.DefaultCost)
+	}
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Keys under which the middleware stores the caller on gin.Context
+const (
+	userContextKey   = "auth.user"
+	apiKeyContextKey = "auth.api_key"
+)
+
+// Middleware rejects requests that don't carry valid credentials
+// Two schemes are accepted in the Authorization header:
+// - "Bearer <access token>" for users who logged in
+// - "ApiKey <key>" for scripts using a personal API key
+// On success the user is loaded from the store and attached to the context,
+// so handlers can call CurrentUser instead of parsing the header again
+func Middleware(tokens *TokenIssuer, users store.UserStore, apiKeys store.APIKeyStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scheme, credential, ok := parseAuthorization(ctx.GetHeader("Authorization"))
+		if !ok {
+			abortWith(ctx, http.StatusUnauthorized, "Missing or malformed Authorization header")
+			return
+		}
+
+		var userID primitive.ObjectID
+		switch {
+		case strings.EqualFold(scheme, "Bearer"):
+			claims, err := tokens.Parse(credential)
+			if err != nil {
+				abortWith(ctx, http.StatusUnauthorized, "Invalid or expired token")
+				return
+			}
+			if userID, err = claims.UserID(); err != nil {
+				abortWith(ctx, http.StatusUnauthorized, "Invalid or expired token")
+				return
+			}
+
+		case strings.EqualFold(scheme, "ApiKey"):
+			key, ok := authenticateAPIKey(ctx, apiKeys, credential)
+			if !ok {
+				return // authenticateAPIKey already wrote the response
+			}
+			userID = key.UserID
+			ctx.Set(apiKeyContextKey, key)
+
+		default:
+			abortWith(ctx, http.StatusUnauthorized, "Unsupported authorization scheme, use Bearer or ApiKey")
+			return
+		}
+
+		dbCtx, cancel := helpers.DBContext(ctx)
+		defer cancel()
+
+		// Loading the user means a deleted account stops working immediately,
+		// even though its token has not expired yet
+		user, err := users.GetUserByID(dbCtx, userID)
+		if errors.Is(err, store.ErrUserNotFound) {
+			abortWith(ctx, http.StatusUnauthorized, "User no longer exists")
+			return
+		}
+		if err != nil {
+			abortWith(ctx, http.StatusInternalServerError, "Failed to load user: "+err.Error())
+			return
+		}
+
+		ctx.Set(userContextKey, user)
+		ctx.Next() // Hand over to the next handler in the chain
+	}
+}
+
+// authenticateAPIKey checks an API key, enforces its scope and records its use
+// It writes the error response itself and returns false when the request must stop
+func authenticateAPIKey(ctx *gin.Context, apiKeys store.APIKeyStore, plain string) (model.APIKey, bool) {
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	key, err := apiKeys.GetAPIKeyByHash(dbCtx, HashAPIKey(plain))
+	if errors.Is(err, store.ErrAPIKeyNotFound) || (err == nil && key.RevokedAt != nil) {
+		abortWith(ctx, http.StatusUnauthorized, "Invalid or revoked API key")
+		return model.APIKey{}, false
+	}
+	if err != nil {
+		abortWith(ctx, http.StatusInternalServerError, "Failed to look up API key: "+err.Error())
+		return model.APIKey{}, false
+	}
+
+	// Read-only keys may only use safe methods that don't change anything
+	if key.Scope == model.APIKeyScopeRead && !IsReadOnlyMethod(ctx.Request.Method) {
+		AbortWithError(ctx, helpers.Forbidden("This API key is read-only"))
+		return model.APIKey{}, false
+	}
+
+	// Failing to record last use shouldn't fail the request itself
+	_ = apiKeys.TouchAPIKey(dbCtx, key.ID, time.Now())
+
+	return key, true
+}
+
+// CurrentUser returns the user attached by Middleware
+// The boolean is false when the route is not behind the middleware
+func CurrentUser(ctx *gin.Context) (model.User, bool) {
+	value, exists := ctx.Get(userContextKey)
+	if !exists {
+		return model.User{}, false
+	}
+	user, ok := value.(model.User)
+	return user, ok
+}
+
+// CurrentAPIKey returns the API key used for the request, if the caller used one
+func CurrentAPIKey(ctx *gin.Context) (model.APIKey, bool) {
+	value, exists := ctx.Get(apiKeyContextKey)
+	if !exists {
+		return model.APIKey{}, false
+	}
+	key, ok := value.(model.APIKey)
+	return key, ok
+}
+
+// OwnerScope builds the store scope for the authenticated user, so a handler only reaches their own data
+// Every owned resource goes through it, so no query can forget to filter by owner
+// It writes a 401 and returns false if the route isn't behind Middleware
+func OwnerScope(ctx *gin.Context) (store.Scope, bool) {
+	user, ok := CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return store.Scope{}, false
+	}
+	return store.OwnedBy(user.ID), true
+}
+
+// parseAuthorization splits an "Authorization: <scheme> <credential>" header value
+func parseAuthorization(header string) (scheme string, credential string, ok bool) {
+	scheme, credential, found := strings.Cut(header, " ")
+	credential = strings.TrimSpace(credential)
+	if !found || credential == "" {
+		return "", "", false
+	}
+	return scheme, credential, true
+}
+
+// IsReadOnlyMethod reports whether an HTTP method never modifies data
+func IsReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// abortWith stops the request with an error built from helpers.Error
+func abortWith(ctx *gin.Context, status int, message string) {
+	if status == http.StatusUnauthorized {
+		// WWW-Authenticate tells the client which schemes the API expects
+		ctx.Header("WWW-Authenticate", `Bearer realm="api", ApiKey realm="api"`)
+	}
+	AbortWithError(ctx, helpers.Error{Message: message, Status: status})
+}
+
+// AbortWithError stops the handler chain and writes err as {"error": message}
+// Middlewares use it so every denial has the same shape
+func AbortWithError(ctx *gin.Context, err helpers.Error) {
+	ctx.AbortWithStatusJSON(err.GetStatus(), gin.H{"error": err.Error()})
+}
@@ -0,0 +1,24 @@
+package auth
+
+import "golang.org/x/crypto/bcrypt"
+
+// HashPassword turns a plain text password into a bcrypt hash
+// bcrypt salts every hash and is deliberately slow, which makes brute forcing
+// a stolen hash expensive; DefaultCost (10) is a good balance for an API
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// unknownUserHash is a bcrypt hash at DefaultCost that no password matches in practice
+// Logins for an email nobody registered are checked against it, so they take as long as a wrong password
+const unknownUserHash = "$2a$10$AQ4PBdM7GTQeC4xASeg36.mwO83cgBEG32MS5PqBx1Xi5JtrboZvO"
+
+// CheckPassword reports whether password matches the stored bcrypt hash
+// The comparison runs in constant time so it doesn't leak how many bytes matched
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
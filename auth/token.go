@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidToken is returned when a token is malformed, forged or expired
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims is the payload we put inside every access token
+// RegisteredClaims adds the standard fields: sub (user ID), exp, iat
+type Claims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// UserID converts the subject claim back into an ObjectID
+func (c Claims) UserID() (primitive.ObjectID, error) {
+	return primitive.ObjectIDFromHex(c.Subject)
+}
+
+// TokenIssuer signs and verifies access tokens and knows how long tokens live
+// HS256 signs and verifies with one shared secret; RS256 signs with a private key
+// and verifies with the matching public key, so other services can verify
+// tokens without being able to create them
+type TokenIssuer struct {
+	method     jwt.SigningMethod
+	signKey    interface{} // []byte for HS256, *rsa.PrivateKey for RS256
+	verifyKey  interface{} // []byte for HS256, *rsa.PublicKey for RS256
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewTokenIssuerFromEnv builds a TokenIssuer from environment variables:
+// - JWT_ALGORITHM: HS256 (default) or RS256
+// - JWT_SECRET: shared secret for HS256
+// - JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_FILE: PEM encoded RSA private key for RS256
+// - JWT_ACCESS_TTL: access token lifetime, e.g. "15m" (default 15m)
+// - JWT_REFRESH_TTL: refresh token lifetime, e.g. "720h" (default 30 days)
+func NewTokenIssuerFromEnv() (*TokenIssuer, error) {
+	issuer := &TokenIssuer{}
+
+	var err error
+	if issuer.accessTTL, err = durationFromEnv("JWT_ACCESS_TTL", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if issuer.refreshTTL, err = durationFromEnv("JWT_REFRESH_TTL", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	algorithm := strings.ToUpper(os.Getenv("JWT_ALGORITHM"))
+	switch algorithm {
+	case "", "HS256":
+		secret := []byte(os.Getenv("JWT_SECRET"))
+		if len(secret) == 0 {
+			// Without a secret a random one is generated, which is fine for local development
+			// but logs everyone out on every restart, so production must set it
+			fmt.Println("Warning: JWT_SECRET not set, using a random secret (tokens won't survive a restart)")
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		issuer.method = jwt.SigningMethodHS256
+		issuer.signKey = secret
+		issuer.verifyKey = secret
+
+	case "RS256":
+		pemData, err := privateKeyPEM()
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("parse RS256 private key: %w", err)
+		}
+		issuer.method = jwt.SigningMethodRS256
+		issuer.signKey = privateKey
+		issuer.verifyKey = &privateKey.PublicKey
+
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q (expected HS256 or RS256)", algorithm)
+	}
+
+	return issuer, nil
+}
+
+// RefreshTTL is how long a refresh token stays valid
+func (t *TokenIssuer) RefreshTTL() time.Duration {
+	return t.refreshTTL
+}
+
+// Issue creates a signed access token for the user and returns it with its expiry time
+func (t *TokenIssuer) Issue(user model.User) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(t.accessTTL)
+
+	claims := Claims{
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.Hex(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(t.method, claims).SignedString(t.signKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// Parse verifies the signature and expiry of a token and returns its claims
+func (t *TokenIssuer) Parse(token string) (*Claims, error) {
+	claims := &Claims{}
+
+	// WithValidMethods stops an attacker from switching the algorithm (e.g. to "none",
+	// or to HS256 using our public key as the "secret")
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return t.verifyKey, nil
+	}, jwt.WithValidMethods([]string{t.method.Alg()}))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// NewRefreshToken generates a random refresh token and the hash to store for it
+// Refresh tokens are opaque random strings rather than JWTs: the server has to look
+// them up anyway to support rotation and revocation
+func NewRefreshToken() (token string, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the SHA-256 hex digest used to store and look up a refresh token
+// A fast hash is fine here (unlike passwords) because the token is 256 random bits
+func HashRefreshToken(token string) string {
+	return sha256Hex(token)
+}
+
+// sha256Hex returns the hex encoded SHA-256 digest of value
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// privateKeyPEM reads the RS256 key from JWT_PRIVATE_KEY or the file in JWT_PRIVATE_KEY_FILE
+func privateKeyPEM() ([]byte, error) {
+	if inline := os.Getenv("JWT_PRIVATE_KEY"); inline != "" {
+		// Hosting dashboards often store multi-line values with literal "\n"
+		return []byte(strings.ReplaceAll(inline, `\n`, "\n")), nil
+	}
+	if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+		return os.ReadFile(path)
+	}
+	return nil, errors.New("RS256 requires JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_FILE")
+}
+
+// durationFromEnv parses a Go duration ("15m", "24h") or falls back to def when unset
+func durationFromEnv(name string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration like 15m or 24h", name)
+	}
+	return d, nil
+}
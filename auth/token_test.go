@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rs256Issuer builds an RS256 issuer from a freshly generated key, the way production passes it in
+func rs256Issuer(t *testing.T) (*TokenIssuer, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	t.Setenv("JWT_ALGORITHM", "RS256")
+	t.Setenv("JWT_PRIVATE_KEY", string(pemKey))
+	issuer, err := NewTokenIssuerFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return issuer, key
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	hs256, err := NewTokenIssuerFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256, _ := rs256Issuer(t)
+
+	user := model.User{ID: primitive.NewObjectID(), Email: "ada@example.com"}
+	for name, issuer := range map[string]*TokenIssuer{"HS256": hs256, "RS256": rs256} {
+		t.Run(name, func(t *testing.T) {
+			token, expiresAt, err := issuer.Issue(user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if until := time.Until(expiresAt); until <= 14*time.Minute || until > 15*time.Minute {
+				t.Errorf("token expires in %v, want the default 15m", until)
+			}
+			claims, err := issuer.Parse(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id, err := claims.UserID(); err != nil || id != user.ID || claims.Email != user.Email {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	rs256, key := rs256Issuer(t)
+	user := model.User{ID: primitive.NewObjectID(), Email: "ada@example.com"}
+	claims := func(expiresIn time.Duration) Claims {
+		return Claims{Email: user.Email, RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.Hex(),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		}}
+	}
+	sign := func(method jwt.SigningMethod, key interface{}, claims Claims) string {
+		t.Helper()
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"expired": sign(jwt.SigningMethodRS256, key, claims(-time.Minute)),
+		// The classic confusion attack: HS256 keyed with the public key, which anyone can read
+		"HS256 with the public key as secret": sign(jwt.SigningMethodHS256, publicPEM, claims(time.Minute)),
+		"alg none":                            sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claims(time.Minute)),
+		"RS512 with the right key":            sign(jwt.SigningMethodRS512, key, claims(time.Minute)),
+		"signed by another key":               sign(jwt.SigningMethodRS256, other, claims(time.Minute)),
+		"not a token":                         "not.a.token",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := rs256.Parse(token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("got %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+	if _, err := rs256.Parse(sign(jwt.SigningMethodRS256, key, claims(time.Minute))); err != nil {
+		t.Errorf("a valid token was rejected: %v", err)
+	}
+}
+
+func TestMiddlewareRejectsExpiredToken(t *testing.T) {
+	api := newTestAPI(t)
+	body := api.register("ada@example.com")
+	if code, _ := api.do(http.MethodGet, "/auth/me", "Bearer "+body["token"].(string), nil); code != http.StatusOK {
+		t.Fatalf("fresh token: got %d, want 200", code)
+	}
+
+	user, err := api.stores.Users.GetUserByEmail(context.Background(), "ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.tokens.accessTTL = -time.Second
+	expired, _, err := api.tokens.Issue(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := api.do(http.MethodGet, "/auth/me", "Bearer "+expired, nil); code != http.StatusUnauthorized {
+		t.Errorf("expired token: got %d, want 401", code)
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	api := newTestAPI(t)
+	first := api.register("ada@example.com")["refresh_token"].(string)
+	refresh := func(token string) (int, map[string]interface{}) {
+		t.Helper()
+		return api.do(http.MethodPost, "/auth/refresh", "", gin.H{"refresh_token": token})
+	}
+
+	code, body := refresh(first)
+	if code != http.StatusOK {
+		t.Fatalf("refresh: got %d %v", code, body)
+	}
+	second := body["refresh_token"].(string)
+	if second == first {
+		t.Fatal("refresh handed back the same token instead of rotating it")
+	}
+	if code, _ := api.do(http.MethodGet, "/auth/me", "Bearer "+body["token"].(string), nil); code != http.StatusOK {
+		t.Errorf("new access token: got %d, want 200", code)
+	}
+
+	// Replaying the rotated token means it was stolen: it fails, and so does every token of its family
+	if code, body := refresh(first); code != http.StatusUnauthorized || body["error"] != "Refresh token has been revoked" {
+		t.Fatalf("replayed token: got %d %v, want 401 revoked", code, body)
+	}
+	if code, _ := refresh(second); code != http.StatusUnauthorized {
+		t.Errorf("latest token of the replayed family: got %d, want 401", code)
+	}
+
+	// Other logins keep working
+	code, body = api.do(http.MethodPost, "/auth/login", "", gin.H{"email": "ada@example.com", "password": "password1"})
+	if code != http.StatusOK {
+		t.Fatalf("login: got %d %v", code, body)
+	}
+	if code, _ := refresh(body["refresh_token"].(string)); code != http.StatusOK {
+		t.Errorf("token of another login: got %d, want 200", code)
+	}
+	if code, _ := refresh("unknown"); code != http.StatusUnauthorized {
+		t.Errorf("unknown token: got %d, want 401", code)
+	}
+}
+
+func TestLogoutRevokesFamily(t *testing.T) {
+	api := newTestAPI(t)
+	first := api.register("ada@example.com")["refresh_token"].(string)
+	_, body := api.do(http.MethodPost, "/auth/refresh", "", gin.H{"refresh_token": first})
+	second := body["refresh_token"].(string)
+
+	if code, _ := api.do(http.MethodPost, "/auth/logout", "", gin.H{"refresh_token": second}); code != http.StatusOK {
+		t.Fatalf("logout: got %d, want 200", code)
+	}
+	if code, _ := api.do(http.MethodPost, "/auth/refresh", "", gin.H{"refresh_token": second}); code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: got %d, want 401", code)
+	}
+}
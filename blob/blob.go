@@ -0,0 +1,73 @@
+// Package blob stores uploaded files (task attachments and their thumbnails)
+// Handlers only talk to the Store interface, so files can live on the local disk
+// during development and in an S3-compatible bucket in production
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned when no blob is stored under the key
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs under slash-separated keys like "tasks/<owner>/<task>/<file>"
+type Store interface {
+	// Put stores data under key, replacing whatever was there
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Open returns the blob's content, or ErrNotFound; the caller closes it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes one blob; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every blob under a directory-like prefix ending in "/"
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// FromEnv picks the blob store from the BLOB_STORE environment variable
+// - local (default): files under BLOB_DIR (default uploads)
+// - s3: objects in S3_BUCKET at S3_ENDPOINT, see NewS3Store
+func FromEnv() (Store, error) {
+	backend := strings.ToLower(os.Getenv("BLOB_STORE"))
+
+	switch backend {
+	case "", "local":
+		dir := os.Getenv("BLOB_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStore(dir)
+
+	case "s3":
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return NewS3Store(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Region:          region,
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q (expected local or s3)", backend)
+	}
+}
+
+// validKey rejects keys that could escape the store's root, like "../x" or "/etc/passwd"
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(key, "/"), "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
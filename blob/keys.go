@@ -0,0 +1,21 @@
+package blob
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Attachments are stored as tasks/<owner>/<task>/<attachment>, so everything
+// a user uploaded can be removed with one DeletePrefix when the account goes
+
+// AttachmentKey is where an uploaded file is kept
+func AttachmentKey(ownerID, taskID, attachmentID primitive.ObjectID) string {
+	return OwnerPrefix(ownerID) + taskID.Hex() + "/" + attachmentID.Hex()
+}
+
+// ThumbnailKey is where the thumbnail of an uploaded file is kept
+func ThumbnailKey(ownerID, taskID, attachmentID primitive.ObjectID) string {
+	return AttachmentKey(ownerID, taskID, attachmentID) + "-thumb"
+}
+
+// OwnerPrefix holds every file uploaded to one user's tasks
+func OwnerPrefix(ownerID primitive.ObjectID) string {
+	return "tasks/" + ownerID.Hex() + "/"
+}
@@ -0,0 +1,85 @@
+// Package cursor signs the opaque tokens clients page through lists with
+// A token carries JSON that only the server reads; the signature stops clients from
+// editing it to reach a position or a sort the list would never have handed out
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrInvalid is returned for a token that is malformed or wasn't signed with this Signer's secret
+var ErrInvalid = errors.New("invalid cursor")
+
+// strictBase64 rejects the unused bits a lenient decoder would ignore
+var strictBase64 = base64.RawURLEncoding.Strict()
+
+// Signer turns values into signed tokens and back
+type Signer struct {
+	secret []byte
+}
+
+// New creates a Signer with the given secret
+func New(secret []byte) *Signer {
+	return &Signer{secret: secret}
+}
+
+// FromEnv reads the secret from CURSOR_SECRET
+// Without one a random secret is generated, so tokens stop working on every restart
+// and aren't shared between instances; production should set it
+func FromEnv() (*Signer, error) {
+	secret := []byte(os.Getenv("CURSOR_SECRET"))
+	if len(secret) == 0 {
+		fmt.Println("Warning: CURSOR_SECRET not set, using a random secret (cursors won't survive a restart)")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	return New(secret), nil
+}
+
+// Encode marshals v to JSON and returns it as a URL-safe token: payload.signature
+func (s *Signer) Encode(v interface{}) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// Decode checks the signature of token and unmarshals its payload into v
+// Decoding is strict, so a token has exactly one spelling: editing its last character can't go unnoticed
+func (s *Signer) Decode(token string, v interface{}) error {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalid
+	}
+	mac, err := strictBase64.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return ErrInvalid
+	}
+	payload, err := strictBase64.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalid
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// sign returns the HMAC-SHA256 of the encoded payload
+func (s *Signer) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
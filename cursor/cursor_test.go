@@ -0,0 +1,91 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type payload struct {
+	Sort string `json:"s"`
+	N    int    `json:"n"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	signer := New([]byte("secret"))
+	token, err := signer.Encode(payload{Sort: "-priority,due_at", N: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("token %q is not URL-safe", token)
+	}
+
+	var got payload
+	if err := signer.Decode(token, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != (payload{Sort: "-priority,due_at", N: 7}) {
+		t.Errorf("decoded %+v", got)
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	signer := New([]byte("secret"))
+	token, err := signer.Encode(payload{Sort: "title", N: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, signature, _ := strings.Cut(token, ".")
+
+	// The same signature on a payload the client edited to skip ahead
+	edited := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"title","n":99}`))
+	foreign, err := New([]byte("other secret")).Encode(payload{Sort: "title", N: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Still valid base64, so only the HMAC check can catch it
+	flipped := []byte(signature)
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+
+	tests := map[string]string{
+		"edited payload":        edited + "." + signature,
+		"tampered signature":    encoded + "." + string(flipped),
+		"signed with other key": foreign,
+		"no signature":          encoded,
+		"empty":                 "",
+		"not base64":            "!!!." + signature,
+		"signature not base64":  encoded + ".!!!",
+		"signed non-JSON":       signedRaw(signer, "not json"),
+		"last character edited": encoded + "." + lastEdited(signature),
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got payload
+			if err := signer.Decode(token, &got); !errors.Is(err, ErrInvalid) {
+				t.Errorf("got %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+// signedRaw signs a payload that isn't JSON, which only a leaked secret could produce
+func signedRaw(s *Signer, raw string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(raw))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+}
+
+// urlAlphabet is the digits of base64.RawURLEncoding in order
+const urlAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// lastEdited flips the lowest bit of the last character; a 32-byte HMAC leaves that bit unused,
+// so a lenient decoder would read the same signature
+func lastEdited(signature string) string {
+	last := strings.IndexByte(urlAlphabet, signature[len(signature)-1])
+	return signature[:len(signature)-1] + string(urlAlphabet[last^1])
+}
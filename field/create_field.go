@@ -0,0 +1,86 @@
+package field
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fieldRequest is the JSON body accepted by CreateField
+type fieldRequest struct {
+	Key      string          `json:"key"`
+	Name     string          `json:"name"`
+	Type     model.FieldType `json:"type"`
+	Options  []string        `json:"options"`
+	Required bool            `json:"required"`
+}
+
+// CreateField defines a new custom field for the logged in user's tasks
+// Tasks created before a required field existed keep working; only new tasks must set it
+func (h *Handler) CreateField(ctx *gin.Context) {
+	var req fieldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	field := model.CustomField{
+		ID:       primitive.NewObjectID(),
+		OwnerID:  scope.OwnerID,
+		Key:      req.Key,
+		Name:     strings.TrimSpace(req.Name),
+		Type:     req.Type,
+		Options:  req.Options,
+		Required: req.Required,
+		Metadata: model.Metadata{CreatedAt: now, UpdatedAt: now},
+	}
+	if err := helpers.ValidateCustomField(&field); err != nil {
+		ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	existing, err := h.Fields.ListCustomFields(dbCtx, scope)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create custom field: " + err.Error()})
+		return
+	}
+	if len(existing) >= MaxFieldsPerOwner {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("You can define at most %d custom fields", MaxFieldsPerOwner),
+		})
+		return
+	}
+
+	err = h.Fields.CreateCustomField(dbCtx, &field)
+	if errors.Is(err, store.ErrCustomFieldKeyTaken) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A custom field with key %q already exists", field.Key)})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create custom field: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Custom field created successfully",
+		"field":   field,
+	})
+}
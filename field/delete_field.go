@@ -0,0 +1,57 @@
+package field
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// DeleteField deletes a custom field together with its values on every task
+func (h *Handler) DeleteField(ctx *gin.Context) {
+	id, ok := fieldID(ctx)
+	if !ok {
+		return
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	field, err := h.Fields.GetCustomField(dbCtx, scope, id)
+	if errors.Is(err, store.ErrCustomFieldNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete custom field: " + err.Error()})
+		return
+	}
+
+	// Clear the values first: if the delete then fails, the field is simply empty
+	// and a new field with the same key won't inherit stale values
+	cleared, err := h.Tasks.ClearCustomField(dbCtx, scope, field.Key)
+	if err == nil {
+		err = h.Fields.DeleteCustomField(dbCtx, scope, id)
+	}
+	if errors.Is(err, store.ErrCustomFieldNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete custom field: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       "Custom field deleted successfully",
+		"cleared_tasks": cleared,
+	})
+}
@@ -0,0 +1,35 @@
+package field
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxFieldsPerOwner caps how many custom fields one user may define
+const MaxFieldsPerOwner = 50
+
+// Handler groups the custom field handlers
+// It needs the task store too, to clear the values of a field before deleting it
+type Handler struct {
+	Fields store.CustomFieldStore
+	Tasks  store.TaskStore
+}
+
+// NewHandler creates a Handler backed by the given stores
+func NewHandler(stores store.Stores) *Handler {
+	return &Handler{Fields: stores.CustomFields, Tasks: stores.Tasks}
+}
+
+// fieldID parses the :id path parameter
+// It writes a 400 and returns false when the ID is malformed
+func fieldID(ctx *gin.Context) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return primitive.NilObjectID, false
+	}
+	return id, true
+}
@@ -0,0 +1,64 @@
+package field
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// ListFields returns the caller's custom field definitions, oldest first
+func (h *Handler) ListFields(ctx *gin.Context) {
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	fields, err := h.Fields.ListCustomFields(dbCtx, scope)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve custom fields: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Custom fields retrieved successfully",
+		"fields":  fields,
+	})
+}
+
+// GetField returns one of the caller's custom field definitions
+func (h *Handler) GetField(ctx *gin.Context) {
+	id, ok := fieldID(ctx)
+	if !ok {
+		return
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	field, err := h.Fields.GetCustomField(dbCtx, scope, id)
+	if errors.Is(err, store.ErrCustomFieldNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve custom field: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Custom field retrieved successfully",
+		"field":   field,
+	})
+}
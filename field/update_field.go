@@ -0,0 +1,86 @@
+package field
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// UpdateField changes the name, options or required flag of a custom field
+// Only the fields present in the body change; the key and the type are fixed
+// Tasks keep values of options that were removed until they are edited
+func (h *Handler) UpdateField(ctx *gin.Context) {
+	id, ok := fieldID(ctx)
+	if !ok {
+		return
+	}
+
+	var update store.CustomFieldUpdate
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if update.Name == nil && update.Options == nil && update.Required == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Update payload cannot be empty"})
+		return
+	}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if err := helpers.ValidateFieldName(name); err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+		update.Name = &name
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Options only make sense on select fields, so the type has to be known first
+	if update.Options != nil {
+		current, err := h.Fields.GetCustomField(dbCtx, scope, id)
+		if errors.Is(err, store.ErrCustomFieldNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load custom field: " + err.Error()})
+			return
+		}
+		if !current.Type.HasOptions() {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only select and multi_select fields have options"})
+			return
+		}
+		options, verr := helpers.ValidateFieldOptions(*update.Options)
+		if verr != nil {
+			ctx.JSON(verr.GetStatus(), gin.H{"error": verr.Error()})
+			return
+		}
+		update.Options = &options
+	}
+
+	field, err := h.Fields.UpdateCustomField(dbCtx, scope, id, update)
+	if errors.Is(err, store.ErrCustomFieldNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update custom field: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Custom field updated successfully",
+		"field":   field,
+	})
+}
@@ -0,0 +1,212 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+)
+
+// MaxFieldOptions caps how many options a select or multi_select field may offer
+const MaxFieldOptions = 50
+
+// MaxFieldText is the longest value a text field may hold
+const MaxFieldText = 500
+
+// fieldKey matches a custom field key: it ends up in query parameters and database paths, so keep it plain
+var fieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// ValidateCustomField checks a definition before it is stored
+// The options are trimmed in place; only select and multi_select fields may have them
+func ValidateCustomField(field *model.CustomField) *Error {
+	if !fieldKey.MatchString(field.Key) {
+		return &Error{Message: "Key must start with a lowercase letter and contain only a-z, 0-9 and _ (at most 40 characters)", Status: 400}
+	}
+	if err := ValidateFieldName(field.Name); err != nil {
+		return err
+	}
+	if !field.Type.Valid() {
+		return &Error{Message: fmt.Sprintf("Type must be one of %v", model.FieldTypes), Status: 400}
+	}
+	if !field.Type.HasOptions() {
+		if len(field.Options) > 0 {
+			return &Error{Message: "Only select and multi_select fields have options", Status: 400}
+		}
+		return nil
+	}
+	options, err := ValidateFieldOptions(field.Options)
+	if err != nil {
+		return err
+	}
+	field.Options = options
+	return nil
+}
+
+// ValidateFieldName checks the display name of a custom field
+func ValidateFieldName(name string) *Error {
+	if strings.TrimSpace(name) == "" {
+		return &Error{Message: "Name is required", Status: 400}
+	}
+	if len(name) > 100 {
+		return &Error{Message: "Name must be at most 100 characters", Status: 400}
+	}
+	return nil
+}
+
+// ValidateFieldOptions checks the options of a select field and returns them trimmed
+func ValidateFieldOptions(options []string) ([]string, *Error) {
+	if len(options) == 0 || len(options) > MaxFieldOptions {
+		return nil, &Error{Message: fmt.Sprintf("Select fields need between 1 and %d options", MaxFieldOptions), Status: 400}
+	}
+	trimmed := make([]string, len(options))
+	seen := make(map[string]bool, len(options))
+	for i, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || len(option) > 100 {
+			return nil, &Error{Message: "Options must be between 1 and 100 characters", Status: 400}
+		}
+		if seen[option] {
+			return nil, &Error{Message: fmt.Sprintf("Option %q is listed twice", option), Status: 400}
+		}
+		seen[option] = true
+		trimmed[i] = option
+	}
+	return trimmed, nil
+}
+
+// ValidateFieldValues checks custom field values against the owner's definitions and returns them normalized
+// A nil value, an empty text or an empty list clears the field: it comes back as nil,
+// which is refused for required fields
+func ValidateFieldValues(defs []model.CustomField, values model.FieldValues) (model.FieldValues, *Error) {
+	byKey := make(map[string]model.CustomField, len(defs))
+	for _, def := range defs {
+		byKey[def.Key] = def
+	}
+
+	normalized := make(model.FieldValues, len(values))
+	for key, value := range values {
+		def, ok := byKey[key]
+		if !ok {
+			return nil, &Error{Message: fmt.Sprintf("Unknown custom field %q", key), Status: 400}
+		}
+		value, err := normalizeFieldValue(def, value)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil && def.Required {
+			return nil, &Error{Message: fmt.Sprintf("Custom field %q is required", key), Status: 400}
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
+
+// RequireFields checks that the values of a new task set every required custom field
+func RequireFields(defs []model.CustomField, values model.FieldValues) *Error {
+	for _, def := range defs {
+		if def.Required && values[def.Key] == nil {
+			return &Error{Message: fmt.Sprintf("Custom field %q is required", def.Key), Status: 400}
+		}
+	}
+	return nil
+}
+
+// normalizeFieldValue turns one decoded JSON value into the form stored for the field's type
+func normalizeFieldValue(def model.CustomField, value interface{}) (interface{}, *Error) {
+	if value == nil {
+		return nil, nil
+	}
+	invalid := func(expected string) *Error {
+		return &Error{Message: fmt.Sprintf("Custom field %q must be %s", def.Key, expected), Status: 400}
+	}
+
+	switch def.Type {
+	case model.FieldText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("a string")
+		}
+		text = strings.TrimSpace(text)
+		if len(text) > MaxFieldText {
+			return nil, invalid(fmt.Sprintf("at most %d characters", MaxFieldText))
+		}
+		if text == "" {
+			return nil, nil
+		}
+		return text, nil
+
+	case model.FieldNumber:
+		number, ok := value.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, invalid("a number")
+		}
+		return number, nil
+
+	case model.FieldDate:
+		date, ok := value.(string)
+		if !ok {
+			return nil, invalid("a YYYY-MM-DD date")
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, invalid("a YYYY-MM-DD date")
+		}
+		return date, nil
+
+	case model.FieldSelect:
+		option, ok := value.(string)
+		if !ok {
+			return nil, invalid(fmt.Sprintf("one of %q", def.Options))
+		}
+		if option == "" {
+			return nil, nil
+		}
+		if !hasOption(def, option) {
+			return nil, invalid(fmt.Sprintf("one of %q", def.Options))
+		}
+		return option, nil
+
+	case model.FieldMultiSelect:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, invalid("a list of options")
+		}
+		// Keep the order the options were picked in, minus duplicates
+		picked := make([]string, 0, len(items))
+		seen := make(map[string]bool, len(items))
+		for _, item := range items {
+			option, ok := item.(string)
+			if !ok || !hasOption(def, option) {
+				return nil, invalid(fmt.Sprintf("a list of %q", def.Options))
+			}
+			if !seen[option] {
+				seen[option] = true
+				picked = append(picked, option)
+			}
+		}
+		if len(picked) == 0 {
+			return nil, nil
+		}
+		return picked, nil
+
+	case model.FieldCheckbox:
+		checked, ok := value.(bool)
+		if !ok {
+			return nil, invalid("true or false")
+		}
+		return checked, nil
+	}
+	return nil, invalid("a known type")
+}
+
+// hasOption reports whether option is one of the field's options
+func hasOption(def model.CustomField, option string) bool {
+	for _, o := range def.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/joshua-takyi/todo/model"
+)
+
+func TestValidateCustomField(t *testing.T) {
+	tests := []struct {
+		name  string
+		field model.CustomField
+		ok    bool
+	}{
+		{"text", model.CustomField{Key: "notes", Name: "Notes", Type: model.FieldText}, true},
+		{"select", model.CustomField{Key: "severity", Name: "Severity", Type: model.FieldSelect, Options: []string{"low", "high"}}, true},
+		{"key with capitals", model.CustomField{Key: "Severity", Name: "S", Type: model.FieldText}, false},
+		{"key with a dot", model.CustomField{Key: "a.b", Name: "S", Type: model.FieldText}, false},
+		{"key starting with a digit", model.CustomField{Key: "1st", Name: "S", Type: model.FieldText}, false},
+		{"key too long", model.CustomField{Key: "k" + strings.Repeat("x", 40), Name: "S", Type: model.FieldText}, false},
+		{"blank name", model.CustomField{Key: "k", Name: "  ", Type: model.FieldText}, false},
+		{"unknown type", model.CustomField{Key: "k", Name: "K", Type: "color"}, false},
+		{"options on a number", model.CustomField{Key: "k", Name: "K", Type: model.FieldNumber, Options: []string{"1"}}, false},
+		{"select without options", model.CustomField{Key: "k", Name: "K", Type: model.FieldSelect}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateCustomField(&tt.field); (err == nil) != tt.ok {
+			t.Errorf("%s: got %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateFieldOptions(t *testing.T) {
+	got, err := ValidateFieldOptions([]string{" low ", "high"})
+	if err != nil || !reflect.DeepEqual(got, []string{"low", "high"}) {
+		t.Errorf("got %q, %v; want the options trimmed", got, err)
+	}
+
+	many := make([]string, MaxFieldOptions+1)
+	for i := range many {
+		many[i] = strings.Repeat("o", i+1)
+	}
+	for name, options := range map[string][]string{
+		"none":             {},
+		"too many":         many,
+		"blank":            {"a", " "},
+		"too long":         {strings.Repeat("o", 101)},
+		"duplicate":        {"a", "b", "a"},
+		"duplicate padded": {"a", " a"},
+	} {
+		if _, err := ValidateFieldOptions(options); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestValidateFieldValues(t *testing.T) {
+	defs := []model.CustomField{
+		{Key: "notes", Type: model.FieldText},
+		{Key: "size", Type: model.FieldNumber},
+		{Key: "launch", Type: model.FieldDate},
+		{Key: "severity", Type: model.FieldSelect, Options: []string{"low", "high"}, Required: true},
+		{Key: "teams", Type: model.FieldMultiSelect, Options: []string{"web", "ios", "api"}},
+		{Key: "reviewed", Type: model.FieldCheckbox},
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  interface{}
+		ok    bool
+	}{
+		{"text is trimmed", "notes", "  hi  ", "hi", true},
+		{"blank text clears", "notes", "   ", nil, true},
+		{"text too long", "notes", strings.Repeat("x", MaxFieldText+1), nil, false},
+		{"text of the wrong type", "notes", 3.0, nil, false},
+		{"number", "size", 2.5, 2.5, true},
+		{"number as a string", "size", "2.5", nil, false},
+		{"date", "launch", "2026-02-28", "2026-02-28", true},
+		{"impossible date", "launch", "2026-02-30", nil, false},
+		{"date with a time", "launch", "2026-02-28T10:00:00Z", nil, false},
+		{"select", "severity", "high", "high", true},
+		{"unknown option", "severity", "medium", nil, false},
+		{"clearing a required field", "severity", nil, nil, false},
+		{"clearing a required field with an empty option", "severity", "", nil, false},
+		{"multi select keeps the order and drops repeats", "teams", []interface{}{"ios", "web", "ios"}, []string{"ios", "web"}, true},
+		{"empty multi select clears", "teams", []interface{}{}, nil, true},
+		{"multi select with an unknown option", "teams", []interface{}{"web", "android"}, nil, false},
+		{"multi select of a single string", "teams", "web", nil, false},
+		{"checkbox", "reviewed", false, false, true},
+		{"checkbox as a string", "reviewed", "true", nil, false},
+		{"unknown key", "color", "red", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := ValidateFieldValues(defs, model.FieldValues{tt.key: tt.value})
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: error %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+		if tt.ok && !reflect.DeepEqual(got[tt.key], tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got[tt.key], tt.want)
+		}
+	}
+}
+
+func TestRequireFields(t *testing.T) {
+	defs := []model.CustomField{
+		{Key: "severity", Type: model.FieldSelect, Options: []string{"low"}, Required: true},
+		{Key: "notes", Type: model.FieldText},
+	}
+	if err := RequireFields(defs, model.FieldValues{"severity": "low"}); err != nil {
+		t.Errorf("required field set: %v", err)
+	}
+	if err := RequireFields(defs, model.FieldValues{"notes": "x"}); err == nil {
+		t.Error("required field missing: accepted")
+	}
+}
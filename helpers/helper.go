@@ -1,6 +1,15 @@
 package helpers
 
-import "github.com/joshua-takyi/todo/model"
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+)
 
 // Response represents a successful operation with a message and status code
 type Response struct {
@@ -31,6 +40,12 @@ func (e Error) GetStatus() int {
 	return e.Status
 }
 
+// Forbidden builds the error returned whenever the caller is authenticated
+// but not allowed to do something, so every denial looks the same
+func Forbidden(message string) Error {
+	return Error{Message: message, Status: http.StatusForbidden}
+}
+
 func ValidateTask(task model.Task) *Error {
 	// Validate each field of the task and return an error if a field is missing
 	if task.Title == "" {
@@ -42,11 +57,99 @@ func ValidateTask(task model.Task) *Error {
 	if task.Priority == "" {
 		return &Error{Message: "Priority is required", Status: 400}
 	}
+	if err := ValidatePriority(task.Priority); err != nil {
+		return err
+	}
 	if len(task.Tags) == 0 {
 		return &Error{Message: "Tags are required", Status: 400}
 	}
-	if len(task.Image) == 0 {
-		return &Error{Message: "Image is required", Status: 400}
+	if err := ValidateSchedule(task.StartAt, task.DueAt); err != nil {
+		return err
+	}
+	if err := ValidateEstimate(task.Points, task.Estimate); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidatePriority rejects priorities other than the known ones, which no filter or sort could find
+func ValidatePriority(priority model.Priority) *Error {
+	if !priority.Valid() {
+		names := make([]string, len(model.Priorities))
+		for i, known := range model.Priorities {
+			names[i] = string(known)
+		}
+		return &Error{Message: "Priority must be one of " + strings.Join(names, ", "), Status: 400}
+	}
+	return nil
+}
+
+// MaxPoints is the largest story point value a task may carry
+const MaxPoints = 100
+
+// MaxEstimateMinutes is the largest time estimate a task may carry: 1000 hours
+const MaxEstimateMinutes = 60 * 1000
+
+// ValidateEstimate checks a task's story points and time estimate; either may be missing
+// Zero points is a valid estimate ("trivial"), a zero time estimate is not
+func ValidateEstimate(points, minutes *int) *Error {
+	if points != nil && (*points < 0 || *points > MaxPoints) {
+		return &Error{Message: fmt.Sprintf("points must be between 0 and %d", MaxPoints), Status: 400}
+	}
+	if minutes != nil && (*minutes < 1 || *minutes > MaxEstimateMinutes) {
+		return &Error{Message: fmt.Sprintf("estimate_minutes must be between 1 and %d", MaxEstimateMinutes), Status: 400}
+	}
+	return nil
+}
+
+// ValidateSchedule checks that a task starts before it is due
+// Either date may be missing, in which case there is nothing to compare
+func ValidateSchedule(startAt, dueAt *time.Time) *Error {
+	if startAt != nil && dueAt != nil && !startAt.Before(*dueAt) {
+		return &Error{Message: "start_at must be before due_at", Status: 400}
+	}
+	return nil
+}
+
+// projectColor matches a CSS hex color like #1E90FF
+var projectColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ValidateProject checks the fields a project is created or updated with
+func ValidateProject(name, color, description string) *Error {
+	if strings.TrimSpace(name) == "" {
+		return &Error{Message: "Name is required", Status: 400}
+	}
+	if len(name) > 100 {
+		return &Error{Message: "Name must be at most 100 characters", Status: 400}
+	}
+	if color != "" && !projectColor.MatchString(color) {
+		return &Error{Message: "Color must be a hex color like #1E90FF", Status: 400}
+	}
+	if len(description) > 1000 {
+		return &Error{Message: "Description must be at most 1000 characters", Status: 400}
+	}
+	return nil
+}
+
+// ValidateRegistration checks the fields needed to create a user account
+func ValidateRegistration(name, email, password string) *Error {
+	if name == "" {
+		return &Error{Message: "Name is required", Status: 400}
+	}
+	if email == "" {
+		return &Error{Message: "Email is required", Status: 400}
+	}
+	// mail.ParseAddress accepts "Name <a@b.c>" too, so also require the result to be the bare address
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return &Error{Message: "Email is not valid", Status: 400}
+	}
+	if len(password) < 8 {
+		return &Error{Message: "Password must be at least 8 characters", Status: 400}
+	}
+	// bcrypt only looks at the first 72 bytes, longer passwords would be silently truncated
+	if len(password) > 72 {
+		return &Error{Message: "Password must be at most 72 bytes", Status: 400}
 	}
 
 	return nil
@@ -0,0 +1,14 @@
+package helpers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DBContext derives a context with a timeout from the incoming request for the store calls of a handler
+// If the client disconnects, the request context is cancelled and so is the database call
+func DBContext(ctx *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx.Request.Context(), 10*time.Second)
+}
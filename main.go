@@ -1,26 +1,88 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+	_ "time/tzdata" // Embeds the time zone database so ?tz= works on slim images without /usr/share/zoneinfo
 
+	"github.com/joho/godotenv"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/blob"
 	"github.com/joshua-takyi/todo/connection"
+	"github.com/joshua-takyi/todo/cursor"
+	"github.com/joshua-takyi/todo/model"
 	"github.com/joshua-takyi/todo/router"
+	"github.com/joshua-takyi/todo/store"
+	"github.com/joshua-takyi/todo/workflow"
 )
 
 func main() {
-	if err := connection.Init(); err != nil {
+	// -assign-ownerless-to is a one-off migration: tasks created before tasks had
+	// owners are invisible to everyone until they are given to a user
+	assignTo := flag.String("assign-ownerless-to", "", "give every task without an owner to the user with this email, then exit")
+	// -make-admin promotes an existing account; new accounts can be made admins with ADMIN_EMAILS
+	makeAdmin := flag.String("make-admin", "", "give the admin role to the user with this email, then exit")
+	flag.Parse()
+
+	// Load .env.local early so STORAGE can be read before choosing a backend
+	// A missing file is fine: production sets real environment variables
+	_ = godotenv.Load(".env.local")
+
+	stores, err := openStores()
+	if err != nil {
 		fmt.Println("Database connection error:", err.Error())
 		return
 	}
 
 	defer func() {
-		if err := connection.Close(); err != nil {
+		if err := stores.Close(); err != nil {
 			fmt.Println("Error closing database connection:", err.Error())
 		}
 	}()
 
-	r := router.Router()
+	if *assignTo != "" {
+		if err := assignOwnerless(stores, *assignTo); err != nil {
+			fmt.Println("Migration error:", err.Error())
+		}
+		return
+	}
+
+	if *makeAdmin != "" {
+		if err := promoteAdmin(stores, *makeAdmin); err != nil {
+			fmt.Println("Promotion error:", err.Error())
+		}
+		return
+	}
+
+	tokens, err := auth.NewTokenIssuerFromEnv()
+	if err != nil {
+		fmt.Println("Token configuration error:", err.Error())
+		return
+	}
+
+	flow, err := workflow.FromEnv()
+	if err != nil {
+		fmt.Println("Workflow configuration error:", err.Error())
+		return
+	}
+
+	blobs, err := blob.FromEnv()
+	if err != nil {
+		fmt.Println("File storage configuration error:", err.Error())
+		return
+	}
+
+	cursors, err := cursor.FromEnv()
+	if err != nil {
+		fmt.Println("Cursor configuration error:", err.Error())
+		return
+	}
+
+	r := router.Router(stores, tokens, flow, blobs, cursors)
 
 	// Get port from environment variable for cloud deployment compatibility
 	// Platforms like Render typically provide the port via the PORT environment variable
@@ -40,3 +102,87 @@ func main() {
 		return
 	}
 }
+
+// openStores picks the storage backend from the STORAGE environment variable
+// - mongo (default): connects to MONGODB_URI
+// - memory: keeps everything in process memory, no external services needed
+// - sqlite: stores everything in the file at SQLITE_PATH (default todo.db)
+func openStores() (store.Stores, error) {
+	backend := strings.ToLower(os.Getenv("STORAGE"))
+
+	switch backend {
+	case "memory":
+		fmt.Println("Using in-memory storage (data is lost on restart)")
+		return store.OpenMemory(), nil
+
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "todo.db"
+		}
+		stores, err := store.OpenSQLite(path)
+		if err != nil {
+			return store.Stores{}, err
+		}
+		fmt.Println("Using SQLite storage at", path)
+		return stores, nil
+
+	case "", "mongo", "mongodb":
+		if err := connection.Init(); err != nil {
+			return store.Stores{}, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// Build the MongoDB backed stores once the client is connected
+		stores, err := store.OpenMongo(ctx, connection.Client)
+		if err != nil {
+			connection.Close()
+			return store.Stores{}, err
+		}
+		// The connection package owns the client, so closing the stores means closing it
+		stores.Close = connection.Close
+		return stores, nil
+
+	default:
+		return store.Stores{}, fmt.Errorf("unknown STORAGE %q (expected mongo, memory or sqlite)", backend)
+	}
+}
+
+// assignOwnerless gives every ownerless task to the user registered with email
+func assignOwnerless(stores store.Stores, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	user, err := stores.Users.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return fmt.Errorf("look up %s: %w", email, err)
+	}
+
+	changed, err := stores.Tasks.AssignOwnerless(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Assigned %d ownerless task(s) to %s\n", changed, user.Email)
+	return nil
+}
+
+// promoteAdmin gives the admin role to the user registered with email
+func promoteAdmin(stores store.Stores, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := stores.Users.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return fmt.Errorf("look up %s: %w", email, err)
+	}
+
+	if _, err := stores.Users.UpdateUserRole(ctx, user.ID, model.RoleAdmin); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is now an admin\n", user.Email)
+	return nil
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attachment is a file uploaded to a task; the bytes live in the blob store, only this metadata on the task
+type Attachment struct {
+	ID           primitive.ObjectID `json:"id"                      bson:"_id"`
+	FileName     string             `json:"file_name"               bson:"file_name"`    // Name the client uploaded it with
+	ContentType  string             `json:"content_type"            bson:"content_type"` // Sniffed from the content, not taken from the client
+	Size         int64              `json:"size"                    bson:"size"`         // In bytes
+	Width        int                `json:"width,omitempty"         bson:"width,omitempty"`
+	Height       int                `json:"height,omitempty"        bson:"height,omitempty"`
+	Key          string             `json:"-"                       bson:"key"`                     // Where the blob store keeps the file
+	ThumbnailKey string             `json:"-"                       bson:"thumbnail_key,omitempty"` // Empty when no thumbnail could be made
+	UploadedBy   primitive.ObjectID `json:"uploaded_by"             bson:"uploaded_by"`
+	UploadedAt   time.Time          `json:"uploaded_at"             bson:"uploaded_at"`
+	URL          string             `json:"url"                     bson:"-"` // Computed: where to download the file
+	ThumbnailURL string             `json:"thumbnail_url,omitempty" bson:"-"`
+}
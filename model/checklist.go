@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChecklistItem is one line of a task's checklist, for steps too small to be subtasks
+type ChecklistItem struct {
+	ID        primitive.ObjectID `json:"id"                bson:"_id"`
+	Text      string             `json:"text"              bson:"text"`
+	Done      bool               `json:"done"              bson:"done"`
+	DoneAt    *time.Time         `json:"done_at,omitempty" bson:"done_at,omitempty"` // When the item was last checked
+	CreatedAt time.Time          `json:"created_at"        bson:"created_at"`
+}
+
+// ChecklistProgress tells how many checklist items are checked, computed on every response
+type ChecklistProgress struct {
+	Done    int `json:"done"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"`
+}
+
+// ChecklistProgress summarizes the task's checklist, or returns nil for a task without one
+func (t Task) ChecklistProgress() *ChecklistProgress {
+	if len(t.Checklist) == 0 {
+		return nil
+	}
+	progress := &ChecklistProgress{Total: len(t.Checklist)}
+	for _, item := range t.Checklist {
+		if item.Done {
+			progress.Done++
+		}
+	}
+	progress.Percent = progress.Done * 100 / progress.Total
+	return progress
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Comment is one message in the discussion thread of a task
+// Deleting a comment only marks it: the thread keeps a placeholder so replies still make sense
+type Comment struct {
+	ID        primitive.ObjectID `json:"id"                   bson:"_id"`
+	TaskID    primitive.ObjectID `json:"task_id"              bson:"task_id"`
+	OwnerID   primitive.ObjectID `json:"-"                    bson:"owner_id"` // Owner of the task, so comments are scoped like their task
+	AuthorID  primitive.ObjectID `json:"author_id"            bson:"author_id"`
+	Body      string             `json:"body"                 bson:"body"` // Markdown, rendered by the client; kept but not shown once deleted
+	EditedAt  *time.Time         `json:"edited_at,omitempty"  bson:"edited_at,omitempty"`
+	DeletedAt *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	History   []CommentRevision  `json:"-"                    bson:"history,omitempty"` // Earlier bodies, oldest first
+	Metadata  Metadata           `json:"metadata"             bson:"metadata"`
+}
+
+// CommentRevision is a body a comment had before it was edited
+type CommentRevision struct {
+	Body      string    `json:"body"       bson:"body"`
+	WrittenAt time.Time `json:"written_at" bson:"written_at"` // When this body was posted or last edited in
+}
+
+// IsDeleted reports whether the comment was deleted
+func (c Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
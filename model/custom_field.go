@@ -0,0 +1,51 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// FieldType is the kind of value a custom field holds
+type FieldType string
+
+const (
+	FieldText        FieldType = "text"
+	FieldNumber      FieldType = "number"
+	FieldDate        FieldType = "date" // A calendar date, stored as YYYY-MM-DD
+	FieldSelect      FieldType = "select"
+	FieldMultiSelect FieldType = "multi_select"
+	FieldCheckbox    FieldType = "checkbox"
+)
+
+// FieldTypes lists every custom field type
+var FieldTypes = []FieldType{FieldText, FieldNumber, FieldDate, FieldSelect, FieldMultiSelect, FieldCheckbox}
+
+// Valid reports whether t is one of the known field types
+func (t FieldType) Valid() bool {
+	for _, fieldType := range FieldTypes {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// HasOptions reports whether values of this type are picked from the field's options
+func (t FieldType) HasOptions() bool {
+	return t == FieldSelect || t == FieldMultiSelect
+}
+
+// CustomField defines a piece of metadata a user can fill in on their tasks, like "customer" or "sprint"
+// The key and the type are fixed once the field exists, so stored values always match the definition
+type CustomField struct {
+	ID       primitive.ObjectID `json:"id"                bson:"_id"`
+	OwnerID  primitive.ObjectID `json:"owner_id"          bson:"owner_id"`
+	Key      string             `json:"key"               bson:"key"` // Name of the value in a task's custom_fields, unique per owner
+	Name     string             `json:"name"              bson:"name"`
+	Type     FieldType          `json:"type"              bson:"type"`
+	Options  []string           `json:"options,omitempty" bson:"options,omitempty"` // The values select and multi_select fields accept
+	Required bool               `json:"required"          bson:"required"`          // New tasks must set it and no task may clear it
+	Metadata Metadata           `json:"metadata"          bson:"metadata"`
+}
+
+// FieldValues holds a task's custom field values by key
+// Values are stored normalized: a string for text, date and select fields,
+// a float64 for numbers, a list of strings for multi_select and a bool for checkboxes
+type FieldValues map[string]interface{}
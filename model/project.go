@@ -0,0 +1,21 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Project groups tasks into a list, like "Work" or "Groceries"
+type Project struct {
+	ID          primitive.ObjectID `json:"id"               bson:"_id"`
+	OwnerID     primitive.ObjectID `json:"owner_id"         bson:"owner_id"`
+	Name        string             `json:"name"             bson:"name"`
+	Color       string             `json:"color,omitempty"  bson:"color,omitempty"` // "#RRGGBB", empty for the client's default
+	Description string             `json:"description"      bson:"description"`
+	Archived    bool               `json:"archived"         bson:"archived"` // Archived projects are hidden from the list and take no new tasks
+	Counts      *ProjectCounts     `json:"counts,omitempty" bson:"-"`        // Computed on every response, never stored
+	Metadata    Metadata           `json:"metadata"         bson:"metadata"`
+}
+
+// ProjectCounts tells how many of a project's tasks are still open and how many are done
+type ProjectCounts struct {
+	Open      int64 `json:"open"`
+	Completed int64 `json:"completed"`
+}
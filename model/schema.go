@@ -3,18 +3,107 @@ package model
 import (
 	"time"
 
+	"github.com/joshua-takyi/todo/rank"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 ) // Corrected import path for MongoDB driver
 
 type Task struct {
-	ID          primitive.ObjectID `json:"id,omitempty"       bson:"_id"`
-	Title       string             `json:"title"              bson:"title"       binding:"required,min=1,max=100"`
-	Description string             `json:"description"        bson:"description" binding:"max=1000"`
-	Image       []string           `json:"image"              bson:"image"`
-	Priority    Priority           `json:"priority"           bson:"priority"    binding:"required"`
-	Tags        []string           `json:"tags,omitempty"     bson:"tags"        binding:"dive,max=20"`
-	Completed   bool               `json:"completed"          bson:"completed"`
-	Metadata    Metadata           `json:"metadata"           bson:"metadata"`
+	ID          primitive.ObjectID   `json:"id,omitempty"       bson:"_id"`
+	OwnerID     primitive.ObjectID   `json:"owner_id"           bson:"owner_id"`
+	ParentID    *primitive.ObjectID  `json:"parent_id,omitempty" bson:"parent_id,omitempty"`   // Set on subtasks, nil for top-level tasks
+	ProjectID   *primitive.ObjectID  `json:"project_id,omitempty" bson:"project_id,omitempty"` // Nil for tasks outside any project
+	Title       string               `json:"title"              bson:"title"       binding:"required,min=1,max=100"`
+	Description string               `json:"description"        bson:"description" binding:"max=1000"`
+	Image       []string             `json:"image"              bson:"image"`
+	Priority    Priority             `json:"priority"           bson:"priority"    binding:"required"`
+	Tags        []string             `json:"tags,omitempty"     bson:"tags"        binding:"dive,max=20"`
+	Completed   bool                 `json:"completed"          bson:"completed"` // Derived: true while Status is terminal
+	Status      Status               `json:"status"             bson:"status,omitempty"`
+	StatusSince StatusTimes          `json:"status_entered_at,omitempty" bson:"status_entered_at,omitempty"`
+	Rank        string               `json:"rank,omitempty"     bson:"rank,omitempty"`         // Board position, see EffectiveRank
+	StartAt     *time.Time           `json:"start_at,omitempty" bson:"start_at,omitempty"`     // When work is planned to begin
+	DueAt       *time.Time           `json:"due_at,omitempty"   bson:"due_at,omitempty"`       // Deadline; both dates are stored in UTC
+	Recurrence  *Recurrence          `json:"recurrence,omitempty" bson:"recurrence,omitempty"` // Nil for one-off tasks
+	BlockedBy   []primitive.ObjectID `json:"blocked_by,omitempty" bson:"blocked_by,omitempty"` // Tasks that must be completed before this one
+	Blocked     bool                 `json:"blocked"            bson:"-"`                      // Computed: true while any blocker is still open
+	Overdue     bool                 `json:"overdue"            bson:"-"`                      // Computed on every response, never stored
+	Points      *int                 `json:"points,omitempty"   bson:"points,omitempty"`
+	Estimate    *int                 `json:"estimate_minutes,omitempty" bson:"estimate_minutes,omitempty"`
+	Fields      FieldValues          `json:"custom_fields,omitempty" bson:"custom_fields,omitempty"`
+	Attachments []Attachment         `json:"attachments,omitempty" bson:"attachments,omitempty"`
+	Checklist   []ChecklistItem      `json:"checklist,omitempty" bson:"checklist,omitempty"`
+	Progress    *ChecklistProgress   `json:"checklist_progress,omitempty" bson:"-"`
+	Metadata    Metadata             `json:"metadata"           bson:"metadata"`
+}
+
+// Recurrence makes a task repeat: completing one occurrence creates the next one
+type Recurrence struct {
+	Rule     string              `json:"rule"              bson:"rule"`              // RFC 5545 RRULE, e.g. FREQ=WEEKLY;BYDAY=MO
+	TimeZone string              `json:"time_zone"         bson:"time_zone"`         // IANA zone the rule is read in, so "Monday" is the user's Monday
+	Anchor   time.Time           `json:"anchor"            bson:"anchor"`            // DTSTART of the series: the due date of its first task
+	SeriesID primitive.ObjectID  `json:"series_id"         bson:"series_id"`         // ID of the first task of the series
+	NextID   *primitive.ObjectID `json:"next_id,omitempty" bson:"next_id,omitempty"` // Set once the following occurrence was created
+}
+
+// IsOverdue reports whether the task is still open after its due date
+func (t Task) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// EffectiveStatus returns the task's status, deriving it from Completed
+// for tasks created before statuses existed (empty status)
+func (t Task) EffectiveStatus() Status {
+	if t.Status != "" {
+		return t.Status
+	}
+	if t.Completed {
+		return StatusDone
+	}
+	return StatusTodo
+}
+
+// EffectiveRank returns the task's position inside its board column
+// Tasks that were never moved on the board have no stored rank: they rank by ID,
+// which keeps them in creation order without writing every task up front
+func (t Task) EffectiveRank() string {
+	if t.Rank != "" {
+		return t.Rank
+	}
+	return rank.FromKey(t.ID.Hex())
+}
+
+// Status is where a task stands in the workflow
+// Which moves between statuses are allowed is configured in the workflow package
+type Status string
+
+const (
+	StatusTodo       Status = "todo"
+	StatusInProgress Status = "in_progress"
+	StatusInReview   Status = "in_review"
+	StatusDone       Status = "done"      // Terminal
+	StatusCancelled  Status = "cancelled" // Terminal
+)
+
+// StatusTimes records when a task last entered each status
+type StatusTimes map[Status]time.Time
+
+// Statuses lists every status in workflow order
+var Statuses = []Status{StatusTodo, StatusInProgress, StatusInReview, StatusDone, StatusCancelled}
+
+// Valid reports whether s is one of the known statuses
+func (s Status) Valid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether a task in this status is finished
+// Tasks in a terminal status count as completed
+func (s Status) IsTerminal() bool {
+	return s == StatusDone || s == StatusCancelled
 }
 
 type Priority string
@@ -25,6 +114,29 @@ const (
 	PriorityHigh   Priority = "high"
 )
 
+// Priorities lists every priority from lowest to highest
+var Priorities = []Priority{PriorityLow, PriorityMedium, PriorityHigh}
+
+// Valid reports whether p is one of the known priorities
+func (p Priority) Valid() bool {
+	for _, priority := range Priorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// Rank is the position of p in Priorities, low being 0, or -1 for an unknown priority
+func (p Priority) Rank() int {
+	for i, priority := range Priorities {
+		if p == priority {
+			return i
+		}
+	}
+	return -1
+}
+
 type Metadata struct {
 	CreatedAt time.Time `json:"created_at"             bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"             bson:"updated_at"`
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimeEntry is a stretch of time someone spent on a task, tracked with a timer or logged by hand
+// A running timer is an entry without an end; each user has at most one of those
+type TimeEntry struct {
+	ID        primitive.ObjectID `json:"id"                 bson:"_id"`
+	TaskID    primitive.ObjectID `json:"task_id"            bson:"task_id"`
+	OwnerID   primitive.ObjectID `json:"-"                  bson:"owner_id"` // Owner of the task, so entries are scoped like their task
+	UserID    primitive.ObjectID `json:"user_id"            bson:"user_id"`  // Who tracked the time
+	StartedAt time.Time          `json:"started_at"         bson:"started_at"`
+	EndedAt   *time.Time         `json:"ended_at,omitempty" bson:"ended_at,omitempty"` // Nil while the timer runs
+	Seconds   int64              `json:"seconds"            bson:"seconds"`            // Stored once stopped, computed on responses while running
+	Running   bool               `json:"running"            bson:"running"`
+	Manual    bool               `json:"manual"             bson:"manual"` // Logged by hand instead of with the timer
+	Note      string             `json:"note,omitempty"     bson:"note,omitempty"`
+	Metadata  Metadata           `json:"metadata"           bson:"metadata"`
+}
+
+// TaskTime adds up the stopped time entries of one task
+type TaskTime struct {
+	TaskID  primitive.ObjectID `json:"task_id" bson:"_id"`
+	Seconds int64              `json:"seconds" bson:"seconds"`
+	Entries int64              `json:"entries" bson:"entries"`
+}
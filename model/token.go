@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken is the server side record of a long lived refresh token
+// Only a SHA-256 hash of the token is stored, so a database leak doesn't hand out sessions
+// Every token created by rotating another one shares its FamilyID, which lets us
+// revoke a whole login session at once when a stolen token is replayed
+type RefreshToken struct {
+	ID        primitive.ObjectID `json:"id"                   bson:"_id"`
+	UserID    primitive.ObjectID `json:"user_id"              bson:"user_id"`
+	FamilyID  primitive.ObjectID `json:"family_id"            bson:"family_id"`
+	TokenHash string             `json:"-"                    bson:"token_hash"`
+	ExpiresAt time.Time          `json:"expires_at"           bson:"expires_at"`
+	CreatedAt time.Time          `json:"created_at"           bson:"created_at"`
+	RevokedAt *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// APIKeyScope limits what a request authenticated with an API key may do
+type APIKeyScope string
+
+const (
+	APIKeyScopeRead      APIKeyScope = "read"       // GET requests only
+	APIKeyScopeReadWrite APIKeyScope = "read-write" // Everything the owner can do
+)
+
+// APIKey is a long lived credential for scripts that can't log in interactively
+// Like refresh tokens only the SHA-256 hash of the key is stored; Prefix keeps the
+// first few characters so users can tell their keys apart in a list
+type APIKey struct {
+	ID         primitive.ObjectID `json:"id"                     bson:"_id"`
+	UserID     primitive.ObjectID `json:"user_id"                bson:"user_id"`
+	Name       string             `json:"name"                   bson:"name"`
+	Prefix     string             `json:"prefix"                 bson:"prefix"`
+	KeyHash    string             `json:"-"                      bson:"key_hash"`
+	Scope      APIKeyScope        `json:"scope"                  bson:"scope"`
+	CreatedAt  time.Time          `json:"created_at"             bson:"created_at"`
+	LastUsedAt *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time         `json:"revoked_at,omitempty"   bson:"revoked_at,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type User struct {
+	ID           primitive.ObjectID `json:"id"                 bson:"_id"`
+	Name         string             `json:"name"               bson:"name"`
+	Email        string             `json:"email"              bson:"email"`
+	PasswordHash string             `json:"-"                  bson:"password_hash"` // "-" keeps the hash out of every JSON response
+	Role         Role               `json:"role"               bson:"role"`
+	Metadata     Metadata           `json:"metadata"           bson:"metadata"`
+}
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"  // Can see and delete every task and manage users
+	RoleMember Role = "member" // Can read and write their own tasks
+	RoleViewer Role = "viewer" // Can only read their own tasks
+)
+
+// Valid reports whether r is one of the known roles
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleMember || r == RoleViewer
+}
+
+// EffectiveRole returns the user's role, treating accounts created before
+// roles existed (empty role) as members
+func (u User) EffectiveRole() Role {
+	if u.Role == "" {
+		return RoleMember
+	}
+	return u.Role
+}
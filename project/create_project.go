@@ -0,0 +1,64 @@
+package project
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// projectRequest is the JSON body accepted by CreateProject
+type projectRequest struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+// CreateProject creates an empty project for the logged in user
+func (h *Handler) CreateProject(ctx *gin.Context) {
+	var req projectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := helpers.ValidateProject(req.Name, req.Color, req.Description); err != nil {
+		ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+		return
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	project := model.Project{
+		ID:          primitive.NewObjectID(),
+		OwnerID:     scope.OwnerID,
+		Name:        req.Name,
+		Color:       req.Color,
+		Description: req.Description,
+		Counts:      &model.ProjectCounts{}, // A new project has no tasks yet
+		Metadata:    model.Metadata{CreatedAt: now, UpdatedAt: now},
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	if err := h.Projects.CreateProject(dbCtx, &project); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Project created successfully",
+		"project": project,
+	})
+}
@@ -0,0 +1,46 @@
+package project
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// DeleteProject deletes a project; its tasks are kept but no longer belong to any project
+func (h *Handler) DeleteProject(ctx *gin.Context) {
+	id, ok := projectID(ctx)
+	if !ok {
+		return
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Empty the project first: if the delete then fails, the tasks are simply outside any project
+	detached, err := h.Tasks.DetachProject(dbCtx, scope, id)
+	if err == nil {
+		err = h.Projects.DeleteProject(dbCtx, scope, id)
+	}
+	if errors.Is(err, store.ErrProjectNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":        "Project deleted successfully",
+		"detached_tasks": detached,
+	})
+}
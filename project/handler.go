@@ -0,0 +1,65 @@
+package project
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Handler groups the project handlers
+// It needs the task store too, for the per-project task counts and to empty a project before deleting it
+type Handler struct {
+	Projects store.ProjectStore
+	Tasks    store.TaskStore
+}
+
+// NewHandler creates a Handler backed by the given stores
+func NewHandler(stores store.Stores) *Handler {
+	return &Handler{Projects: stores.Projects, Tasks: stores.Tasks}
+}
+
+// projectID parses the :id path parameter
+// It writes a 400 and returns false when the ID is malformed
+func projectID(ctx *gin.Context) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return primitive.NilObjectID, false
+	}
+	return id, true
+}
+
+// withCounts fills in the open/completed task counts of every project with a single store call
+func (h *Handler) withCounts(ctx context.Context, scope store.Scope, projects []model.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, len(projects))
+	for i, project := range projects {
+		ids[i] = project.ID
+	}
+	counts, err := h.Tasks.CountByProject(ctx, scope, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range projects {
+		count := counts[projects[i].ID] // Zero counts for projects without tasks
+		projects[i].Counts = &count
+	}
+	return nil
+}
+
+// withCount is withCounts for a single project
+func (h *Handler) withCount(ctx context.Context, scope store.Scope, project model.Project) (model.Project, error) {
+	projects := []model.Project{project}
+	if err := h.withCounts(ctx, scope, projects); err != nil {
+		return model.Project{}, err
+	}
+	return projects[0], nil
+}
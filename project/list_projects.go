@@ -0,0 +1,83 @@
+package project
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// ListProjects returns the caller's projects, oldest first, with their task counts
+// Query parameters:
+// - include_archived: true to list archived projects as well (default: false)
+func (h *Handler) ListProjects(ctx *gin.Context) {
+	includeArchived := false
+	if value := ctx.Query("include_archived"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid include_archived: use true or false"})
+			return
+		}
+		includeArchived = parsed
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	projects, err := h.Projects.ListProjects(dbCtx, scope, includeArchived)
+	if err == nil {
+		err = h.withCounts(dbCtx, scope, projects)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve projects: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Projects retrieved successfully",
+		"projects": projects,
+	})
+}
+
+// GetProject returns one of the caller's projects with its task counts
+func (h *Handler) GetProject(ctx *gin.Context) {
+	id, ok := projectID(ctx)
+	if !ok {
+		return
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	project, err := h.Projects.GetProject(dbCtx, scope, id)
+	if err == nil {
+		project, err = h.withCount(dbCtx, scope, project)
+	}
+	if errors.Is(err, store.ErrProjectNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Project retrieved successfully",
+		"project": project,
+	})
+}
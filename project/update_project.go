@@ -0,0 +1,77 @@
+package project
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// UpdateProject changes the name, color, description or archived flag of a project
+// Only the fields present in the body change
+func (h *Handler) UpdateProject(ctx *gin.Context) {
+	id, ok := projectID(ctx)
+	if !ok {
+		return
+	}
+
+	var update store.ProjectUpdate
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if update == (store.ProjectUpdate{}) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Update payload cannot be empty"})
+		return
+	}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		update.Name = &name
+	}
+
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Validate the project as it will be after the update, like PatchTask does for dates
+	current, err := h.Projects.GetProject(dbCtx, scope, id)
+	if errors.Is(err, store.ErrProjectNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load project: " + err.Error()})
+		return
+	}
+	update.Apply(&current)
+	if err := helpers.ValidateProject(current.Name, current.Color, current.Description); err != nil {
+		ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.Projects.UpdateProject(dbCtx, scope, id, update)
+	if err == nil {
+		project, err = h.withCount(dbCtx, scope, project)
+	}
+	if errors.Is(err, store.ErrProjectNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Project updated successfully",
+		"project": project,
+	})
+}
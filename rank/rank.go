@@ -0,0 +1,89 @@
+// Package rank generates lexicographic ranks for manually ordered lists (like board columns)
+// A rank is a string of base-36 digits read as a fraction: "i" is 0.i, "3" is 0.3 and so on.
+// Between always finds a string that sorts between two others, so moving an item only
+// rewrites that item instead of renumbering everything below it
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits is the rank alphabet in sort order; ranks compare correctly as plain strings
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// ErrNoSpace is returned when there is no rank strictly between the two bounds
+// (the bounds are equal or in the wrong order)
+var ErrNoSpace = errors.New("rank: no rank between the given bounds")
+
+// Between returns a rank that sorts strictly after a and strictly before b
+// An empty a means "before everything" and an empty b means "after everything"
+// Results never end in the lowest digit, which keeps room before every rank
+func Between(a, b string) (string, error) {
+	if !valid(a) || !valid(b) || (b != "" && a >= b) {
+		return "", ErrNoSpace
+	}
+	if b == "" {
+		return after(a), nil
+	}
+
+	var out strings.Builder
+	bounded := true // Once the result is known to be below b, b stops constraining the digits
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(a) {
+			lo = strings.IndexByte(digits, a[i])
+		}
+		hi := len(digits)
+		if bounded {
+			hi = strings.IndexByte(digits, b[i]) // a < b and a isn't longer on the shared prefix, so b has a digit here
+		}
+
+		switch {
+		case hi-lo > 1:
+			// Room for a digit strictly in between: done
+			out.WriteByte(digits[(lo+hi)/2])
+			return out.String(), nil
+		case hi-lo == 1:
+			// Taking lo keeps the result below b whatever follows, so only a matters from here on
+			out.WriteByte(digits[lo])
+			bounded = false
+		default:
+			// Same digit in both bounds: keep it and look at the next position
+			out.WriteByte(digits[lo])
+		}
+	}
+}
+
+// after returns the closest convenient rank after a: its last digit bumped by one,
+// or a middle digit appended once the last digit is already the highest
+// Staying close to a (instead of jumping halfway to the end) means ranks made
+// later with FromKey still sort after it
+func after(a string) string {
+	mid := digits[len(digits)/2]
+	if a == "" {
+		return string(mid)
+	}
+	last := strings.IndexByte(digits, a[len(a)-1])
+	if last == len(digits)-1 {
+		return a + string(mid)
+	}
+	return a[:len(a)-1] + string(digits[last+1])
+}
+
+// FromKey turns a unique key written in rank digits (like a hex ObjectID) into a rank
+// Items that were never moved use it as their rank, so they keep a stable, distinct
+// position (in key order) without ever being written
+func FromKey(key string) string {
+	return strings.ToLower(key) + string(digits[len(digits)/2])
+}
+
+// valid reports whether s only uses rank digits and doesn't end in the lowest digit
+func valid(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(digits, s[i]) < 0 {
+			return false
+		}
+	}
+	return s == "" || s[len(s)-1] != digits[0]
+}
@@ -0,0 +1,87 @@
+package rank
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"", "", "i"},
+		{"a", "c", "b"},
+		{"a", "b", "ai"},   // Neighbouring digits: go one level deeper
+		{"", "1", "0i"},    // Only the lowest digit is below b
+		{"az", "b", "azi"}, // a's last digit is already the highest
+		{"a", "a1", "a0i"}, // b only extends a
+		{"a", "", "b"},     // After everything: bump the last digit
+		{"z", "", "zi"},    // ...or append once it can't be bumped
+		{"", "i", "9"},
+	}
+	for _, tt := range tests {
+		got, err := Between(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("Between(%q, %q) = %q, %v; want %q", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
+
+func TestBetweenRejects(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"a", "a"},  // Equal
+		{"b", "a"},  // Wrong order
+		{"a0", "b"}, // Ends in the lowest digit
+		{"A", ""},   // Not a rank digit
+		{"", "b-"},
+	}
+	for _, tt := range tests {
+		if got, err := Between(tt.a, tt.b); !errors.Is(err, ErrNoSpace) {
+			t.Errorf("Between(%q, %q) = %q, %v; want ErrNoSpace", tt.a, tt.b, got, err)
+		}
+	}
+}
+
+// Inserting at random places many times must keep every rank distinct, valid and in order
+func TestBetweenKeepsOrder(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	ranks := []string{}
+	for range 2000 {
+		i := random.Intn(len(ranks) + 1) // Insert before ranks[i]
+		var a, b string
+		if i > 0 {
+			a = ranks[i-1]
+		}
+		if i < len(ranks) {
+			b = ranks[i]
+		}
+		r, err := Between(a, b)
+		if err != nil {
+			t.Fatalf("Between(%q, %q): %v", a, b, err)
+		}
+		if r <= a || (b != "" && r >= b) || !valid(r) {
+			t.Fatalf("Between(%q, %q) = %q", a, b, r)
+		}
+		ranks = append(ranks[:i], append([]string{r}, ranks[i:]...)...)
+	}
+	if !sort.StringsAreSorted(ranks) {
+		t.Error("ranks are out of order")
+	}
+}
+
+func TestFromKey(t *testing.T) {
+	first, second := FromKey("65A1F0"), FromKey("65a1f1")
+	if first != "65a1f0i" || !valid(first) {
+		t.Errorf("FromKey = %q", first)
+	}
+	if first >= second {
+		t.Errorf("FromKey(%q) >= FromKey(%q), want key order", first, second)
+	}
+	// A rank placed right after a key-ranked item still sorts before the next key
+	if r, _ := Between(first, ""); r >= second {
+		t.Errorf("rank after %q = %q, sorts after %q", first, r, second)
+	}
+}
@@ -0,0 +1,82 @@
+// Package recurrence wraps RFC 5545 RRULE handling for recurring tasks
+// The rule string only describes the pattern (FREQ=WEEKLY;BYDAY=MO); the
+// start of the series (DTSTART) is the task's first due date and is passed in separately
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// Normalize checks a rule and returns it in canonical form
+// "rrule:freq=weekly;byday=mo" becomes "FREQ=WEEKLY;BYDAY=MO", so equal rules compare equal
+func Normalize(rule string) (string, error) {
+	rule = strings.ToUpper(strings.TrimSpace(rule))
+	rule = strings.TrimPrefix(rule, "RRULE:")
+	if rule == "" {
+		return "", errors.New("rule is required")
+	}
+	// The start of the series comes from the task, not from the rule
+	if strings.Contains(rule, "\n") || strings.Contains(rule, "DTSTART") {
+		return "", errors.New("DTSTART is not allowed, the task's due date starts the series")
+	}
+
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return "", fmt.Errorf("invalid RRULE: %w", err)
+	}
+	// Tasks repeating every second or minute would flood the list with occurrences
+	if option.Freq == rrule.SECONDLY || option.Freq == rrule.MINUTELY {
+		return "", errors.New("FREQ must be HOURLY or slower")
+	}
+	// NewRRule validates the ranges of the BY* parts (BYMONTH=13 and friends)
+	if _, err := rrule.NewRRule(*option); err != nil {
+		return "", fmt.Errorf("invalid RRULE: %w", err)
+	}
+	return option.RRuleString(), nil
+}
+
+// Upcoming returns up to n occurrences strictly after `after`
+// anchor is the first occurrence of the series and loc the zone the rule is read in,
+// so BYDAY=MO means Monday where the user lives and local times survive DST changes
+// Fewer than n times come back when the series ends (COUNT or UNTIL)
+func Upcoming(rule string, anchor time.Time, loc *time.Location, after time.Time, n int) ([]time.Time, error) {
+	option, err := rrule.StrToROptionInLocation(rule, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RRULE: %w", err)
+	}
+	option.Dtstart = anchor.In(loc)
+
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RRULE: %w", err)
+	}
+
+	// The iterator walks the series from its start; COUNT is counted from there too,
+	// which is why the anchor stays fixed for every occurrence of a series
+	times := []time.Time{}
+	next := r.Iterator()
+	for len(times) < n {
+		t, ok := next()
+		if !ok {
+			break
+		}
+		if t.After(after) {
+			times = append(times, t)
+		}
+	}
+	return times, nil
+}
+
+// Next returns the first occurrence after `after`; ok is false once the series has ended
+func Next(rule string, anchor time.Time, loc *time.Location, after time.Time) (next time.Time, ok bool, err error) {
+	times, err := Upcoming(rule, anchor, loc, after, 1)
+	if err != nil || len(times) == 0 {
+		return time.Time{}, false, err
+	}
+	return times[0], true, nil
+}
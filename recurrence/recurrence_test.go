@@ -0,0 +1,100 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{"rrule:freq=weekly;byday=mo", "FREQ=WEEKLY;BYDAY=MO", false},
+		{"  FREQ=DAILY;COUNT=3 ", "FREQ=DAILY;COUNT=3", false},
+		{"FREQ=HOURLY;INTERVAL=4", "FREQ=HOURLY;INTERVAL=4", false},
+		{"", "", true},
+		{"FREQ=MINUTELY", "", true},
+		{"FREQ=SECONDLY", "", true},
+		{"FREQ=MONTHLY;BYMONTH=13", "", true},
+		{"FREQ=FORTNIGHTLY", "", true},
+		{"DTSTART:20260101T000000Z\nRRULE:FREQ=DAILY", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.rule)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q, error %v", tt.rule, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	anchor := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC) // A Monday
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 9, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		rule  string
+		after time.Time
+		n     int
+		want  []time.Time
+	}{
+		{"daily", "FREQ=DAILY", anchor, 3, []time.Time{day(6), day(7), day(8)}},
+		{"weekly on two days", "FREQ=WEEKLY;BYDAY=MO,TH", anchor, 3, []time.Time{day(8), day(12), day(15)}},
+		// COUNT counts from the anchor, so later occurrences see fewer left
+		{"count from the anchor", "FREQ=DAILY;COUNT=3", anchor, 5, []time.Time{day(6), day(7)}},
+		{"count reached", "FREQ=DAILY;COUNT=3", day(7), 5, []time.Time{}},
+		{"until is inclusive", "FREQ=DAILY;UNTIL=20260108T090000Z", anchor, 5, []time.Time{day(6), day(7), day(8)}},
+		{"until passed", "FREQ=DAILY;UNTIL=20260108T090000Z", day(8), 5, []time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Upcoming(tt.rule, anchor, time.UTC, tt.after, tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUpcomingKeepsLocalTimeAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tz database:", err)
+	}
+	// Clocks go forward on 29 March 2026; a 09:00 meeting stays at 09:00 local
+	anchor := time.Date(2026, 3, 27, 9, 0, 0, 0, loc)
+	got, err := Upcoming("FREQ=DAILY", anchor.UTC(), loc, anchor, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, occurrence := range got {
+		if local := occurrence.In(loc); local.Hour() != 9 {
+			t.Errorf("occurrence %v is at %d:00 local, want 9:00", local, local.Hour())
+		}
+	}
+	if offset := got[1].Sub(got[0]); offset != 23*time.Hour {
+		t.Errorf("gap over the DST change = %v, want 23h", offset)
+	}
+}
+
+func TestNext(t *testing.T) {
+	anchor := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	next, ok, err := Next("FREQ=WEEKLY", anchor, time.UTC, anchor)
+	if err != nil || !ok || !next.Equal(anchor.AddDate(0, 0, 7)) {
+		t.Errorf("Next = %v, %v, %v; want a week later", next, ok, err)
+	}
+	if _, ok, err := Next("FREQ=WEEKLY;COUNT=1", anchor, time.UTC, anchor); ok || err != nil {
+		t.Errorf("Next after the last occurrence: ok %v, error %v", ok, err)
+	}
+}
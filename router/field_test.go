@@ -0,0 +1,80 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCustomFieldsOnTasks(t *testing.T) {
+	server := newTestServer(t)
+	alice := register(t, server, "alice")
+
+	define := func(body gin.H) string {
+		t.Helper()
+		code, res := call(t, server, http.MethodPost, "/api/v1/fields", alice, body)
+		if code != http.StatusCreated {
+			t.Fatalf("define %v: got %d %v", body["key"], code, res)
+		}
+		return res["field"].(map[string]interface{})["id"].(string)
+	}
+	define(gin.H{"key": "severity", "name": "Severity", "type": "select", "options": []string{"low", "high"}, "required": true})
+	define(gin.H{"key": "size", "name": "Size", "type": "number"})
+	teams := define(gin.H{"key": "teams", "name": "Teams", "type": "multi_select", "options": []string{"web", "ios"}})
+
+	if code, _ := call(t, server, http.MethodPost, "/api/v1/fields", alice, gin.H{"key": "size", "name": "Again", "type": "text"}); code != http.StatusConflict {
+		t.Errorf("define a key twice: got %d, want 409", code)
+	}
+
+	create := func(fields gin.H) (int, map[string]interface{}) {
+		return call(t, server, http.MethodPost, "/api/v1/tasks", alice, gin.H{
+			"title": "t", "description": "d", "priority": "low", "tags": []string{"a"}, "custom_fields": fields,
+		})
+	}
+	if code, _ := create(gin.H{"size": 3}); code != http.StatusBadRequest {
+		t.Errorf("create without the required field: got %d, want 400", code)
+	}
+	if code, _ := create(gin.H{"severity": "high", "color": "red"}); code != http.StatusBadRequest {
+		t.Errorf("create with an unknown field: got %d, want 400", code)
+	}
+	code, big := create(gin.H{"severity": "high", "size": 8, "teams": []string{"ios", "web", "ios"}})
+	if code != http.StatusCreated {
+		t.Fatalf("create: got %d %v", code, big)
+	}
+	if code, res := create(gin.H{"severity": "low", "size": 2}); code != http.StatusCreated {
+		t.Fatalf("create: got %d %v", code, res)
+	}
+
+	bigTask := "/api/v1/tasks/" + big["id"].(string)
+	if code, _ := call(t, server, http.MethodPatch, bigTask, alice, gin.H{"custom_fields": gin.H{"severity": nil}}); code != http.StatusBadRequest {
+		t.Errorf("clear a required field: got %d, want 400", code)
+	}
+
+	list := func(query string) []interface{} {
+		t.Helper()
+		code, res := call(t, server, http.MethodGet, "/api/v1/tasks?"+query, alice, nil)
+		if code != http.StatusOK {
+			t.Fatalf("list ?%s: got %d %v", query, code, res)
+		}
+		return res["tasks"].([]interface{})
+	}
+	if tasks := list("field.size.gte=5"); len(tasks) != 1 {
+		t.Errorf("size >= 5: %d tasks, want 1", len(tasks))
+	}
+	if tasks := list("field.teams=ios"); len(tasks) != 1 {
+		t.Errorf("teams has ios: %d tasks, want 1", len(tasks))
+	}
+	if code, _ := call(t, server, http.MethodGet, "/api/v1/tasks?field.severity.gt=low", alice, nil); code != http.StatusBadRequest {
+		t.Errorf("ordering a select field: got %d, want 400", code)
+	}
+
+	// Deleting a definition takes its values off every task
+	if code, res := call(t, server, http.MethodDelete, "/api/v1/fields/"+teams, alice, nil); code != http.StatusOK || res["cleared_tasks"] != float64(1) {
+		t.Fatalf("delete field: got %d %v", code, res)
+	}
+	_, res := call(t, server, http.MethodGet, bigTask, alice, nil)
+	if fields := res["task"].(map[string]interface{})["custom_fields"].(map[string]interface{}); fields["teams"] != nil {
+		t.Errorf("custom fields after the delete = %v", fields)
+	}
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTasksArePrivate(t *testing.T) {
+	server := newTestServer(t)
+	alice, bob, admin := register(t, server, "alice"), register(t, server, "bob"), register(t, server, "admin")
+
+	create := func(token, title string, extra gin.H) string {
+		t.Helper()
+		body := gin.H{"title": title, "description": "d", "priority": "low", "tags": []string{"shared"}}
+		for key, value := range extra {
+			body[key] = value
+		}
+		code, res := call(t, server, http.MethodPost, "/api/v1/tasks", token, body)
+		if code != http.StatusCreated {
+			t.Fatalf("create %s: got %d %v", title, code, res)
+		}
+		return res["id"].(string)
+	}
+	alicesTask := create(alice, "alice's plan", nil)
+	bobsTask := create(bob, "bob's plan", nil)
+	task := "/api/v1/tasks/" + alicesTask
+
+	// Every route on someone else's task answers as if it didn't exist
+	routes := []struct {
+		method, path string
+		body         gin.H
+	}{
+		{http.MethodGet, task, nil},
+		{http.MethodPatch, task, gin.H{"title": "mine now"}},
+		{http.MethodPatch, task + "/complete", nil},
+		{http.MethodPost, task + "/transitions", gin.H{"status": "in_progress"}},
+		{http.MethodPost, task + "/move", gin.H{}},
+		{http.MethodGet, task + "/children", nil},
+		{http.MethodGet, task + "/checklist", nil},
+		{http.MethodPost, task + "/comments", gin.H{"body": "hi"}},
+		{http.MethodPost, task + "/timer/start", nil},
+		{http.MethodPost, task + "/dependencies", gin.H{"blocker_id": bobsTask}},
+		{http.MethodDelete, task, nil},
+	}
+	for _, route := range routes {
+		if code, _ := call(t, server, route.method, route.path, bob, route.body); code != http.StatusNotFound {
+			t.Errorf("bob: %s %s: got %d, want 404", route.method, route.path, code)
+		}
+	}
+
+	// Nor can bob point his own tasks at hers
+	if code, _ := call(t, server, http.MethodPost, "/api/v1/tasks/"+bobsTask+"/dependencies", bob, gin.H{"blocker_id": alicesTask}); code != http.StatusBadRequest {
+		t.Errorf("bob's task blocked by alice's: got %d, want 400", code)
+	}
+	if code, _ := call(t, server, http.MethodPost, "/api/v1/tasks", bob, gin.H{
+		"title": "sub", "description": "d", "priority": "low", "tags": []string{"a"}, "parent_id": alicesTask,
+	}); code != http.StatusBadRequest {
+		t.Errorf("bob files a subtask under alice's task: got %d, want 400", code)
+	}
+
+	// Lists, search and the board only ever show the caller's own tasks
+	for _, path := range []string{"/api/v1/tasks", "/api/v1/tasks/search?q=plan"} {
+		_, body := call(t, server, http.MethodGet, path, bob, nil)
+		key := "tasks"
+		if body[key] == nil {
+			key = "results"
+		}
+		if got := body[key].([]interface{}); len(got) != 1 {
+			t.Errorf("bob: %s = %v, want only his task", path, got)
+		}
+	}
+	_, body := call(t, server, http.MethodGet, "/api/v1/board", bob, nil)
+	if body["total"] != float64(1) {
+		t.Errorf("bob's board holds %v tasks, want 1", body["total"])
+	}
+
+	// Alice's task is untouched by all of the above
+	_, body = call(t, server, http.MethodGet, task, alice, nil)
+	if got := body["task"].(map[string]interface{}); got["title"] != "alice's plan" || got["status"] != "todo" {
+		t.Errorf("alice's task = %v", got)
+	}
+
+	// Admins see everyone's tasks on their own routes, optionally for one owner
+	_, body = call(t, server, http.MethodGet, "/api/v1/admin/tasks", admin, nil)
+	if total := body["pagination"].(map[string]interface{})["total"]; total != float64(2) {
+		t.Errorf("admin sees %v tasks, want 2", total)
+	}
+	_, body = call(t, server, http.MethodGet, "/api/v1/admin/tasks?owner_id="+userID(t, server, alice), admin, nil)
+	if total := body["pagination"].(map[string]interface{})["total"]; total != float64(1) {
+		t.Errorf("admin sees %v of alice's tasks, want 1", total)
+	}
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProjects(t *testing.T) {
+	server := newTestServer(t)
+	alice, bob := register(t, server, "alice"), register(t, server, "bob")
+
+	for _, body := range []gin.H{
+		{"name": " "},
+		{"name": "Web", "color": "blue"},
+	} {
+		if code, _ := call(t, server, http.MethodPost, "/api/v1/projects", alice, body); code != http.StatusBadRequest {
+			t.Errorf("create %v: got %d, want 400", body, code)
+		}
+	}
+	code, body := call(t, server, http.MethodPost, "/api/v1/projects", alice, gin.H{"name": "Web", "color": "#1E90FF"})
+	if code != http.StatusCreated {
+		t.Fatalf("create: got %d %v", code, body)
+	}
+	projectID := body["project"].(map[string]interface{})["id"].(string)
+	project := "/api/v1/projects/" + projectID
+
+	createTask := func(token string, projectID interface{}) (int, string) {
+		code, res := call(t, server, http.MethodPost, "/api/v1/tasks", token, gin.H{
+			"title": "t", "description": "d", "priority": "low", "tags": []string{"a"}, "project_id": projectID,
+		})
+		id, _ := res["id"].(string)
+		return code, id
+	}
+	_, open := createTask(alice, projectID)
+	_, done := createTask(alice, projectID)
+	call(t, server, http.MethodPatch, "/api/v1/tasks/"+done+"/complete", alice, nil)
+	_, loose := createTask(alice, nil)
+
+	// Projects are as private as tasks
+	if code, _ := createTask(bob, projectID); code != http.StatusBadRequest {
+		t.Errorf("bob files a task into alice's project: got %d, want 400", code)
+	}
+	if code, _ := call(t, server, http.MethodGet, project, bob, nil); code != http.StatusNotFound {
+		t.Errorf("bob reads alice's project: got %d, want 404", code)
+	}
+
+	_, body = call(t, server, http.MethodGet, project, alice, nil)
+	if counts := body["project"].(map[string]interface{})["counts"].(map[string]interface{}); counts["open"] != float64(1) || counts["completed"] != float64(1) {
+		t.Errorf("counts = %v, want one open and one completed", counts)
+	}
+
+	countTasks := func(query string) int {
+		_, res := call(t, server, http.MethodGet, "/api/v1/tasks?"+query, alice, nil)
+		return len(res["tasks"].([]interface{}))
+	}
+	if n := countTasks("project_id=" + projectID); n != 2 {
+		t.Errorf("tasks in the project: %d, want 2", n)
+	}
+	if n := countTasks("project_id=none"); n != 1 {
+		t.Errorf("tasks outside any project: %d, want 1", n)
+	}
+
+	// Archived projects leave the list and take no new tasks
+	if code, _ := call(t, server, http.MethodPatch, project, alice, gin.H{"archived": true}); code != http.StatusOK {
+		t.Fatalf("archive: got %d", code)
+	}
+	if _, res := call(t, server, http.MethodGet, "/api/v1/projects", alice, nil); len(res["projects"].([]interface{})) != 0 {
+		t.Errorf("archived project still listed: %v", res["projects"])
+	}
+	if _, res := call(t, server, http.MethodGet, "/api/v1/projects?include_archived=true", alice, nil); len(res["projects"].([]interface{})) != 1 {
+		t.Errorf("include_archived: %v", res["projects"])
+	}
+	if code, _ := createTask(alice, projectID); code != http.StatusBadRequest {
+		t.Errorf("task into an archived project: got %d, want 400", code)
+	}
+	if code, _ := call(t, server, http.MethodPatch, "/api/v1/tasks/"+loose, alice, gin.H{"project_id": projectID}); code != http.StatusBadRequest {
+		t.Errorf("move a task into an archived project: got %d, want 400", code)
+	}
+
+	// Deleting the project keeps its tasks
+	code, body = call(t, server, http.MethodDelete, project, alice, nil)
+	if code != http.StatusOK || body["detached_tasks"] != float64(2) {
+		t.Fatalf("delete: got %d %v", code, body)
+	}
+	_, body = call(t, server, http.MethodGet, "/api/v1/tasks/"+open, alice, nil)
+	if task := body["task"].(map[string]interface{}); task["project_id"] != nil {
+		t.Errorf("task still in the deleted project: %v", task["project_id"])
+	}
+}
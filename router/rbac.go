@@ -0,0 +1,44 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+)
+
+// requireRole only lets users with one of the given roles through
+// It must run after auth.Middleware, which attaches the user to the context
+func requireRole(roles ...model.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := auth.CurrentUser(ctx)
+		if !ok {
+			auth.AbortWithError(ctx, helpers.Error{Message: "Not authenticated", Status: http.StatusUnauthorized})
+			return
+		}
+
+		role := user.EffectiveRole()
+		for _, allowed := range roles {
+			if role == allowed {
+				ctx.Next()
+				return
+			}
+		}
+		auth.AbortWithError(ctx, helpers.Forbidden("Your role ("+string(role)+") is not allowed to do this"))
+	}
+}
+
+// viewersReadOnly stops viewers from changing anything
+// Viewers may still read their own tasks, so only unsafe methods are refused
+func viewersReadOnly() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := auth.CurrentUser(ctx)
+		if ok && user.EffectiveRole() == model.RoleViewer && !auth.IsReadOnlyMethod(ctx.Request.Method) {
+			auth.AbortWithError(ctx, helpers.Forbidden("Viewers have read-only access"))
+			return
+		}
+		ctx.Next()
+	}
+}
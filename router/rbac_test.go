@@ -0,0 +1,120 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userID reads the account ID behind a token
+func userID(t *testing.T, server *httptest.Server, token string) string {
+	t.Helper()
+	code, body := call(t, server, http.MethodGet, "/api/v1/auth/me", token, nil)
+	if code != http.StatusOK {
+		t.Fatalf("me: got %d %v", code, body)
+	}
+	return body["user"].(map[string]interface{})["id"].(string)
+}
+
+func TestAdminRoutesNeedAdminRole(t *testing.T) {
+	server := newTestServer(t)
+	alice, admin := register(t, server, "alice"), register(t, server, "admin")
+	aliceID := userID(t, server, alice)
+
+	forbidden := []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/admin/tasks"},
+		{http.MethodDelete, "/api/v1/admin/tasks/" + aliceID},
+		{http.MethodGet, "/api/v1/admin/users"},
+		{http.MethodPatch, "/api/v1/admin/users/" + aliceID},
+		{http.MethodDelete, "/api/v1/admin/users/" + aliceID},
+	}
+	for _, route := range forbidden {
+		if code, _ := call(t, server, route.method, route.path, alice, gin.H{"role": "admin"}); code != http.StatusForbidden {
+			t.Errorf("%s %s as a member: got %d, want 403", route.method, route.path, code)
+		}
+	}
+
+	code, body := call(t, server, http.MethodGet, "/api/v1/admin/users", admin, nil)
+	if code != http.StatusOK || body["pagination"].(map[string]interface{})["total"] != float64(2) {
+		t.Fatalf("list users as admin: got %d %v", code, body)
+	}
+}
+
+func TestViewersAreReadOnly(t *testing.T) {
+	server := newTestServer(t)
+	bob, admin := register(t, server, "bob"), register(t, server, "admin")
+
+	code, body := call(t, server, http.MethodPost, "/api/v1/tasks", bob, gin.H{
+		"title": "Before", "description": "d", "priority": "low", "tags": []string{"a"},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("create as member: got %d %v", code, body)
+	}
+	task := "/api/v1/tasks/" + body["id"].(string)
+
+	code, body = call(t, server, http.MethodPatch, "/api/v1/admin/users/"+userID(t, server, bob), admin, gin.H{"role": "viewer"})
+	if code != http.StatusOK || body["user"].(map[string]interface{})["role"] != "viewer" {
+		t.Fatalf("demote to viewer: got %d %v", code, body)
+	}
+
+	// The role is loaded on every request, so bob's existing token is read-only at once
+	if code, _ := call(t, server, http.MethodGet, task, bob, nil); code != http.StatusOK {
+		t.Errorf("viewer reads own task: got %d, want 200", code)
+	}
+	writes := []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/tasks"},
+		{http.MethodPatch, task},
+		{http.MethodDelete, task},
+		{http.MethodPost, "/api/v1/projects"},
+	}
+	for _, route := range writes {
+		if code, _ := call(t, server, route.method, route.path, bob, gin.H{"title": "After"}); code != http.StatusForbidden {
+			t.Errorf("%s %s as a viewer: got %d, want 403", route.method, route.path, code)
+		}
+	}
+}
+
+func TestAdminUserManagement(t *testing.T) {
+	server := newTestServer(t)
+	alice, admin := register(t, server, "alice"), register(t, server, "admin")
+	aliceID, adminID := userID(t, server, alice), userID(t, server, admin)
+
+	if code, _ := call(t, server, http.MethodPatch, "/api/v1/admin/users/"+adminID, admin, gin.H{"role": "member"}); code != http.StatusBadRequest {
+		t.Errorf("admin demotes themselves: got %d, want 400", code)
+	}
+	if code, _ := call(t, server, http.MethodDelete, "/api/v1/admin/users/"+adminID, admin, nil); code != http.StatusBadRequest {
+		t.Errorf("admin deletes themselves: got %d, want 400", code)
+	}
+	if code, _ := call(t, server, http.MethodPatch, "/api/v1/admin/users/"+aliceID, admin, gin.H{"role": "owner"}); code != http.StatusBadRequest {
+		t.Errorf("unknown role: got %d, want 400", code)
+	}
+
+	code, body := call(t, server, http.MethodPatch, "/api/v1/admin/users/"+aliceID, admin, gin.H{"role": "admin"})
+	if code != http.StatusOK {
+		t.Fatalf("promote: got %d %v", code, body)
+	}
+	if code, _ := call(t, server, http.MethodGet, "/api/v1/admin/users", alice, nil); code != http.StatusOK {
+		t.Errorf("promoted user lists users: got %d, want 200", code)
+	}
+	if code, _ := call(t, server, http.MethodPatch, "/api/v1/admin/users/"+aliceID, admin, gin.H{"role": "member"}); code != http.StatusOK {
+		t.Fatalf("demote: got %d", code)
+	}
+
+	if code, body := call(t, server, http.MethodPost, "/api/v1/tasks", alice, gin.H{
+		"title": "Mine", "description": "d", "priority": "low", "tags": []string{"a"},
+	}); code != http.StatusCreated {
+		t.Fatalf("create: got %d %v", code, body)
+	}
+	code, body = call(t, server, http.MethodDelete, "/api/v1/admin/users/"+aliceID, admin, nil)
+	if code != http.StatusOK || body["deleted_tasks"] != float64(1) {
+		t.Fatalf("delete user: got %d %v", code, body)
+	}
+	if code, _ := call(t, server, http.MethodGet, "/api/v1/tasks", alice, nil); code != http.StatusUnauthorized {
+		t.Errorf("deleted user's token: got %d, want 401", code)
+	}
+	if code, _ := call(t, server, http.MethodDelete, "/api/v1/admin/users/"+aliceID, admin, nil); code != http.StatusNotFound {
+		t.Errorf("delete a missing user: got %d, want 404", code)
+	}
+}
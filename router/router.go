@@ -8,10 +8,23 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/blob"
+	"github.com/joshua-takyi/todo/cursor"
+	"github.com/joshua-takyi/todo/field"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/project"
+	"github.com/joshua-takyi/todo/store"
 	"github.com/joshua-takyi/todo/task"
+	"github.com/joshua-takyi/todo/user"
+	"github.com/joshua-takyi/todo/workflow"
 )
 
-func Router() *gin.Engine {
+// Router builds the gin engine and wires every route to its handler
+// The stores are passed in so the router doesn't care which database is behind them
+// flow is the status workflow tasks follow (see workflow.FromEnv)
+// blobs keeps the files uploaded as task attachments (see blob.FromEnv)
+func Router(stores store.Stores, tokens *auth.TokenIssuer, flow workflow.Workflow, blobs blob.Store, cursors *cursor.Signer) *gin.Engine {
 	router := gin.Default()
 
 	if err := godotenv.Load("../.env.local"); err != nil {
@@ -41,9 +54,50 @@ func Router() *gin.Engine {
 			"message": "Todo API is running",
 			"version": "1.0",
 			"endpoints": []string{
+				"/api/v1/auth/register - POST",
+				"/api/v1/auth/login - POST",
+				"/api/v1/auth/refresh - POST",
+				"/api/v1/auth/logout - POST",
+				"/api/v1/auth/me - GET",
+				"/api/v1/keys - GET, POST",
+				"/api/v1/keys/:id - DELETE",
 				"/api/v1/tasks - GET, POST",
+				"/api/v1/tasks/search - GET",
 				"/api/v1/tasks/:id - GET, PATCH, DELETE",
 				"/api/v1/tasks/:id/complete - PATCH",
+				"/api/v1/tasks/:id/transitions - GET, POST",
+				"/api/v1/workflow - GET",
+				"/api/v1/board - GET",
+				"/api/v1/tasks/:id/move - POST",
+				"/api/v1/tasks/:id/occurrences - GET",
+				"/api/v1/tasks/:id/children - GET",
+				"/api/v1/tasks/:id/ancestors - GET",
+				"/api/v1/tasks/:id/dependencies - GET, POST",
+				"/api/v1/tasks/:id/dependencies/:blocker_id - DELETE",
+				"/api/v1/tasks/:id/attachments - GET, POST",
+				"/api/v1/tasks/:id/attachments/:attachment_id - GET, DELETE",
+				"/api/v1/tasks/:id/attachments/:attachment_id/thumbnail - GET",
+				"/api/v1/tasks/:id/checklist - GET, POST",
+				"/api/v1/tasks/:id/checklist/reorder - POST",
+				"/api/v1/tasks/:id/checklist/:item_id - PATCH, DELETE",
+				"/api/v1/tasks/:id/comments - GET, POST",
+				"/api/v1/tasks/:id/comments/:comment_id - PATCH, DELETE",
+				"/api/v1/tasks/:id/comments/:comment_id/history - GET",
+				"/api/v1/tasks/:id/timer/start - POST",
+				"/api/v1/timer - GET",
+				"/api/v1/timer/stop - POST",
+				"/api/v1/tasks/:id/time - GET, POST",
+				"/api/v1/tasks/:id/time/:entry_id - DELETE",
+				"/api/v1/time/report - GET",
+				"/api/v1/estimates - GET",
+				"/api/v1/projects - GET, POST",
+				"/api/v1/projects/:id - GET, PATCH, DELETE",
+				"/api/v1/fields - GET, POST",
+				"/api/v1/fields/:id - GET, PATCH, DELETE",
+				"/api/v1/admin/tasks - GET",
+				"/api/v1/admin/tasks/:id - DELETE (force: subtasks are deleted too unless ?mode= says otherwise)",
+				"/api/v1/admin/users - GET",
+				"/api/v1/admin/users/:id - PATCH, DELETE",
 			},
 		})
 	})
@@ -55,15 +109,108 @@ func Router() *gin.Engine {
 		})
 	})
 
+	// Create the handlers with the injected stores
+	tasks := task.NewHandler(stores, flow, blobs, cursors)
+	accounts := auth.NewHandler(stores, tokens)
+	allTasks := task.NewAdminHandler(stores, flow, blobs, cursors)
+	users := user.NewHandler(stores, blobs)
+	projects := project.NewHandler(stores)
+	fields := field.NewHandler(stores)
+
 	// Define the routes for the task management API under /api/v1 prefix
 	v1 := router.Group("/api/v1")
+
+	// Auth routes are public: you can't have a token before logging in
+	{
+		v1.POST("/auth/register", accounts.Register) // Create a user account
+		v1.POST("/auth/login", accounts.Login)       // Exchange email and password for tokens
+		v1.POST("/auth/refresh", accounts.Refresh)   // Rotate a refresh token into new tokens
+		v1.POST("/auth/logout", accounts.Logout)     // Revoke a refresh token and its family
+	}
+
+	// Everything else under /api/v1 requires a valid access token or API key
+	// Viewers are authenticated too, but may only use read-only methods
+	protected := v1.Group("")
+	protected.Use(auth.Middleware(tokens, stores.Users, stores.APIKeys), viewersReadOnly())
 	{
-		v1.POST("/tasks", task.CreateTask)                   // Create a new task
-		v1.GET("/tasks", task.GetTask)                       // Retrieve all tasks
-		v1.GET("/tasks/:id", task.GetById)                   // Retrieve a specific task by ID
-		v1.PATCH("/tasks/:id", task.PatchTask)               // Update a specific task by ID
-		v1.DELETE("/tasks/:id", task.DeleteTask)             // Delete a specific task by ID
-		v1.PATCH("/tasks/:id/complete", task.MarkAsComplete) // Mark a task as complete
+		protected.GET("/auth/me", accounts.Me) // Return the authenticated user
+
+		protected.POST("/keys", accounts.CreateAPIKey)       // Create a personal API key
+		protected.GET("/keys", accounts.ListAPIKeys)         // List your API keys
+		protected.DELETE("/keys/:id", accounts.RevokeAPIKey) // Revoke an API key
+
+		protected.POST("/tasks", tasks.CreateTask)                   // Create a new task
+		protected.GET("/tasks", tasks.GetTask)                       // Retrieve all tasks
+		protected.GET("/tasks/search", tasks.SearchTasks)            // Full-text search in titles, descriptions and comments
+		protected.GET("/tasks/:id", tasks.GetById)                   // Retrieve a specific task by ID
+		protected.PATCH("/tasks/:id", tasks.PatchTask)               // Update a specific task by ID
+		protected.DELETE("/tasks/:id", tasks.DeleteTask)             // Delete a specific task by ID
+		protected.PATCH("/tasks/:id/complete", tasks.MarkAsComplete) // Mark a task as complete
+		protected.GET("/tasks/:id/occurrences", tasks.Occurrences)   // Preview the next dates of a recurring task
+		protected.GET("/tasks/:id/children", tasks.Children)         // List the direct subtasks of a task
+		protected.GET("/tasks/:id/ancestors", tasks.Ancestors)       // Path from the top-level task to the parent
+
+		protected.GET("/workflow", tasks.GetWorkflow)                  // Describe the statuses and allowed transitions
+		protected.GET("/tasks/:id/transitions", tasks.ListTransitions) // List the statuses a task may move to
+		protected.POST("/tasks/:id/transitions", tasks.Transition)     // Move a task to another status
+
+		protected.GET("/estimates", tasks.GetEstimates) // Estimates against tracked time, with weekly velocity
+
+		protected.GET("/board", tasks.GetBoard)              // Tasks grouped into columns in board order (?group_by=)
+		protected.POST("/tasks/:id/move", tasks.MoveOnBoard) // Reorder a task or move it to another column
+
+		protected.GET("/tasks/:id/dependencies", tasks.ListDependencies)                // List the tasks blocking a task
+		protected.POST("/tasks/:id/dependencies", tasks.AddDependency)                  // Add a "blocker blocks task" edge
+		protected.DELETE("/tasks/:id/dependencies/:blocker_id", tasks.RemoveDependency) // Remove an edge
+
+		protected.POST("/tasks/:id/attachments", tasks.UploadAttachment)                          // Upload a file (multipart field "file")
+		protected.GET("/tasks/:id/attachments", tasks.ListAttachments)                            // List the task's files
+		protected.GET("/tasks/:id/attachments/:attachment_id", tasks.DownloadAttachment)          // Download a file
+		protected.GET("/tasks/:id/attachments/:attachment_id/thumbnail", tasks.DownloadThumbnail) // Download a file's thumbnail
+		protected.DELETE("/tasks/:id/attachments/:attachment_id", tasks.DeleteAttachment)         // Delete a file
+
+		protected.GET("/tasks/:id/checklist", tasks.ListChecklist)                   // The task's checklist with its progress
+		protected.POST("/tasks/:id/checklist", tasks.AddChecklistItem)               // Add an item (at the end or at a position)
+		protected.POST("/tasks/:id/checklist/reorder", tasks.ReorderChecklist)       // Put the items in a new order
+		protected.PATCH("/tasks/:id/checklist/:item_id", tasks.UpdateChecklistItem)  // Rename, check or uncheck an item
+		protected.DELETE("/tasks/:id/checklist/:item_id", tasks.RemoveChecklistItem) // Remove an item
+
+		protected.GET("/tasks/:id/comments", tasks.ListComments)                       // List the task's comments, oldest first
+		protected.POST("/tasks/:id/comments", tasks.AddComment)                        // Comment on a task (Markdown body)
+		protected.PATCH("/tasks/:id/comments/:comment_id", tasks.EditComment)          // Edit your comment, keeping the old body
+		protected.DELETE("/tasks/:id/comments/:comment_id", tasks.DeleteComment)       // Delete your comment, leaving a placeholder
+		protected.GET("/tasks/:id/comments/:comment_id/history", tasks.CommentHistory) // Every body the comment has had
+
+		protected.POST("/tasks/:id/timer/start", tasks.StartTimer)           // Start your timer on a task (one at a time)
+		protected.POST("/timer/stop", tasks.StopTimer)                       // Stop your running timer
+		protected.GET("/timer", tasks.CurrentTimer)                          // Your running timer, if any
+		protected.POST("/tasks/:id/time", tasks.LogTime)                     // Log time by hand (duration, started_at, note)
+		protected.GET("/tasks/:id/time", tasks.ListTimeEntries)              // The task's time entries with its total
+		protected.DELETE("/tasks/:id/time/:entry_id", tasks.DeleteTimeEntry) // Delete a time entry
+		protected.GET("/time/report", tasks.TimeReport)                      // Time per task and per tag (?from=&to=&tag=)
+
+		protected.POST("/projects", projects.CreateProject)       // Create a project
+		protected.GET("/projects", projects.ListProjects)         // List your projects with their task counts
+		protected.GET("/projects/:id", projects.GetProject)       // Retrieve a project with its task counts
+		protected.PATCH("/projects/:id", projects.UpdateProject)  // Rename, recolor or (un)archive a project
+		protected.DELETE("/projects/:id", projects.DeleteProject) // Delete a project, keeping its tasks
+
+		protected.POST("/fields", fields.CreateField)       // Define a custom field for your tasks
+		protected.GET("/fields", fields.ListFields)         // List your custom field definitions
+		protected.GET("/fields/:id", fields.GetField)       // Retrieve a custom field definition
+		protected.PATCH("/fields/:id", fields.UpdateField)  // Rename a field, change its options or whether it is required
+		protected.DELETE("/fields/:id", fields.DeleteField) // Delete a field and its values on every task
+	}
+
+	// Admin routes reach every user's data, so only admins get past requireRole
+	admin := protected.Group("/admin", requireRole(model.RoleAdmin))
+	{
+		admin.GET("/tasks", allTasks.GetTask)                // List every task (?owner_id= narrows to one user)
+		admin.DELETE("/tasks/:id", allTasks.ForceDeleteTask) // Delete any user's task with its subtasks (?mode= as for owners)
+
+		admin.GET("/users", users.ListUsers)         // List every account
+		admin.PATCH("/users/:id", users.UpdateRole)  // Change a user's role
+		admin.DELETE("/users/:id", users.DeleteUser) // Delete a user and their tasks
 	}
 
 	return router
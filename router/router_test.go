@@ -0,0 +1,161 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/blob"
+	"github.com/joshua-takyi/todo/cursor"
+	"github.com/joshua-takyi/todo/store"
+	"github.com/joshua-takyi/todo/workflow"
+)
+
+// newTestServer serves the whole API on the memory backend, with no external service involved
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("ADMIN_EMAILS", "admin@example.com")
+
+	tokens, err := auth.NewTokenIssuerFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(Router(store.OpenMemory(), tokens, workflow.Default(), blobs, cursor.New([]byte("test"))))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// call sends a JSON request and decodes the JSON response, if any
+func call(t *testing.T, server *httptest.Server, method, path, token string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var raw []byte
+	if body != nil {
+		var err error
+		if raw, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var decoded map[string]interface{}
+	if res.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(res.Body).Decode(&decoded); err != nil {
+			t.Fatalf("%s %s: response is not JSON: %v", method, path, err)
+		}
+	}
+	return res.StatusCode, decoded
+}
+
+// register creates an account and returns its access token
+func register(t *testing.T, server *httptest.Server, name string) string {
+	t.Helper()
+	code, body := call(t, server, http.MethodPost, "/api/v1/auth/register", "", gin.H{
+		"name": name, "email": name + "@example.com", "password": "password1",
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("register %s: got %d %v", name, code, body)
+	}
+	return body["token"].(string)
+}
+
+func TestTaskLifecycleOnMemoryBackend(t *testing.T) {
+	server := newTestServer(t)
+	alice, bob := register(t, server, "alice"), register(t, server, "bob")
+
+	if code, _ := call(t, server, http.MethodGet, "/api/v1/tasks", "", nil); code != http.StatusUnauthorized {
+		t.Fatalf("anonymous list: got %d, want 401", code)
+	}
+
+	code, body := call(t, server, http.MethodPost, "/api/v1/tasks", alice, gin.H{
+		"title": "Ship it", "description": "d", "priority": "high", "tags": []string{"release"},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("create: got %d %v", code, body)
+	}
+	id := body["id"].(string)
+	task := "/api/v1/tasks/" + id
+
+	code, body = call(t, server, http.MethodGet, "/api/v1/tasks", alice, nil)
+	if code != http.StatusOK || body["pagination"].(map[string]interface{})["total"] != float64(1) {
+		t.Fatalf("list: got %d %v", code, body)
+	}
+
+	// Tasks are private: another user can neither see nor change them
+	if code, _ := call(t, server, http.MethodGet, task, bob, nil); code != http.StatusNotFound {
+		t.Errorf("bob reads alice's task: got %d, want 404", code)
+	}
+	if code, _ := call(t, server, http.MethodDelete, task, bob, nil); code != http.StatusNotFound {
+		t.Errorf("bob deletes alice's task: got %d, want 404", code)
+	}
+
+	code, body = call(t, server, http.MethodPatch, task+"/complete", alice, nil)
+	if code != http.StatusOK || body["completed"] != true {
+		t.Fatalf("complete: got %d %v", code, body)
+	}
+	code, body = call(t, server, http.MethodPatch, task, alice, gin.H{"title": "Shipped"})
+	if code != http.StatusOK || body["task"].(map[string]interface{})["title"] != "Shipped" {
+		t.Fatalf("patch: got %d %v", code, body)
+	}
+
+	if code, body := call(t, server, http.MethodDelete, task, alice, nil); code != http.StatusNoContent {
+		t.Fatalf("delete: got %d %v", code, body)
+	}
+	if code, _ := call(t, server, http.MethodGet, task, alice, nil); code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d, want 404", code)
+	}
+}
+
+func TestAdminDeleteTakesSubtasks(t *testing.T) {
+	server := newTestServer(t)
+	alice, admin := register(t, server, "alice"), register(t, server, "admin")
+
+	create := func(body gin.H) string {
+		body["description"], body["priority"], body["tags"] = "d", "low", []string{"a"}
+		code, res := call(t, server, http.MethodPost, "/api/v1/tasks", alice, body)
+		if code != http.StatusCreated {
+			t.Fatalf("create: got %d %v", code, res)
+		}
+		return res["id"].(string)
+	}
+	parent := create(gin.H{"title": "parent"})
+	create(gin.H{"title": "child", "parent_id": parent})
+
+	if code, _ := call(t, server, http.MethodDelete, "/api/v1/tasks/"+parent, alice, nil); code != http.StatusConflict {
+		t.Errorf("owner delete with subtasks: got %d, want 409", code)
+	}
+	if code, _ := call(t, server, http.MethodDelete, "/api/v1/admin/tasks/"+parent, alice, nil); code != http.StatusForbidden {
+		t.Errorf("admin route as a member: got %d, want 403", code)
+	}
+	if code, body := call(t, server, http.MethodDelete, "/api/v1/admin/tasks/"+parent, admin, nil); code != http.StatusNoContent {
+		t.Fatalf("admin delete: got %d %v", code, body)
+	}
+
+	_, body := call(t, server, http.MethodGet, "/api/v1/tasks", alice, nil)
+	if total := body["pagination"].(map[string]interface{})["total"]; total != float64(0) {
+		t.Errorf("%v tasks left, want the subtask deleted too", total)
+	}
+}
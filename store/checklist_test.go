@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReorderChecklistNeedsEveryItemOnce(t *testing.T) {
+	ctx := context.Background()
+	for backend, stores := range testStores(t) {
+		owner := primitive.NewObjectID()
+		createOwner(t, stores, owner)
+		scope := OwnedBy(owner)
+		task := createTask(t, stores, owner, "with a checklist")
+
+		var a, b, c primitive.ObjectID
+		for _, id := range []*primitive.ObjectID{&a, &b, &c} {
+			*id = primitive.NewObjectID()
+			item := model.ChecklistItem{ID: *id, Text: "step", CreatedAt: time.Now()}
+			if _, err := stores.Tasks.AddChecklistItem(ctx, scope, task.ID, item, 100); err != nil {
+				t.Fatal(err)
+			}
+		}
+		order := func(t *testing.T) []primitive.ObjectID {
+			t.Helper()
+			stored, err := stores.Tasks.Get(ctx, scope, task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []primitive.ObjectID
+			for _, item := range stored.Checklist {
+				ids = append(ids, item.ID)
+			}
+			return ids
+		}
+
+		rejected := map[string][]primitive.ObjectID{
+			"repeated item in place of a missing one": {a, a, b},
+			"repeated item on top of all of them":     {a, b, c, a},
+			"missing item":                            {a, b},
+			"unknown item":                            {a, b, primitive.NewObjectID()},
+			"empty":                                   {},
+		}
+		for name, itemIDs := range rejected {
+			t.Run(backend+"/"+name, func(t *testing.T) {
+				if _, err := stores.Tasks.ReorderChecklist(ctx, scope, task.ID, itemIDs); !errors.Is(err, ErrChecklistMismatch) {
+					t.Fatalf("got %v, want ErrChecklistMismatch", err)
+				}
+				if got := order(t); !slices.Equal(got, []primitive.ObjectID{a, b, c}) {
+					t.Errorf("checklist changed to %v", got)
+				}
+			})
+		}
+
+		t.Run(backend+"/every item once", func(t *testing.T) {
+			if _, err := stores.Tasks.ReorderChecklist(ctx, scope, task.ID, []primitive.ObjectID{c, a, b}); err != nil {
+				t.Fatal(err)
+			}
+			if got := order(t); !slices.Equal(got, []primitive.ObjectID{c, a, b}) {
+				t.Errorf("order = %v, want c, a, b", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAddDependencyRejectsCycles(t *testing.T) {
+	ctx := context.Background()
+	for backend, stores := range testStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			owner := primitive.NewObjectID()
+			createOwner(t, stores, owner)
+			scope := OwnedBy(owner)
+			a, b, c := createTask(t, stores, owner, "a"), createTask(t, stores, owner, "b"), createTask(t, stores, owner, "c")
+
+			// a blocks b blocks c
+			if _, err := stores.Tasks.AddDependency(ctx, scope, b.ID, a.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := stores.Tasks.AddDependency(ctx, scope, c.ID, b.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := stores.Tasks.AddDependency(ctx, scope, c.ID, b.ID); err != nil {
+				t.Errorf("adding an existing edge again: %v", err)
+			}
+
+			for name, edge := range map[string][2]primitive.ObjectID{
+				"self":     {a.ID, a.ID},
+				"direct":   {a.ID, b.ID},
+				"indirect": {a.ID, c.ID},
+			} {
+				if _, err := stores.Tasks.AddDependency(ctx, scope, edge[0], edge[1]); !errors.Is(err, ErrDependencyCycle) {
+					t.Errorf("%s cycle: got %v, want ErrDependencyCycle", name, err)
+				}
+			}
+			stored, err := stores.Tasks.Get(ctx, scope, a.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stored.BlockedBy) != 0 {
+				t.Errorf("a is blocked by %v after rejected edges", stored.BlockedBy)
+			}
+		})
+	}
+}
+
+func TestConcurrentOppositeDependencies(t *testing.T) {
+	ctx := context.Background()
+	for backend, stores := range testStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			owner := primitive.NewObjectID()
+			createOwner(t, stores, owner)
+			scope := OwnedBy(owner)
+
+			for round := 0; round < 20; round++ {
+				a, b := createTask(t, stores, owner, "a"), createTask(t, stores, owner, "b")
+
+				// "a blocks b" and "b blocks a" race; whatever the timing, at most one may stick
+				var wg sync.WaitGroup
+				errs := make([]error, 2)
+				for i, edge := range [][2]model.Task{{b, a}, {a, b}} {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, errs[i] = stores.Tasks.AddDependency(ctx, scope, edge[0].ID, edge[1].ID)
+					}()
+				}
+				wg.Wait()
+
+				added := 0
+				for _, err := range errs {
+					switch {
+					case err == nil:
+						added++
+					case !errors.Is(err, ErrDependencyCycle):
+						t.Fatalf("round %d: %v", round, err)
+					}
+				}
+				if added > 1 {
+					t.Fatalf("round %d: both opposite edges were added", round)
+				}
+
+				tasks, err := stores.Tasks.GetMany(ctx, scope, []primitive.ObjectID{a.ID, b.ID})
+				if err != nil {
+					t.Fatal(err)
+				}
+				edges := 0
+				for _, task := range tasks {
+					edges += len(task.BlockedBy)
+				}
+				if edges != added {
+					t.Fatalf("round %d: %d edges stored, %d reported added", round, edges, added)
+				}
+			}
+		})
+	}
+}
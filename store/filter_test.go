@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestListFiltersOnEveryBackend(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := base.AddDate(0, 0, days)
+		return &t
+	}
+	yes, no := true, false
+	inProgress := model.StatusInProgress
+
+	for backend, stores := range testStores(t) {
+		owner := primitive.NewObjectID()
+		createOwner(t, stores, owner)
+
+		rows := []struct {
+			title    string
+			tags     []string
+			priority model.Priority
+			status   model.Status
+			created  int
+		}{
+			{"Fix (a.b) parser", []string{"api", "bug"}, model.PriorityHigh, model.StatusTodo, 0},
+			{"Write docs", []string{"docs"}, model.PriorityLow, model.StatusDone, 1},
+			{"Fix login", []string{"bug"}, model.PriorityMedium, model.StatusInProgress, 2},
+			{"a.b.c notes", []string{"api"}, model.PriorityLow, model.StatusCancelled, 3},
+		}
+		for _, row := range rows {
+			task := model.Task{
+				ID: primitive.NewObjectID(), OwnerID: owner, Title: row.title, Description: "d", Priority: row.priority,
+				Tags: row.tags, Image: []string{}, Status: row.status, Completed: row.status.IsTerminal(),
+				Metadata: model.Metadata{CreatedAt: *at(row.created), UpdatedAt: *at(row.created)},
+			}
+			if err := stores.Tasks.Create(ctx, &task); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tests := []struct {
+			name   string
+			filter TaskFilter
+			want   string
+		}{
+			{"any tag", TaskFilter{Tags: []string{"docs", "bug"}}, "Fix (a.b) parser,Fix login,Write docs"},
+			{"all tags", TaskFilter{Tags: []string{"api", "bug"}, AllTags: true}, "Fix (a.b) parser"},
+			{"priorities", TaskFilter{Priorities: []model.Priority{model.PriorityHigh, model.PriorityMedium}}, "Fix (a.b) parser,Fix login"},
+			{"completed", TaskFilter{Completed: &yes}, "Write docs,a.b.c notes"},
+			{"open", TaskFilter{Completed: &no}, "Fix (a.b) parser,Fix login"},
+			{"status", TaskFilter{Status: &inProgress}, "Fix login"},
+			// The title is plain text, never a pattern, and matched ignoring case
+			{"title", TaskFilter{Title: "FIX"}, "Fix (a.b) parser,Fix login"},
+			{"title with pattern characters", TaskFilter{Title: "(A.B)"}, "Fix (a.b) parser"},
+			{"title with a dot", TaskFilter{Title: "a.b"}, "Fix (a.b) parser,a.b.c notes"},
+			{"title with a percent sign", TaskFilter{Title: "%"}, ""},
+			{"created range", TaskFilter{CreatedAfter: at(0), CreatedBefore: at(3)}, "Fix login,Write docs"},
+			{"updated range", TaskFilter{UpdatedAfter: at(2)}, "a.b.c notes"},
+			{"combined", TaskFilter{Tags: []string{"api"}, Completed: &no, Title: "parser"}, "Fix (a.b) parser"},
+		}
+		for _, tt := range tests {
+			// A one-task page still counts every match, so pagination totals stay right
+			page, total, err := stores.Tasks.List(ctx, OwnedBy(owner), ListOptions{Page: 1, Limit: 1, Filter: tt.filter})
+			if err != nil {
+				t.Fatalf("%s: %s: %v", backend, tt.name, err)
+			}
+			all, _, err := stores.Tasks.List(ctx, OwnedBy(owner), ListOptions{Page: 1, Limit: 10, Filter: tt.filter})
+			if err != nil {
+				t.Fatalf("%s: %s: %v", backend, tt.name, err)
+			}
+
+			var got []string
+			for _, task := range all {
+				got = append(got, task.Title)
+			}
+			slices.Sort(got)
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("%s: %s = [%s], want [%s]", backend, tt.name, strings.Join(got, ","), tt.want)
+			}
+			if total != int64(len(all)) || len(page) > 1 {
+				t.Errorf("%s: %s: total %d with a page of %d, want %d matches", backend, tt.name, total, len(page), len(all))
+			}
+		}
+	}
+}
@@ -0,0 +1,696 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryTaskStore keeps tasks in a map guarded by a mutex
+// Nothing survives a restart, which makes it handy for CI and local development
+// where no MongoDB server is available
+type MemoryTaskStore struct {
+	mu    sync.RWMutex // RWMutex lets many readers in at once but only one writer
+	tasks map[primitive.ObjectID]model.Task
+}
+
+// OpenMemory returns a fresh set of in-memory stores
+func OpenMemory() Stores {
+	return Stores{
+		Tasks:         NewMemoryTaskStore(),
+		Users:         NewMemoryUserStore(),
+		RefreshTokens: NewMemoryRefreshTokenStore(),
+		APIKeys:       NewMemoryAPIKeyStore(),
+		Projects:      NewMemoryProjectStore(),
+		Comments:      NewMemoryCommentStore(),
+		TimeEntries:   NewMemoryTimeEntryStore(),
+		CustomFields:  NewMemoryCustomFieldStore(),
+		Close:         func() error { return nil }, // Nothing to release
+	}
+}
+
+// NewMemoryTaskStore returns an empty in-memory store
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{
+		tasks: make(map[primitive.ObjectID]model.Task),
+	}
+}
+
+// Create stores a copy of the task
+func (s *MemoryTaskStore) Create(ctx context.Context, task *model.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks[task.ID] = cloneTask(*task)
+	return nil
+}
+
+// Get returns a copy of the stored task so callers can't mutate our state
+func (s *MemoryTaskStore) Get(ctx context.Context, scope Scope, id primitive.ObjectID) (model.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+	return cloneTask(task), nil
+}
+
+// List filters and sorts the caller's tasks and then cuts out the requested page
+func (s *MemoryTaskStore) List(ctx context.Context, scope Scope, opts ListOptions) ([]model.Task, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make([]model.Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if scope.Allows(task) && opts.Filter.Matches(task) {
+			all = append(all, task)
+		}
+	}
+
+	// Map iteration order is random in Go, so we always sort before paginating
+	// The ID is used as a tie breaker to keep the order stable between calls
+	keys := opts.Sort.OrDefault()
+	sort.Slice(all, func(i, j int) bool {
+		return comparePositions(keys, PositionOf(keys, all[i]), PositionOf(keys, all[j])) < 0
+	})
+
+	total := int64(len(all))
+	var page []model.Task
+	if opts.From != nil {
+		page = pageFrom(keys, all, *opts.From, opts.Limit)
+	} else {
+		page = paginate(all, opts)
+	}
+
+	tasks := make([]model.Task, 0, len(page))
+	for _, task := range page {
+		tasks = append(tasks, cloneTask(task))
+	}
+	return tasks, total, nil
+}
+
+// Update applies the non-nil fields of the TaskUpdate
+func (s *MemoryTaskStore) Update(ctx context.Context, scope Scope, id primitive.ObjectID, update TaskUpdate) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+	if update.Transition != nil && task.EffectiveStatus() != update.Transition.From {
+		return model.Task{}, ErrStatusConflict
+	}
+
+	update.Apply(&task)
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = cloneTask(task)
+	return cloneTask(task), nil
+}
+
+// Delete removes the task from the map
+func (s *MemoryTaskStore) Delete(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return ErrNotFound
+	}
+	delete(s.tasks, id)
+	s.dropDependencies(id)
+	return nil
+}
+
+// AssignOwnerless gives every task without an owner to ownerID
+func (s *MemoryTaskStore) AssignOwnerless(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed int64
+	for id, task := range s.tasks {
+		if task.OwnerID.IsZero() {
+			task.OwnerID = ownerID
+			s.tasks[id] = task
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// DeleteByOwner removes every task that belongs to ownerID
+func (s *MemoryTaskStore) DeleteByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, task := range s.tasks {
+		if task.OwnerID == ownerID {
+			delete(s.tasks, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ListChildren returns the direct subtasks of parentID, oldest first
+func (s *MemoryTaskStore) ListChildren(ctx context.Context, scope Scope, parentID primitive.ObjectID) ([]model.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	children := []model.Task{}
+	for _, task := range s.tasks {
+		if scope.Allows(task) && task.ParentID != nil && *task.ParentID == parentID {
+			children = append(children, cloneTask(task))
+		}
+	}
+	sort.Slice(children, func(i, j int) bool {
+		a, b := children[i].Metadata.CreatedAt, children[j].Metadata.CreatedAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return children[i].ID.Hex() < children[j].ID.Hex()
+	})
+	return children, nil
+}
+
+// DetachChildren clears the parent of every direct subtask of parentID
+func (s *MemoryTaskStore) DetachChildren(ctx context.Context, scope Scope, parentID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed int64
+	for id, task := range s.tasks {
+		if scope.Allows(task) && task.ParentID != nil && *task.ParentID == parentID {
+			task.ParentID = nil
+			task.Metadata.UpdatedAt = time.Now()
+			s.tasks[id] = task
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// DeleteTree removes the task and everything below it while holding the write lock
+func (s *MemoryTaskStore) DeleteTree(ctx context.Context, scope Scope, id primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	root, ok := s.tasks[id]
+	if !ok || !scope.Allows(root) {
+		return 0, ErrNotFound
+	}
+
+	// Walk the tree level by level (breadth first) collecting every subtask
+	doomed := []primitive.ObjectID{id}
+	for i := 0; i < len(doomed); i++ {
+		for childID, task := range s.tasks {
+			if task.ParentID != nil && *task.ParentID == doomed[i] {
+				doomed = append(doomed, childID)
+			}
+		}
+	}
+
+	for _, taskID := range doomed {
+		delete(s.tasks, taskID)
+	}
+	s.dropDependencies(doomed...)
+	return int64(len(doomed)), nil
+}
+
+// GetMany returns copies of the requested tasks that exist inside the scope
+func (s *MemoryTaskStore) GetMany(ctx context.Context, scope Scope, ids []primitive.ObjectID) ([]model.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := []model.Task{}
+	for _, id := range ids {
+		if task, ok := s.tasks[id]; ok && scope.Allows(task) {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	return tasks, nil
+}
+
+// AddDependency appends blockerID to the task's blockers unless it is already there
+// The cycle check runs under the same lock as the write
+func (s *MemoryTaskStore) AddDependency(ctx context.Context, scope Scope, id, blockerID primitive.ObjectID) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+
+	for _, existing := range task.BlockedBy {
+		if existing == blockerID {
+			return cloneTask(task), nil
+		}
+	}
+	cycle, err := blocksTransitively(ctx, s.lockedLoad, id, blockerID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if cycle {
+		return model.Task{}, ErrDependencyCycle
+	}
+	task.BlockedBy = append(task.BlockedBy, blockerID)
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = cloneTask(task)
+	return cloneTask(task), nil
+}
+
+// lockedLoad returns the tasks with the given IDs for a caller already holding the lock
+// The tasks aren't copied, so they must only be read
+func (s *MemoryTaskStore) lockedLoad(ctx context.Context, ids []primitive.ObjectID) ([]model.Task, error) {
+	tasks := make([]model.Task, 0, len(ids))
+	for _, id := range ids {
+		if task, ok := s.tasks[id]; ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// RemoveDependency takes blockerID out of the task's blockers
+func (s *MemoryTaskStore) RemoveDependency(ctx context.Context, scope Scope, id, blockerID primitive.ObjectID) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+
+	kept := make([]primitive.ObjectID, 0, len(task.BlockedBy))
+	for _, existing := range task.BlockedBy {
+		if existing != blockerID {
+			kept = append(kept, existing)
+		}
+	}
+	if len(kept) == len(task.BlockedBy) {
+		return model.Task{}, ErrDependencyNotFound
+	}
+	task.BlockedBy = kept
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = task
+	return cloneTask(task), nil
+}
+
+// AddAttachment appends the attachment to the task's list
+func (s *MemoryTaskStore) AddAttachment(ctx context.Context, scope Scope, id primitive.ObjectID, attachment model.Attachment) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+	task.Attachments = append(append([]model.Attachment(nil), task.Attachments...), attachment)
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = task
+	return cloneTask(task), nil
+}
+
+// RemoveAttachment takes the attachment out of the task's list
+func (s *MemoryTaskStore) RemoveAttachment(ctx context.Context, scope Scope, id, attachmentID primitive.ObjectID) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+
+	kept := make([]model.Attachment, 0, len(task.Attachments))
+	for _, existing := range task.Attachments {
+		if existing.ID != attachmentID {
+			kept = append(kept, existing)
+		}
+	}
+	if len(kept) == len(task.Attachments) {
+		return model.Task{}, ErrAttachmentNotFound
+	}
+	task.Attachments = kept
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = task
+	return cloneTask(task), nil
+}
+
+// AddChecklistItem inserts the item into a copy of the checklist, so readers never see a half-changed list
+func (s *MemoryTaskStore) AddChecklistItem(ctx context.Context, scope Scope, id primitive.ObjectID, item model.ChecklistItem, position int) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+	if position < 0 || position > len(task.Checklist) {
+		position = len(task.Checklist)
+	}
+
+	checklist := make([]model.ChecklistItem, 0, len(task.Checklist)+1)
+	checklist = append(checklist, task.Checklist[:position]...)
+	checklist = append(checklist, item)
+	checklist = append(checklist, task.Checklist[position:]...)
+	task.Checklist = checklist
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = task
+	return cloneTask(task), nil
+}
+
+// UpdateChecklistItem applies the update to one item of the checklist
+func (s *MemoryTaskStore) UpdateChecklistItem(ctx context.Context, scope Scope, id, itemID primitive.ObjectID, update ChecklistItemUpdate) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+
+	checklist := append([]model.ChecklistItem(nil), task.Checklist...)
+	found := false
+	for i := range checklist {
+		if checklist[i].ID == itemID {
+			update.Apply(&checklist[i], time.Now())
+			found = true
+			break
+		}
+	}
+	if !found {
+		return model.Task{}, ErrChecklistItemNotFound
+	}
+	task.Checklist = checklist
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = task
+	return cloneTask(task), nil
+}
+
+// RemoveChecklistItem takes the item out of the checklist
+func (s *MemoryTaskStore) RemoveChecklistItem(ctx context.Context, scope Scope, id, itemID primitive.ObjectID) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+
+	kept := make([]model.ChecklistItem, 0, len(task.Checklist))
+	for _, existing := range task.Checklist {
+		if existing.ID != itemID {
+			kept = append(kept, existing)
+		}
+	}
+	if len(kept) == len(task.Checklist) {
+		return model.Task{}, ErrChecklistItemNotFound
+	}
+	task.Checklist = kept
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = task
+	return cloneTask(task), nil
+}
+
+// ReorderChecklist rebuilds the checklist in the requested order
+func (s *MemoryTaskStore) ReorderChecklist(ctx context.Context, scope Scope, id primitive.ObjectID, itemIDs []primitive.ObjectID) (model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !scope.Allows(task) {
+		return model.Task{}, ErrNotFound
+	}
+	if len(itemIDs) != len(task.Checklist) {
+		return model.Task{}, ErrChecklistMismatch
+	}
+
+	items := make(map[primitive.ObjectID]model.ChecklistItem, len(task.Checklist))
+	for _, item := range task.Checklist {
+		items[item.ID] = item
+	}
+	checklist := make([]model.ChecklistItem, 0, len(itemIDs))
+	for _, itemID := range itemIDs {
+		item, ok := items[itemID]
+		if !ok {
+			return model.Task{}, ErrChecklistMismatch
+		}
+		delete(items, itemID) // A repeated ID then fails the lookup above
+		checklist = append(checklist, item)
+	}
+	task.Checklist = checklist
+	task.Metadata.UpdatedAt = time.Now()
+
+	s.tasks[id] = task
+	return cloneTask(task), nil
+}
+
+// CountByProject tallies the tasks of the requested projects in one pass over the map
+func (s *MemoryTaskStore) CountByProject(ctx context.Context, scope Scope, projectIDs []primitive.ObjectID) (map[primitive.ObjectID]model.ProjectCounts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wanted := make(map[primitive.ObjectID]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		wanted[id] = true
+	}
+
+	counts := make(map[primitive.ObjectID]model.ProjectCounts)
+	for _, task := range s.tasks {
+		if !scope.Allows(task) || task.ProjectID == nil || !wanted[*task.ProjectID] {
+			continue
+		}
+		count := counts[*task.ProjectID]
+		if task.Completed {
+			count.Completed++
+		} else {
+			count.Open++
+		}
+		counts[*task.ProjectID] = count
+	}
+	return counts, nil
+}
+
+// DetachProject clears the project of every task in projectID
+func (s *MemoryTaskStore) DetachProject(ctx context.Context, scope Scope, projectID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed int64
+	for id, task := range s.tasks {
+		if scope.Allows(task) && task.ProjectID != nil && *task.ProjectID == projectID {
+			task.ProjectID = nil
+			task.Metadata.UpdatedAt = time.Now()
+			s.tasks[id] = task
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// SearchTasks scores every task in the scope against the query
+func (s *MemoryTaskStore) SearchTasks(ctx context.Context, scope Scope, query TextQuery, limit int) ([]TextMatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []TextMatch
+	for _, task := range s.tasks {
+		if !scope.Allows(task) {
+			continue
+		}
+		score, ok := query.Score(WeightedText{task.Title, titleWeight}, WeightedText{task.Description, descriptionWeight})
+		if ok {
+			matches = append(matches, TextMatch{TaskID: task.ID, Score: score})
+		}
+	}
+	return bestTextMatches(matches, limit), nil
+}
+
+// ClearCustomField removes the value of the custom field from every task that has one
+func (s *MemoryTaskStore) ClearCustomField(ctx context.Context, scope Scope, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed int64
+	for id, task := range s.tasks {
+		if _, ok := task.Fields[key]; ok && scope.Allows(task) {
+			task.Fields = mergeFields(task.Fields, model.FieldValues{key: nil})
+			task.Metadata.UpdatedAt = time.Now()
+			s.tasks[id] = task
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// dropDependencies removes deleted tasks from the blockers of the remaining ones
+// The caller must hold the write lock
+func (s *MemoryTaskStore) dropDependencies(deleted ...primitive.ObjectID) {
+	gone := make(map[primitive.ObjectID]bool, len(deleted))
+	for _, id := range deleted {
+		gone[id] = true
+	}
+
+	for id, task := range s.tasks {
+		kept := task.BlockedBy[:0:0] // Fresh slice so the stored task isn't modified in place
+		for _, blockerID := range task.BlockedBy {
+			if !gone[blockerID] {
+				kept = append(kept, blockerID)
+			}
+		}
+		if len(kept) != len(task.BlockedBy) {
+			task.BlockedBy = kept
+			s.tasks[id] = task
+		}
+	}
+}
+
+// compareSortValues orders two SortKey values; a missing value sorts before any other
+func compareSortValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	cmp, _ := compareFieldValues(a, b)
+	return cmp
+}
+
+// comparePositions orders two positions in a list sorted by keys, then by ID newest first
+// It is negative when a comes first and only 0 for the same task
+func comparePositions(keys TaskSort, a, b Position) int {
+	for i, key := range keys {
+		if cmp := compareSortValues(a.Values[i], b.Values[i]); cmp != 0 {
+			if key.Desc {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return -strings.Compare(a.ID.Hex(), b.ID.Hex())
+}
+
+// pageFrom returns up to limit of the sorted tasks next to pos, the task at pos itself left out
+func pageFrom(keys TaskSort, tasks []model.Task, pos Position, limit int) []model.Task {
+	// The first task after pos; everything before it is at or before pos
+	after := sort.Search(len(tasks), func(i int) bool {
+		return comparePositions(keys, PositionOf(keys, tasks[i]), pos) > 0
+	})
+	if !pos.Before {
+		return tasks[after:min(after+limit, len(tasks))]
+	}
+	before := after
+	if before > 0 && tasks[before-1].ID == pos.ID {
+		before--
+	}
+	return tasks[max(before-limit, 0):before]
+}
+
+// paginate returns the slice of items that belongs to the requested page
+func paginate(tasks []model.Task, opts ListOptions) []model.Task {
+	start := opts.Skip()
+	if start >= len(tasks) {
+		return nil
+	}
+	end := start + opts.Limit
+	if end > len(tasks) {
+		end = len(tasks)
+	}
+	return tasks[start:end]
+}
+
+// cloneTask copies the slices, structs and pointers inside a task
+// A plain struct copy would still share the backing arrays of Tags and Image, and the values of the dates
+func cloneTask(task model.Task) model.Task {
+	task.Tags = cloneStrings(task.Tags)
+	task.Image = cloneStrings(task.Image)
+	if task.Recurrence != nil {
+		recurrence := *task.Recurrence
+		recurrence.NextID = clonePointer(recurrence.NextID)
+		task.Recurrence = &recurrence
+	}
+	if task.ParentID != nil {
+		parentID := *task.ParentID
+		task.ParentID = &parentID
+	}
+	if task.ProjectID != nil {
+		projectID := *task.ProjectID
+		task.ProjectID = &projectID
+	}
+	task.StartAt = clonePointer(task.StartAt)
+	task.DueAt = clonePointer(task.DueAt)
+	task.Points = clonePointer(task.Points)
+	task.Estimate = clonePointer(task.Estimate)
+	if task.StatusSince != nil {
+		since := make(model.StatusTimes, len(task.StatusSince))
+		for status, at := range task.StatusSince {
+			since[status] = at
+		}
+		task.StatusSince = since
+	}
+	if task.BlockedBy != nil {
+		task.BlockedBy = append([]primitive.ObjectID(nil), task.BlockedBy...)
+	}
+	if task.Attachments != nil {
+		task.Attachments = append([]model.Attachment(nil), task.Attachments...)
+	}
+	if task.Checklist != nil {
+		task.Checklist = append([]model.ChecklistItem(nil), task.Checklist...)
+		for i := range task.Checklist {
+			task.Checklist[i].DoneAt = clonePointer(task.Checklist[i].DoneAt)
+		}
+	}
+	task.Progress = clonePointer(task.Progress)
+	if task.Fields != nil {
+		task.Fields = mergeFields(task.Fields, nil)
+		for key, value := range task.Fields {
+			// Multi-select values are lists, which a plain map copy would still share
+			switch list := value.(type) {
+			case []string:
+				task.Fields[key] = cloneStrings(list)
+			case []interface{}:
+				task.Fields[key] = append([]interface{}(nil), list...)
+			}
+		}
+	}
+	return task
+}
+
+// clonePointer copies the value behind p, so the copy can't change the stored task
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	value := *p
+	return &value
+}
+
+// cloneStrings copies a slice, keeping nil and empty apart so every store serializes them the same way
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	out := make([]string, len(values))
+	copy(out, values)
+	return out
+}
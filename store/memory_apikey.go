@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryAPIKeyStore keeps API keys in process memory, keyed by ID
+type MemoryAPIKeyStore struct {
+	mu   sync.Mutex
+	keys map[primitive.ObjectID]model.APIKey
+}
+
+// NewMemoryAPIKeyStore returns an empty in-memory API key store
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{
+		keys: make(map[primitive.ObjectID]model.APIKey),
+	}
+}
+
+// CreateAPIKey stores the key
+func (s *MemoryAPIKeyStore) CreateAPIKey(ctx context.Context, key *model.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = *key
+	return nil
+}
+
+// ListAPIKeys returns the user's keys, newest first
+func (s *MemoryAPIKeyStore) ListAPIKeys(ctx context.Context, userID primitive.ObjectID) ([]model.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []model.APIKey{}
+	for _, key := range s.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// GetAPIKeyByHash scans for the key with a matching hash
+// A linear scan is fine for the handful of keys a dev box holds
+func (s *MemoryAPIKeyStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (model.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return model.APIKey{}, ErrAPIKeyNotFound
+}
+
+// RevokeAPIKey marks one of the user's keys as revoked
+func (s *MemoryAPIKeyStore) RevokeAPIKey(ctx context.Context, userID, id primitive.ObjectID) (model.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok || key.UserID != userID {
+		return model.APIKey{}, ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		s.keys[id] = key
+	}
+	return key, nil
+}
+
+// TouchAPIKey records when the key was last used
+func (s *MemoryAPIKeyStore) TouchAPIKey(ctx context.Context, id primitive.ObjectID, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[id]; ok {
+		key.LastUsedAt = &usedAt
+		s.keys[id] = key
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryCommentStore keeps comments in process memory, keyed by ID
+type MemoryCommentStore struct {
+	mu       sync.RWMutex
+	comments map[primitive.ObjectID]model.Comment
+}
+
+// NewMemoryCommentStore returns an empty in-memory comment store
+func NewMemoryCommentStore() *MemoryCommentStore {
+	return &MemoryCommentStore{
+		comments: make(map[primitive.ObjectID]model.Comment),
+	}
+}
+
+// CreateComment stores a copy of the comment
+func (s *MemoryCommentStore) CreateComment(ctx context.Context, comment *model.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.comments[comment.ID] = cloneComment(*comment)
+	return nil
+}
+
+// GetComment returns the comment if it belongs to the task and is inside the scope
+func (s *MemoryCommentStore) GetComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID) (model.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.find(scope, taskID, id)
+	if !ok {
+		return model.Comment{}, ErrCommentNotFound
+	}
+	return cloneComment(comment), nil
+}
+
+// ListComments returns one page of the task's comments, oldest first
+func (s *MemoryCommentStore) ListComments(ctx context.Context, scope Scope, taskID primitive.ObjectID, opts ListOptions) ([]model.Comment, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := []model.Comment{}
+	for _, comment := range s.comments {
+		if comment.TaskID == taskID && scope.AllowsOwner(comment.OwnerID) {
+			comment.History = nil
+			all = append(all, comment)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		a, b := all[i].Metadata.CreatedAt, all[j].Metadata.CreatedAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return all[i].ID.Hex() < all[j].ID.Hex()
+	})
+
+	start := opts.Skip()
+	if start >= len(all) {
+		return []model.Comment{}, int64(len(all)), nil
+	}
+	end := start + opts.Limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end], int64(len(all)), nil
+}
+
+// EditComment replaces the body, keeping the old one in the history
+func (s *MemoryCommentStore) EditComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, body string, editedAt time.Time) (model.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.find(scope, taskID, id)
+	if !ok {
+		return model.Comment{}, ErrCommentNotFound
+	}
+	if comment.IsDeleted() {
+		return model.Comment{}, ErrCommentDeleted
+	}
+
+	comment = cloneComment(comment)
+	comment.History = append(comment.History, model.CommentRevision{Body: comment.Body, WrittenAt: writtenAt(comment)})
+	comment.Body = body
+	comment.EditedAt = &editedAt
+	comment.Metadata.UpdatedAt = editedAt
+
+	s.comments[id] = comment
+	return cloneComment(comment), nil
+}
+
+// DeleteComment marks the comment deleted
+func (s *MemoryCommentStore) DeleteComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, deletedAt time.Time) (model.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.find(scope, taskID, id)
+	if !ok {
+		return model.Comment{}, ErrCommentNotFound
+	}
+	if comment.IsDeleted() {
+		return model.Comment{}, ErrCommentDeleted
+	}
+
+	comment.DeletedAt = &deletedAt
+	comment.Metadata.UpdatedAt = deletedAt
+
+	s.comments[id] = comment
+	return cloneComment(comment), nil
+}
+
+// SearchComments scores every live comment in the scope against the query
+func (s *MemoryCommentStore) SearchComments(ctx context.Context, scope Scope, query TextQuery, limit int) ([]CommentMatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []CommentMatch
+	for _, comment := range s.comments {
+		if comment.IsDeleted() || !scope.AllowsOwner(comment.OwnerID) {
+			continue
+		}
+		if score, ok := query.Score(WeightedText{comment.Body, commentWeight}); ok {
+			matches = append(matches, CommentMatch{
+				TaskID: comment.TaskID, CommentID: comment.ID, Body: comment.Body, Score: score,
+			})
+		}
+	}
+	return bestCommentMatches(matches, limit), nil
+}
+
+// DeleteCommentsByTasks removes every comment of the tasks
+func (s *MemoryCommentStore) DeleteCommentsByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doomed := make(map[primitive.ObjectID]bool, len(taskIDs))
+	for _, taskID := range taskIDs {
+		doomed[taskID] = true
+	}
+
+	var deleted int64
+	for id, comment := range s.comments {
+		if doomed[comment.TaskID] {
+			delete(s.comments, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteCommentsByOwner removes every comment on ownerID's tasks
+func (s *MemoryCommentStore) DeleteCommentsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, comment := range s.comments {
+		if comment.OwnerID == ownerID {
+			delete(s.comments, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// find looks a comment up by ID and checks it belongs to the task and the scope
+// The caller holds the lock
+func (s *MemoryCommentStore) find(scope Scope, taskID, id primitive.ObjectID) (model.Comment, bool) {
+	comment, ok := s.comments[id]
+	if !ok || comment.TaskID != taskID || !scope.AllowsOwner(comment.OwnerID) {
+		return model.Comment{}, false
+	}
+	return comment, true
+}
+
+// cloneComment copies the history so callers can't change the stored comment through it
+func cloneComment(comment model.Comment) model.Comment {
+	comment.History = append([]model.CommentRevision(nil), comment.History...)
+	return comment
+}
+
+// writtenAt is when the comment got its current body: its last edit, or its creation
+func writtenAt(comment model.Comment) time.Time {
+	if comment.EditedAt != nil {
+		return *comment.EditedAt
+	}
+	return comment.Metadata.CreatedAt
+}
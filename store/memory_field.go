@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryCustomFieldStore keeps custom field definitions in process memory, keyed by ID
+type MemoryCustomFieldStore struct {
+	mu     sync.RWMutex
+	fields map[primitive.ObjectID]model.CustomField
+}
+
+// NewMemoryCustomFieldStore returns an empty in-memory custom field store
+func NewMemoryCustomFieldStore() *MemoryCustomFieldStore {
+	return &MemoryCustomFieldStore{
+		fields: make(map[primitive.ObjectID]model.CustomField),
+	}
+}
+
+// CreateCustomField stores the definition; the lock makes the key check and the insert one step
+func (s *MemoryCustomFieldStore) CreateCustomField(ctx context.Context, field *model.CustomField) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.fields {
+		if existing.OwnerID == field.OwnerID && existing.Key == field.Key {
+			return ErrCustomFieldKeyTaken
+		}
+	}
+	s.fields[field.ID] = cloneCustomField(*field)
+	return nil
+}
+
+// GetCustomField returns the definition if it is inside the scope
+func (s *MemoryCustomFieldStore) GetCustomField(ctx context.Context, scope Scope, id primitive.ObjectID) (model.CustomField, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	field, ok := s.fields[id]
+	if !ok || !scope.AllowsOwner(field.OwnerID) {
+		return model.CustomField{}, ErrCustomFieldNotFound
+	}
+	return cloneCustomField(field), nil
+}
+
+// ListCustomFields returns the definitions in the scope, oldest first
+func (s *MemoryCustomFieldStore) ListCustomFields(ctx context.Context, scope Scope) ([]model.CustomField, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fields := []model.CustomField{}
+	for _, field := range s.fields {
+		if scope.AllowsOwner(field.OwnerID) {
+			fields = append(fields, cloneCustomField(field))
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].Metadata.CreatedAt, fields[j].Metadata.CreatedAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return fields[i].ID.Hex() < fields[j].ID.Hex()
+	})
+	return fields, nil
+}
+
+// UpdateCustomField applies the non-nil fields of the CustomFieldUpdate
+func (s *MemoryCustomFieldStore) UpdateCustomField(ctx context.Context, scope Scope, id primitive.ObjectID, update CustomFieldUpdate) (model.CustomField, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	field, ok := s.fields[id]
+	if !ok || !scope.AllowsOwner(field.OwnerID) {
+		return model.CustomField{}, ErrCustomFieldNotFound
+	}
+
+	update.Apply(&field)
+	field.Metadata.UpdatedAt = time.Now()
+
+	s.fields[id] = field
+	return cloneCustomField(field), nil
+}
+
+// DeleteCustomField removes the definition from the map
+func (s *MemoryCustomFieldStore) DeleteCustomField(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	field, ok := s.fields[id]
+	if !ok || !scope.AllowsOwner(field.OwnerID) {
+		return ErrCustomFieldNotFound
+	}
+	delete(s.fields, id)
+	return nil
+}
+
+// DeleteCustomFieldsByOwner removes every definition that belongs to ownerID
+func (s *MemoryCustomFieldStore) DeleteCustomFieldsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, field := range s.fields {
+		if field.OwnerID == ownerID {
+			delete(s.fields, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// cloneCustomField copies the options so callers can't change the stored definition
+func cloneCustomField(field model.CustomField) model.CustomField {
+	field.Options = append([]string(nil), field.Options...)
+	return field
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryProjectStore keeps projects in process memory, keyed by ID
+type MemoryProjectStore struct {
+	mu       sync.RWMutex
+	projects map[primitive.ObjectID]model.Project
+}
+
+// NewMemoryProjectStore returns an empty in-memory project store
+func NewMemoryProjectStore() *MemoryProjectStore {
+	return &MemoryProjectStore{
+		projects: make(map[primitive.ObjectID]model.Project),
+	}
+}
+
+// CreateProject stores the project
+func (s *MemoryProjectStore) CreateProject(ctx context.Context, project *model.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.projects[project.ID] = *project
+	return nil
+}
+
+// GetProject returns the project if it is inside the scope
+func (s *MemoryProjectStore) GetProject(ctx context.Context, scope Scope, id primitive.ObjectID) (model.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, ok := s.projects[id]
+	if !ok || !scope.AllowsOwner(project.OwnerID) {
+		return model.Project{}, ErrProjectNotFound
+	}
+	return project, nil
+}
+
+// ListProjects returns the projects in the scope, oldest first
+func (s *MemoryProjectStore) ListProjects(ctx context.Context, scope Scope, includeArchived bool) ([]model.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := []model.Project{}
+	for _, project := range s.projects {
+		if scope.AllowsOwner(project.OwnerID) && (includeArchived || !project.Archived) {
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		a, b := projects[i].Metadata.CreatedAt, projects[j].Metadata.CreatedAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return projects[i].ID.Hex() < projects[j].ID.Hex()
+	})
+	return projects, nil
+}
+
+// UpdateProject applies the non-nil fields of the ProjectUpdate
+func (s *MemoryProjectStore) UpdateProject(ctx context.Context, scope Scope, id primitive.ObjectID, update ProjectUpdate) (model.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok || !scope.AllowsOwner(project.OwnerID) {
+		return model.Project{}, ErrProjectNotFound
+	}
+
+	update.Apply(&project)
+	project.Metadata.UpdatedAt = time.Now()
+
+	s.projects[id] = project
+	return project, nil
+}
+
+// DeleteProject removes the project from the map
+func (s *MemoryProjectStore) DeleteProject(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok || !scope.AllowsOwner(project.OwnerID) {
+		return ErrProjectNotFound
+	}
+	delete(s.projects, id)
+	return nil
+}
+
+// DeleteProjectsByOwner removes every project that belongs to ownerID
+func (s *MemoryProjectStore) DeleteProjectsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, project := range s.projects {
+		if project.OwnerID == ownerID {
+			delete(s.projects, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fullTask sets every field of a task, so every pointer, slice and map has something behind it to share.
+// Each call builds the same task out of fresh memory, which makes a second call the expected value
+func fullTask() model.Task {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var last byte
+	newID := func() primitive.ObjectID {
+		last++
+		return primitive.ObjectID{last}
+	}
+	id := func() *primitive.ObjectID {
+		id := newID()
+		return &id
+	}
+	number := func(n int) *int { return &n }
+	return model.Task{
+		ID: newID(), OwnerID: newID(), ParentID: id(), ProjectID: id(),
+		Title: "t", Description: "d", Image: []string{"i"}, Priority: model.PriorityHigh, Tags: []string{"a", "b"},
+		Status: model.StatusInProgress, StatusSince: model.StatusTimes{model.StatusInProgress: at}, Rank: "m",
+		StartAt: &at, DueAt: &at,
+		Recurrence: &model.Recurrence{Rule: "FREQ=DAILY", TimeZone: "UTC", Anchor: at, SeriesID: newID(), NextID: id()},
+		BlockedBy:  []primitive.ObjectID{newID()},
+		Points:     number(3), Estimate: number(90),
+		Fields:      model.FieldValues{"sev": 2.0, "labels": []string{"x", "y"}, "decoded": []interface{}{"p", 1.0}},
+		Attachments: []model.Attachment{{ID: newID(), FileName: "f.png", UploadedAt: at}},
+		Checklist:   []model.ChecklistItem{{ID: newID(), Text: "step", Done: true, DoneAt: &at, CreatedAt: at}},
+		Progress:    &model.ChecklistProgress{Done: 1, Total: 1, Percent: 100},
+		Metadata:    model.Metadata{CreatedAt: at, UpdatedAt: at},
+	}
+}
+
+// unset lists the pointers, slices, maps and interfaces inside v that are nil or empty, by path
+func unset(v reflect.Value, path string) []string {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return []string{path}
+		}
+		return unset(v.Elem(), path)
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return []string{path}
+		}
+		if v.Kind() == reflect.Slice {
+			return unset(v.Index(0), path+"[0]")
+		}
+		var missing []string
+		for _, key := range v.MapKeys() {
+			missing = append(missing, unset(v.MapIndex(key), fmt.Sprintf("%s[%v]", path, key))...)
+		}
+		return missing
+	case reflect.Struct:
+		var missing []string
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				missing = append(missing, unset(v.Field(i), path+"."+v.Type().Field(i).Name)...)
+			}
+		}
+		return missing
+	}
+	return nil
+}
+
+// mutate changes every value v reaches, writing through its pointers, slices and maps
+func mutate(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			mutate(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if inner := v.Elem(); inner.Kind() == reflect.Slice || inner.Kind() == reflect.Map || inner.Kind() == reflect.Pointer {
+			mutate(inner) // Shares its backing data with v, so the change shows through
+		} else if v.CanSet() {
+			v.Set(reflect.ValueOf("mutated"))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			mutate(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			mutate(value)
+			v.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			v.Set(reflect.ValueOf(t.Add(time.Hour)))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				mutate(v.Field(i))
+			}
+		}
+	case reflect.String:
+		v.SetString(v.String() + "!")
+	case reflect.Bool:
+		v.SetBool(!v.Bool())
+	case reflect.Int, reflect.Int64:
+		v.SetInt(v.Int() + 1)
+	case reflect.Uint8:
+		v.SetUint(v.Uint() + 1)
+	case reflect.Float64:
+		v.SetFloat(v.Float() + 1)
+	}
+}
+
+func TestMemoryStoreCopiesTasks(t *testing.T) {
+	// A field added to model.Task must be set in fullTask, or the copies below wouldn't be checked for it
+	if missing := unset(reflect.ValueOf(fullTask()), "Task"); len(missing) > 0 {
+		t.Fatalf("fullTask leaves %v unset", missing)
+	}
+
+	ctx := context.Background()
+	scope := AnyOwner()
+	returned := map[string]func(s *MemoryTaskStore, task model.Task) model.Task{
+		"Create argument": func(s *MemoryTaskStore, task model.Task) model.Task {
+			return task // The caller still holds the task it passed to Create
+		},
+		"Get": func(s *MemoryTaskStore, task model.Task) model.Task {
+			got, err := s.Get(ctx, scope, task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return got
+		},
+		"List": func(s *MemoryTaskStore, task model.Task) model.Task {
+			got, _, err := s.List(ctx, scope, ListOptions{Page: 1, Limit: 10})
+			if err != nil || len(got) != 1 {
+				t.Fatalf("list: %v %v", got, err)
+			}
+			return got[0]
+		},
+		"GetMany": func(s *MemoryTaskStore, task model.Task) model.Task {
+			got, err := s.GetMany(ctx, scope, []primitive.ObjectID{task.ID})
+			if err != nil || len(got) != 1 {
+				t.Fatalf("get many: %v %v", got, err)
+			}
+			return got[0]
+		},
+		"Update": func(s *MemoryTaskStore, task model.Task) model.Task {
+			title := "renamed"
+			got, err := s.Update(ctx, scope, task.ID, TaskUpdate{Title: &title})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return got
+		},
+	}
+	for name, get := range returned {
+		t.Run(name, func(t *testing.T) {
+			s := NewMemoryTaskStore()
+			task := fullTask()
+			if err := s.Create(ctx, &task); err != nil {
+				t.Fatal(err)
+			}
+			copied := get(s, task)
+			mutate(reflect.ValueOf(&copied).Elem())
+
+			got, err := s.Get(ctx, scope, task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := fullTask()
+			if name == "Update" {
+				want.Title = "renamed"
+				want.Metadata.UpdatedAt = got.Metadata.UpdatedAt
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("changing the copy changed the stored task\nstored: %+v\nwant:   %+v", got, want)
+			}
+		})
+	}
+}
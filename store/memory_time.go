@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryTimeEntryStore keeps time entries in process memory, keyed by ID
+type MemoryTimeEntryStore struct {
+	mu      sync.RWMutex
+	entries map[primitive.ObjectID]model.TimeEntry
+}
+
+// NewMemoryTimeEntryStore returns an empty in-memory time entry store
+func NewMemoryTimeEntryStore() *MemoryTimeEntryStore {
+	return &MemoryTimeEntryStore{
+		entries: make(map[primitive.ObjectID]model.TimeEntry),
+	}
+}
+
+// CreateTimeEntry stores the entry; the lock makes the running timer check and the insert one step
+func (s *MemoryTimeEntryStore) CreateTimeEntry(ctx context.Context, entry *model.TimeEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.Running {
+		if _, ok := s.running(entry.UserID); ok {
+			return ErrTimerRunning
+		}
+	}
+	s.entries[entry.ID] = *entry
+	return nil
+}
+
+// RunningTimer returns the user's running timer
+func (s *MemoryTimeEntryStore) RunningTimer(ctx context.Context, userID primitive.ObjectID) (model.TimeEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.running(userID)
+	if !ok {
+		return model.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	return entry, nil
+}
+
+// StopTimer ends the user's running timer and records its duration
+func (s *MemoryTimeEntryStore) StopTimer(ctx context.Context, userID primitive.ObjectID, endedAt time.Time) (model.TimeEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.running(userID)
+	if !ok {
+		return model.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	entry.Running = false
+	entry.EndedAt = &endedAt
+	entry.Seconds = timerSeconds(entry.StartedAt, endedAt)
+	entry.Metadata.UpdatedAt = endedAt
+
+	s.entries[entry.ID] = entry
+	return entry, nil
+}
+
+// ListTimeEntries returns one page of the task's entries, most recent first
+func (s *MemoryTimeEntryStore) ListTimeEntries(ctx context.Context, scope Scope, taskID primitive.ObjectID, opts ListOptions) ([]model.TimeEntry, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := []model.TimeEntry{}
+	for _, entry := range s.entries {
+		if entry.TaskID == taskID && scope.AllowsOwner(entry.OwnerID) {
+			all = append(all, entry)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		a, b := all[i].StartedAt, all[j].StartedAt
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return all[i].ID.Hex() > all[j].ID.Hex()
+	})
+
+	start := opts.Skip()
+	if start >= len(all) {
+		return []model.TimeEntry{}, int64(len(all)), nil
+	}
+	end := start + opts.Limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end], int64(len(all)), nil
+}
+
+// DeleteTimeEntry removes the entry from the map
+func (s *MemoryTimeEntryStore) DeleteTimeEntry(ctx context.Context, scope Scope, taskID, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok || entry.TaskID != taskID || !scope.AllowsOwner(entry.OwnerID) {
+		return ErrTimeEntryNotFound
+	}
+	delete(s.entries, id)
+	return nil
+}
+
+// SumTimeByTask adds up the stopped entries matching the filter in one pass over the map
+func (s *MemoryTimeEntryStore) SumTimeByTask(ctx context.Context, scope Scope, filter TimeFilter) ([]model.TaskTime, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks map[primitive.ObjectID]bool
+	if filter.TaskIDs != nil {
+		tasks = make(map[primitive.ObjectID]bool, len(filter.TaskIDs))
+		for _, taskID := range filter.TaskIDs {
+			tasks[taskID] = true
+		}
+	}
+
+	totals := map[primitive.ObjectID]*model.TaskTime{}
+	for _, entry := range s.entries {
+		if entry.Running || !scope.AllowsOwner(entry.OwnerID) {
+			continue
+		}
+		if tasks != nil && !tasks[entry.TaskID] {
+			continue
+		}
+		if filter.From != nil && entry.StartedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !entry.StartedAt.Before(*filter.To) {
+			continue
+		}
+
+		total, ok := totals[entry.TaskID]
+		if !ok {
+			total = &model.TaskTime{TaskID: entry.TaskID}
+			totals[entry.TaskID] = total
+		}
+		total.Seconds += entry.Seconds
+		total.Entries++
+	}
+
+	sums := make([]model.TaskTime, 0, len(totals))
+	for _, total := range totals {
+		sums = append(sums, *total)
+	}
+	return sums, nil
+}
+
+// DeleteTimeEntriesByTasks removes every entry of the tasks
+func (s *MemoryTimeEntryStore) DeleteTimeEntriesByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doomed := make(map[primitive.ObjectID]bool, len(taskIDs))
+	for _, taskID := range taskIDs {
+		doomed[taskID] = true
+	}
+
+	var deleted int64
+	for id, entry := range s.entries {
+		if doomed[entry.TaskID] {
+			delete(s.entries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteTimeEntriesByOwner removes every entry on ownerID's tasks
+func (s *MemoryTimeEntryStore) DeleteTimeEntriesByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, entry := range s.entries {
+		if entry.OwnerID == ownerID {
+			delete(s.entries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// running finds the user's running timer; the caller holds the lock
+func (s *MemoryTimeEntryStore) running(userID primitive.ObjectID) (model.TimeEntry, bool) {
+	for _, entry := range s.entries {
+		if entry.Running && entry.UserID == userID {
+			return entry, true
+		}
+	}
+	return model.TimeEntry{}, false
+}
+
+// timerSeconds is the whole number of seconds a timer ran, never negative
+func timerSeconds(startedAt, endedAt time.Time) int64 {
+	seconds := int64(endedAt.Sub(startedAt) / time.Second)
+	if seconds < 0 {
+		return 0
+	}
+	return seconds
+}
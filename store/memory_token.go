@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRefreshTokenStore keeps refresh tokens in process memory, keyed by hash
+type MemoryRefreshTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]model.RefreshToken
+}
+
+// NewMemoryRefreshTokenStore returns an empty in-memory refresh token store
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens: make(map[string]model.RefreshToken),
+	}
+}
+
+// CreateRefreshToken stores the token record
+func (s *MemoryRefreshTokenStore) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[token.TokenHash] = *token
+	return nil
+}
+
+// GetRefreshToken looks a token up by hash
+func (s *MemoryRefreshTokenStore) GetRefreshToken(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return model.RefreshToken{}, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// RevokeRefreshToken marks one active token as revoked
+func (s *MemoryRefreshTokenStore) RevokeRefreshToken(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, token := range s.tokens {
+		if token.ID == id && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			s.tokens[hash] = token
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// RevokeRefreshFamily marks every active token of the family as revoked
+func (s *MemoryRefreshTokenStore) RevokeRefreshFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, token := range s.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			s.tokens[hash] = token
+		}
+	}
+	return nil
+}
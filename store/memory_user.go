@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryUserStore keeps users in process memory
+// A second map from email to ID plays the role of a unique index
+type MemoryUserStore struct {
+	mu      sync.RWMutex
+	users   map[primitive.ObjectID]model.User
+	byEmail map[string]primitive.ObjectID
+}
+
+// NewMemoryUserStore returns an empty in-memory user store
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		users:   make(map[primitive.ObjectID]model.User),
+		byEmail: make(map[string]primitive.ObjectID),
+	}
+}
+
+// CreateUser stores the user unless the email is already registered
+func (s *MemoryUserStore) CreateUser(ctx context.Context, user *model.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, taken := s.byEmail[user.Email]; taken {
+		return ErrEmailTaken
+	}
+	s.users[user.ID] = *user
+	s.byEmail[user.Email] = user.ID
+	return nil
+}
+
+// GetUserByID looks a user up by ID
+func (s *MemoryUserStore) GetUserByID(ctx context.Context, id primitive.ObjectID) (model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return model.User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// GetUserByEmail uses the email index to find the user
+func (s *MemoryUserStore) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.byEmail[email]
+	if !ok {
+		return model.User{}, ErrUserNotFound
+	}
+	return s.users[id], nil
+}
+
+// ListUsers returns one page of users in sign-up order
+func (s *MemoryUserStore) ListUsers(ctx context.Context, opts ListOptions) ([]model.User, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make([]model.User, 0, len(s.users))
+	for _, user := range s.users {
+		all = append(all, user)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		a, b := all[i].Metadata.CreatedAt, all[j].Metadata.CreatedAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return all[i].ID.Hex() < all[j].ID.Hex()
+	})
+
+	start := opts.Skip()
+	if start >= len(all) {
+		return []model.User{}, int64(len(all)), nil
+	}
+	end := start + opts.Limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end], int64(len(all)), nil
+}
+
+// UpdateUserRole changes the role of a stored user
+func (s *MemoryUserStore) UpdateUserRole(ctx context.Context, id primitive.ObjectID, role model.Role) (model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return model.User{}, ErrUserNotFound
+	}
+	user.Role = role
+	user.Metadata.UpdatedAt = time.Now()
+	s.users[id] = user
+	return user, nil
+}
+
+// DeleteUser removes the user and its email index entry
+func (s *MemoryUserStore) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	delete(s.users, id)
+	delete(s.byEmail, user.Email)
+	return nil
+}
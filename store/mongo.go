@@ -0,0 +1,963 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoTaskStore is the MongoDB implementation of TaskStore
+// All tasks live in the "tasks" collection of the "Go" database
+type MongoTaskStore struct {
+	collection *mongo.Collection
+}
+
+// OpenMongo builds every store on top of an already connected client
+func OpenMongo(ctx context.Context, client *mongo.Client) (Stores, error) {
+	tasks, err := NewMongoTaskStore(ctx, client)
+	if err != nil {
+		return Stores{}, err
+	}
+	users, err := NewMongoUserStore(ctx, client)
+	if err != nil {
+		return Stores{}, err
+	}
+	refreshTokens, err := NewMongoRefreshTokenStore(ctx, client)
+	if err != nil {
+		return Stores{}, err
+	}
+	apiKeys, err := NewMongoAPIKeyStore(ctx, client)
+	if err != nil {
+		return Stores{}, err
+	}
+	projects, err := NewMongoProjectStore(ctx, client)
+	if err != nil {
+		return Stores{}, err
+	}
+	comments, err := NewMongoCommentStore(ctx, client)
+	if err != nil {
+		return Stores{}, err
+	}
+	timeEntries, err := NewMongoTimeEntryStore(ctx, client)
+	if err != nil {
+		return Stores{}, err
+	}
+	customFields, err := NewMongoCustomFieldStore(ctx, client)
+	if err != nil {
+		return Stores{}, err
+	}
+
+	return Stores{
+		Tasks:         tasks,
+		Users:         users,
+		RefreshTokens: refreshTokens,
+		APIKeys:       apiKeys,
+		Projects:      projects,
+		Comments:      comments,
+		TimeEntries:   timeEntries,
+		CustomFields:  customFields,
+		Close:         func() error { return nil }, // The connection package owns the client
+	}, nil
+}
+
+// NewMongoTaskStore builds a TaskStore on top of an already connected client
+// Every query filters on owner_id, so indexes starting with it keep lists fast as users grow
+func NewMongoTaskStore(ctx context.Context, client *mongo.Client) (*MongoTaskStore, error) {
+	collection := client.Database("Go").Collection("tasks")
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "metadata.created_at", Value: -1}},
+			Options: options.Index().SetName("owner_created_at"),
+		},
+		{
+			// Serves the children of a task and the subtree walks of DeleteTree
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "parent_id", Value: 1}},
+			Options: options.Index().SetName("owner_parent"),
+		},
+		{
+			// Serves the due_before / due_after / overdue filters
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "due_at", Value: 1}},
+			Options: options.Index().SetName("owner_due_at"),
+		},
+		{
+			// Serves the project filter and the per-project counts
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "project_id", Value: 1}},
+			Options: options.Index().SetName("owner_project"),
+		},
+		{
+			// Serves the status filter
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("owner_status"),
+		},
+		{
+			// Serves the tag filter; tags is an array, so this is a multikey index
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "tags", Value: 1}},
+			Options: options.Index().SetName("owner_tags"),
+		},
+		{
+			// Serves SearchTasks; a collection can have only one text index, so it covers both fields
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().SetName("text").
+				SetWeights(bson.D{{Key: "title", Value: titleWeight}, {Key: "description", Value: descriptionWeight}}),
+		},
+		{
+			// Serves filters on custom fields, whose keys differ from user to user
+			Keys:    bson.D{{Key: "custom_fields.$**", Value: 1}},
+			Options: options.Index().SetName("custom_fields"),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoTaskStore{collection: collection}, nil
+}
+
+// Create inserts the task document as-is
+func (s *MongoTaskStore) Create(ctx context.Context, task *model.Task) error {
+	_, err := s.collection.InsertOne(ctx, task)
+	return err
+}
+
+// Get finds a single task by its ObjectID
+func (s *MongoTaskStore) Get(ctx context.Context, scope Scope, id primitive.ObjectID) (model.Task, error) {
+	var task model.Task
+	err := s.collection.FindOne(ctx, scopedByID(scope, id)).Decode(&task)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Translate the driver specific error into our own sentinel
+		return model.Task{}, ErrNotFound
+	}
+	return task, err
+}
+
+// List returns one page of tasks and the total document count
+func (s *MongoTaskStore) List(ctx context.Context, scope Scope, opts ListOptions) ([]model.Task, int64, error) {
+	// Only the caller's tasks matching the filter are counted and returned
+	filter := taskFilter(scope, opts.Filter)
+
+	// Count total documents for pagination info
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Sort with an aggregation: a priority rank isn't stored, so it has to be computed before the $sort
+	// A position replaces the skip: the page starts right next to it however many tasks came before
+	keys := opts.Sort.OrDefault()
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	pipeline = append(pipeline, rankStages(keys)...)
+	skip := int64(opts.Skip())
+	reverse := false
+	if opts.From != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: positionFilter(keys, *opts.From)}})
+		skip, reverse = 0, opts.From.Before
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: sortOrder(keys, reverse)}},
+		bson.D{{Key: "$skip", Value: skip}},
+		bson.D{{Key: "$limit", Value: int64(opts.Limit)}},
+		bson.D{{Key: "$project", Value: bson.M{priorityRankPath: 0}}},
+	)
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	// cursor.All iterates the cursor and decodes every document for us
+	tasks := []model.Task{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, 0, err
+	}
+	if reverse {
+		slices.Reverse(tasks)
+	}
+
+	return tasks, total, nil
+}
+
+// Update applies only the fields that are set on the TaskUpdate
+func (s *MongoTaskStore) Update(ctx context.Context, scope Scope, id primitive.ObjectID, update TaskUpdate) (model.Task, error) {
+	set := bson.M{"metadata.updated_at": time.Now()}
+	if update.Title != nil {
+		set["title"] = *update.Title
+	}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.Image != nil {
+		set["image"] = *update.Image
+	}
+	if update.Priority != nil {
+		set["priority"] = *update.Priority
+	}
+	if update.Tags != nil {
+		set["tags"] = *update.Tags
+	}
+	if update.Completed != nil {
+		set["completed"] = *update.Completed
+	}
+	if update.Rank != nil {
+		set["rank"] = *update.Rank
+	}
+
+	// Fields sent as null are removed from the document instead of stored as null
+	unset := bson.M{}
+	setOrUnset(set, unset, "start_at", update.StartAt)
+	setOrUnset(set, unset, "due_at", update.DueAt)
+	setOrUnset(set, unset, "recurrence", update.Recurrence)
+	setOrUnset(set, unset, "parent_id", update.ParentID)
+	setOrUnset(set, unset, "project_id", update.ProjectID)
+	setOrUnset(set, unset, "points", update.Points)
+	setOrUnset(set, unset, "estimate_minutes", update.Estimate)
+	for key, value := range update.Fields {
+		if value == nil {
+			unset["custom_fields."+key] = ""
+		} else {
+			set["custom_fields."+key] = value
+		}
+	}
+
+	changes := bson.M{"$set": set}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+	if update.Transition == nil {
+		return s.findOneAndUpdate(ctx, scope, id, changes)
+	}
+
+	// A transition only applies while the task is still in the status the handler saw
+	to := update.Transition.To
+	set["status"] = to
+	set["completed"] = to.IsTerminal()
+	set["status_entered_at."+string(to)] = update.Transition.At
+
+	filter := scopedByID(scope, id)
+	filter["$and"] = []bson.M{statusFilter(update.Transition.From)}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task model.Task
+	err := s.collection.FindOneAndUpdate(ctx, filter, changes, opts).Decode(&task)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return task, err
+	}
+
+	// Tell "no such task" apart from "the task is in another status by now"
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return model.Task{}, err
+	}
+	return model.Task{}, ErrStatusConflict
+}
+
+// Delete removes the task and reports ErrNotFound when nothing was deleted
+func (s *MongoTaskStore) Delete(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, scopedByID(scope, id))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return s.dropDependencies(ctx, id)
+}
+
+// AssignOwnerless sets owner_id on every document that doesn't have one yet
+// Documents written before ownership existed have no owner_id field at all
+func (s *MongoTaskStore) AssignOwnerless(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	filter := bson.M{"$or": []bson.M{
+		{"owner_id": bson.M{"$exists": false}},
+		{"owner_id": nil},
+		{"owner_id": primitive.NilObjectID},
+	}}
+	result, err := s.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"owner_id": ownerID}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// DeleteByOwner removes every task that belongs to ownerID
+func (s *MongoTaskStore) DeleteByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// ListChildren returns the direct subtasks of parentID, oldest first
+func (s *MongoTaskStore) ListChildren(ctx context.Context, scope Scope, parentID primitive.ObjectID) ([]model.Task, error) {
+	filter := scopeFilter(scope)
+	filter["parent_id"] = parentID
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "metadata.created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	children := []model.Task{}
+	if err := cursor.All(ctx, &children); err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// DetachChildren removes parent_id from every direct subtask of parentID
+func (s *MongoTaskStore) DetachChildren(ctx context.Context, scope Scope, parentID primitive.ObjectID) (int64, error) {
+	filter := scopeFilter(scope)
+	filter["parent_id"] = parentID
+
+	update := bson.M{
+		"$unset": bson.M{"parent_id": ""},
+		"$set":   bson.M{"metadata.updated_at": time.Now()},
+	}
+	result, err := s.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// DeleteTree collects the IDs of the task and its subtasks one level at a time,
+// then deletes them all with a single DeleteMany
+func (s *MongoTaskStore) DeleteTree(ctx context.Context, scope Scope, id primitive.ObjectID) (int64, error) {
+	if err := s.collection.FindOne(ctx, scopedByID(scope, id)).Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	doomed := []primitive.ObjectID{id}
+	level := []primitive.ObjectID{id}
+	for len(level) > 0 {
+		filter := scopeFilter(scope)
+		filter["parent_id"] = bson.M{"$in": level}
+
+		// Only the IDs are needed, so skip decoding the rest of the documents
+		cursor, err := s.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			return 0, err
+		}
+		var children []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &children); err != nil {
+			return 0, err
+		}
+
+		level = level[:0]
+		for _, child := range children {
+			level = append(level, child.ID)
+		}
+		doomed = append(doomed, level...)
+	}
+
+	result, err := s.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": doomed}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, s.dropDependencies(ctx, doomed...)
+}
+
+// GetMany finds every task in ids with a single $in query
+func (s *MongoTaskStore) GetMany(ctx context.Context, scope Scope, ids []primitive.ObjectID) ([]model.Task, error) {
+	filter := scopeFilter(scope)
+	filter["_id"] = bson.M{"$in": ids}
+
+	cursor, err := s.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tasks := []model.Task{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// AddDependency adds blockerID to blocked_by after checking it closes no loop
+// The walk and the write are separate operations, so the walk runs again after the write: of two requests
+// adding opposite edges at the same time, at least one sees the other's edge then and takes its own back out
+func (s *MongoTaskStore) AddDependency(ctx context.Context, scope Scope, id, blockerID primitive.ObjectID) (model.Task, error) {
+	load := func(ctx context.Context, ids []primitive.ObjectID) ([]model.Task, error) {
+		return s.GetMany(ctx, AnyOwner(), ids)
+	}
+	if cycle, err := blocksTransitively(ctx, load, id, blockerID); err != nil || cycle {
+		if err == nil {
+			err = ErrDependencyCycle
+		}
+		return model.Task{}, err
+	}
+
+	// Only matching tasks without the edge tells "added it" apart from "it was already there"
+	filter := scopedByID(scope, id)
+	filter["blocked_by"] = bson.M{"$ne": blockerID}
+	update := bson.M{
+		"$push": bson.M{"blocked_by": blockerID},
+		"$set":  bson.M{"metadata.updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task model.Task
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&task)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return s.Get(ctx, scope, id) // Missing, or the edge already existed and nothing changed
+	}
+	if err != nil {
+		return model.Task{}, err
+	}
+
+	cycle, err := blocksTransitively(ctx, load, id, blockerID)
+	if err == nil && !cycle {
+		return task, nil
+	}
+	undo := bson.M{"$pull": bson.M{"blocked_by": blockerID}}
+	if _, undoErr := s.collection.UpdateOne(ctx, scopedByID(scope, id), undo); undoErr != nil {
+		return model.Task{}, undoErr
+	}
+	if err == nil {
+		err = ErrDependencyCycle
+	}
+	return model.Task{}, err
+}
+
+// RemoveDependency pulls blockerID out of blocked_by
+// The filter also requires the edge to exist, so a miss is either a missing task or a missing edge
+func (s *MongoTaskStore) RemoveDependency(ctx context.Context, scope Scope, id, blockerID primitive.ObjectID) (model.Task, error) {
+	filter := scopedByID(scope, id)
+	filter["blocked_by"] = blockerID
+
+	update := bson.M{
+		"$pull": bson.M{"blocked_by": blockerID},
+		"$set":  bson.M{"metadata.updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task model.Task
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&task)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return task, err
+	}
+
+	// Tell "no such task" apart from "the task has no such dependency"
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return model.Task{}, err
+	}
+	return model.Task{}, ErrDependencyNotFound
+}
+
+// AddAttachment pushes the attachment onto the task's embedded list
+func (s *MongoTaskStore) AddAttachment(ctx context.Context, scope Scope, id primitive.ObjectID, attachment model.Attachment) (model.Task, error) {
+	update := bson.M{
+		"$push": bson.M{"attachments": attachment},
+		"$set":  bson.M{"metadata.updated_at": time.Now()},
+	}
+	return s.findOneAndUpdate(ctx, scope, id, update)
+}
+
+// RemoveAttachment pulls the attachment out of the embedded list
+// Like RemoveDependency, the filter requires the attachment, so a miss needs a second look
+func (s *MongoTaskStore) RemoveAttachment(ctx context.Context, scope Scope, id, attachmentID primitive.ObjectID) (model.Task, error) {
+	filter := scopedByID(scope, id)
+	filter["attachments._id"] = attachmentID
+
+	update := bson.M{
+		"$pull": bson.M{"attachments": bson.M{"_id": attachmentID}},
+		"$set":  bson.M{"metadata.updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task model.Task
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&task)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return task, err
+	}
+
+	// Tell "no such task" apart from "the task has no such attachment"
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return model.Task{}, err
+	}
+	return model.Task{}, ErrAttachmentNotFound
+}
+
+// AddChecklistItem pushes the item into the embedded checklist at the requested position
+func (s *MongoTaskStore) AddChecklistItem(ctx context.Context, scope Scope, id primitive.ObjectID, item model.ChecklistItem, position int) (model.Task, error) {
+	push := bson.M{"$each": bson.A{item}}
+	if position >= 0 {
+		push["$position"] = position // Past the end, $position appends
+	}
+	update := bson.M{
+		"$push": bson.M{"checklist": push},
+		"$set":  bson.M{"metadata.updated_at": time.Now()},
+	}
+	return s.findOneAndUpdate(ctx, scope, id, update)
+}
+
+// UpdateChecklistItem sets the fields of one item in place with the positional $ operator
+// The filter also requires the item to exist, so a miss is either a missing task or a missing item
+func (s *MongoTaskStore) UpdateChecklistItem(ctx context.Context, scope Scope, id, itemID primitive.ObjectID, update ChecklistItemUpdate) (model.Task, error) {
+	filter := scopedByID(scope, id)
+	filter["checklist._id"] = itemID
+
+	now := time.Now()
+	set := bson.M{"metadata.updated_at": now}
+	unset := bson.M{}
+	if update.Text != nil {
+		set["checklist.$.text"] = *update.Text
+	}
+	if update.Done != nil {
+		set["checklist.$.done"] = *update.Done
+		if *update.Done {
+			set["checklist.$.done_at"] = now
+		} else {
+			unset["checklist.$.done_at"] = ""
+		}
+	}
+	changes := bson.M{"$set": set}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task model.Task
+	err := s.collection.FindOneAndUpdate(ctx, filter, changes, opts).Decode(&task)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return task, err
+	}
+
+	// Tell "no such task" apart from "the task has no such item"
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return model.Task{}, err
+	}
+	return model.Task{}, ErrChecklistItemNotFound
+}
+
+// RemoveChecklistItem pulls the item out of the embedded checklist
+func (s *MongoTaskStore) RemoveChecklistItem(ctx context.Context, scope Scope, id, itemID primitive.ObjectID) (model.Task, error) {
+	filter := scopedByID(scope, id)
+	filter["checklist._id"] = itemID
+
+	update := bson.M{
+		"$pull": bson.M{"checklist": bson.M{"_id": itemID}},
+		"$set":  bson.M{"metadata.updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task model.Task
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&task)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return task, err
+	}
+
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return model.Task{}, err
+	}
+	return model.Task{}, ErrChecklistItemNotFound
+}
+
+// ReorderChecklist rebuilds the checklist from the stored items in a single pipeline update:
+// each requested ID is mapped to its item, so nothing changed by a concurrent request is lost
+// The filter only matches while the checklist holds exactly the requested items; $all is satisfied by
+// [a, a, b] on a checklist of a, b, c, so repeated IDs are turned away before the update
+func (s *MongoTaskStore) ReorderChecklist(ctx context.Context, scope Scope, id primitive.ObjectID, itemIDs []primitive.ObjectID) (model.Task, error) {
+	if hasRepeats(itemIDs) {
+		if _, err := s.Get(ctx, scope, id); err != nil {
+			return model.Task{}, err
+		}
+		return model.Task{}, ErrChecklistMismatch
+	}
+
+	filter := scopedByID(scope, id)
+	filter["checklist"] = bson.M{"$size": len(itemIDs)}
+	if len(itemIDs) > 0 {
+		filter["checklist._id"] = bson.M{"$all": itemIDs}
+	}
+
+	reordered := bson.M{"$map": bson.M{
+		"input": itemIDs,
+		"as":    "id",
+		"in": bson.M{"$arrayElemAt": bson.A{
+			bson.M{"$filter": bson.M{"input": "$checklist", "cond": bson.M{"$eq": bson.A{"$$this._id", "$$id"}}}},
+			0,
+		}},
+	}}
+	update := bson.A{bson.M{"$set": bson.M{"checklist": reordered, "metadata.updated_at": time.Now()}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task model.Task
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&task)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return task, err
+	}
+
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return model.Task{}, err
+	}
+	return model.Task{}, ErrChecklistMismatch
+}
+
+// hasRepeats reports whether an ID appears more than once in ids
+func hasRepeats(ids []primitive.ObjectID) bool {
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return true
+		}
+		seen[id] = true
+	}
+	return false
+}
+
+// CountByProject groups the tasks of the requested projects on the server
+// so only one small document per project comes back
+func (s *MongoTaskStore) CountByProject(ctx context.Context, scope Scope, projectIDs []primitive.ObjectID) (map[primitive.ObjectID]model.ProjectCounts, error) {
+	match := scopeFilter(scope)
+	match["project_id"] = bson.M{"$in": projectIDs}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id":       "$project_id",
+			"open":      bson.M{"$sum": bson.M{"$cond": bson.A{"$completed", 0, 1}}},
+			"completed": bson.M{"$sum": bson.M{"$cond": bson.A{"$completed", 1, 0}}},
+		}},
+	}
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []struct {
+		ProjectID primitive.ObjectID `bson:"_id"`
+		Open      int64              `bson:"open"`
+		Completed int64              `bson:"completed"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]model.ProjectCounts, len(groups))
+	for _, group := range groups {
+		counts[group.ProjectID] = model.ProjectCounts{Open: group.Open, Completed: group.Completed}
+	}
+	return counts, nil
+}
+
+// DetachProject removes project_id from every task in projectID
+func (s *MongoTaskStore) DetachProject(ctx context.Context, scope Scope, projectID primitive.ObjectID) (int64, error) {
+	filter := scopeFilter(scope)
+	filter["project_id"] = projectID
+
+	update := bson.M{
+		"$unset": bson.M{"project_id": ""},
+		"$set":   bson.M{"metadata.updated_at": time.Now()},
+	}
+	result, err := s.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// SearchTasks runs the query on the text index, best matches first
+func (s *MongoTaskStore) SearchTasks(ctx context.Context, scope Scope, query TextQuery, limit int) ([]TextMatch, error) {
+	filter := scopeFilter(scope)
+	filter["$text"] = bson.M{"$search": query.MongoSearch()}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1, "score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Score float64            `bson:"score"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	matches := make([]TextMatch, len(results))
+	for i, result := range results {
+		matches[i] = TextMatch{TaskID: result.ID, Score: result.Score}
+	}
+	return matches, nil
+}
+
+// ClearCustomField unsets the custom field on every task that has it
+func (s *MongoTaskStore) ClearCustomField(ctx context.Context, scope Scope, key string) (int64, error) {
+	filter := scopeFilter(scope)
+	filter["custom_fields."+key] = bson.M{"$exists": true}
+
+	update := bson.M{
+		"$unset": bson.M{"custom_fields." + key: ""},
+		"$set":   bson.M{"metadata.updated_at": time.Now()},
+	}
+	result, err := s.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// dropDependencies removes deleted tasks from the blocked_by arrays of the remaining ones
+func (s *MongoTaskStore) dropDependencies(ctx context.Context, deleted ...primitive.ObjectID) error {
+	_, err := s.collection.UpdateMany(ctx,
+		bson.M{"blocked_by": bson.M{"$in": deleted}},
+		bson.M{"$pull": bson.M{"blocked_by": bson.M{"$in": deleted}}})
+	return err
+}
+
+// findOneAndUpdate runs an update and returns the document after the change
+func (s *MongoTaskStore) findOneAndUpdate(ctx context.Context, scope Scope, id primitive.ObjectID, update interface{}) (model.Task, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task model.Task
+	err := s.collection.FindOneAndUpdate(ctx, scopedByID(scope, id), update, opts).Decode(&task)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Task{}, ErrNotFound
+	}
+	return task, err
+}
+
+// scopeFilter turns a Scope into the matching MongoDB filter
+func scopeFilter(scope Scope) bson.M {
+	if scope.AllOwners {
+		return bson.M{}
+	}
+	return bson.M{"owner_id": scope.OwnerID}
+}
+
+// setOrUnset adds an optional field to the $set or $unset part of an update
+func setOrUnset[T any](set, unset bson.M, field string, value Optional[T]) {
+	if !value.Set {
+		return
+	}
+	if value.Value == nil {
+		unset[field] = ""
+		return
+	}
+	set[field] = *value.Value
+}
+
+// priorityRankPath is where sortStages puts the computed rank of a task's priority
+const priorityRankPath = "priority_rank"
+
+// mongoSortPaths maps SortFields to the document fields they sort on
+var mongoSortPaths = map[string]string{
+	"created_at":       "metadata.created_at",
+	"updated_at":       "metadata.updated_at",
+	"start_at":         "start_at",
+	"due_at":           "due_at",
+	"priority":         priorityRankPath,
+	"title":            "title",
+	"points":           "points",
+	"estimate_minutes": "estimate_minutes",
+}
+
+// sortPath returns the document field a key sorts on
+func sortPath(key SortKey) string {
+	if key.Custom {
+		return "custom_fields." + key.Field
+	}
+	return mongoSortPaths[key.Field]
+}
+
+// rankStages computes the priority rank when keys sort on it; it has to run before the $sort and any position filter
+func rankStages(keys TaskSort) mongo.Pipeline {
+	for _, key := range keys {
+		if key.Field == "priority" && !key.Custom {
+			return mongo.Pipeline{{{Key: "$addFields", Value: bson.M{priorityRankPath: priorityRank()}}}}
+		}
+	}
+	return nil
+}
+
+// sortOrder orders tasks by keys and then by _id, newest first
+// reverse flips every direction, for reading the tasks before a position nearest first
+// A missing value sorts like null: first ascending, last descending
+func sortOrder(keys TaskSort, reverse bool) bson.D {
+	direction := func(desc bool) int {
+		if desc != reverse {
+			return -1
+		}
+		return 1
+	}
+	order := bson.D{}
+	for _, key := range keys {
+		order = append(order, bson.E{Key: sortPath(key), Value: direction(key.Desc)})
+	}
+	return append(order, bson.E{Key: "_id", Value: direction(true)})
+}
+
+// positionFilter keeps the tasks that come after pos in keys order, or before it when pos.Before is set
+// For keys (a, b) that is: a beyond pos.a, or a equal and b beyond pos.b, or both equal and the _id beyond
+func positionFilter(keys TaskSort, pos Position) bson.M {
+	alternatives := bson.A{}
+	equal := bson.M{}
+	for i := 0; i <= len(keys); i++ {
+		path, desc, value := "_id", true, interface{}(pos.ID)
+		if i < len(keys) {
+			path, desc, value = sortPath(keys[i]), keys[i].Desc, pos.Values[i]
+		}
+		if pos.Before {
+			desc = !desc
+		}
+
+		// Null and missing sort below every value: nothing is beyond them in descending order
+		var beyond bson.M
+		switch {
+		case value == nil && !desc:
+			beyond = bson.M{path: bson.M{"$ne": nil}}
+		case value != nil && !desc:
+			beyond = bson.M{path: bson.M{"$gt": value}}
+		case value != nil && desc:
+			beyond = bson.M{"$or": bson.A{bson.M{path: bson.M{"$lt": value}}, bson.M{path: nil}}}
+		}
+		if beyond != nil {
+			alternative := bson.A{beyond}
+			for field, condition := range equal {
+				alternative = append(alternative, bson.M{field: condition})
+			}
+			alternatives = append(alternatives, bson.M{"$and": alternative})
+		}
+		equal[path] = value
+	}
+	return bson.M{"$or": alternatives}
+}
+
+// priorityRank is an expression for Priority.Rank, null for an unknown priority
+func priorityRank() bson.M {
+	branches := make(bson.A, len(model.Priorities))
+	for rank, priority := range model.Priorities {
+		branches[rank] = bson.M{"case": bson.M{"$eq": bson.A{"$priority", string(priority)}}, "then": rank}
+	}
+	return bson.M{"$switch": bson.M{"branches": branches, "default": nil}}
+}
+
+// taskFilter combines the scope with the list filter
+// Each condition is added to $and so two conditions on the same field don't overwrite each other
+func taskFilter(scope Scope, f TaskFilter) bson.M {
+	filter := scopeFilter(scope)
+
+	var and []bson.M
+	if f.DueBefore != nil {
+		and = append(and, bson.M{"due_at": bson.M{"$lt": *f.DueBefore}})
+	}
+	if f.DueAfter != nil {
+		and = append(and, bson.M{"due_at": bson.M{"$gt": *f.DueAfter}})
+	}
+	if f.Overdue != nil {
+		overdue := bson.M{"completed": false, "due_at": bson.M{"$lt": f.Now}}
+		if *f.Overdue {
+			and = append(and, overdue)
+		} else {
+			and = append(and, bson.M{"$nor": []bson.M{overdue}})
+		}
+	}
+	if f.ProjectID != nil {
+		and = append(and, bson.M{"project_id": *f.ProjectID})
+	}
+	if f.NoProject {
+		and = append(and, bson.M{"project_id": nil}) // Matches a missing field as well as null
+	}
+	if f.Status != nil {
+		and = append(and, statusFilter(*f.Status))
+	}
+	if len(f.Tags) > 0 {
+		operator := "$in"
+		if f.AllTags {
+			operator = "$all"
+		}
+		and = append(and, bson.M{"tags": bson.M{operator: f.Tags}})
+	}
+	if len(f.Priorities) > 0 {
+		and = append(and, bson.M{"priority": bson.M{"$in": f.Priorities}})
+	}
+	if f.Completed != nil {
+		and = append(and, bson.M{"completed": *f.Completed})
+	}
+	if f.Title != "" {
+		// QuoteMeta makes the text match literally, so a query can't inject a pattern
+		title := primitive.Regex{Pattern: regexp.QuoteMeta(f.Title), Options: "i"}
+		and = append(and, bson.M{"title": title})
+	}
+	for _, bound := range []struct {
+		field, operator string
+		at              *time.Time
+	}{
+		{"metadata.created_at", "$lt", f.CreatedBefore},
+		{"metadata.created_at", "$gt", f.CreatedAfter},
+		{"metadata.updated_at", "$lt", f.UpdatedBefore},
+		{"metadata.updated_at", "$gt", f.UpdatedAfter},
+	} {
+		if bound.at != nil {
+			and = append(and, bson.M{bound.field: bson.M{bound.operator: *bound.at}})
+		}
+	}
+	for _, condition := range f.Fields {
+		and = append(and, fieldFilter(condition))
+	}
+
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+	return filter
+}
+
+// fieldFilter matches the tasks passing one custom field condition
+// Equality on an array field matches when any item is equal, which is what multi_select needs
+func fieldFilter(c FieldCondition) bson.M {
+	path := "custom_fields." + c.Key
+	var match bson.M
+	if c.Op == FieldEq {
+		match = bson.M{path: c.Value}
+	} else {
+		match = bson.M{path: bson.M{"$" + string(c.Op): c.Value}}
+	}
+	if !c.Missing {
+		return match
+	}
+	return bson.M{"$or": []bson.M{match, {path: nil}}} // null matches a missing field too
+}
+
+// statusFilter matches the tasks in a status
+// Tasks written before statuses existed have no status field; like
+// model.Task.EffectiveStatus, they count as done when completed and todo otherwise
+func statusFilter(status model.Status) bson.M {
+	if status != model.StatusTodo && status != model.StatusDone {
+		return bson.M{"status": status}
+	}
+	return bson.M{"$or": []bson.M{
+		{"status": status},
+		{"status": bson.M{"$exists": false}, "completed": status == model.StatusDone},
+	}}
+}
+
+// scopedByID matches one task by ID, but only inside the scope
+func scopedByID(scope Scope, id primitive.ObjectID) bson.M {
+	filter := scopeFilter(scope)
+	filter["_id"] = id
+	return filter
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoAPIKeyStore is the MongoDB implementation of APIKeyStore
+type MongoAPIKeyStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAPIKeyStore returns the store and creates its indexes
+func NewMongoAPIKeyStore(ctx context.Context, client *mongo.Client) (*MongoAPIKeyStore, error) {
+	collection := client.Database("Go").Collection("api_keys")
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("key_hash_unique"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("user_created_at"),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoAPIKeyStore{collection: collection}, nil
+}
+
+// CreateAPIKey inserts a new key
+func (s *MongoAPIKeyStore) CreateAPIKey(ctx context.Context, key *model.APIKey) error {
+	_, err := s.collection.InsertOne(ctx, key)
+	return err
+}
+
+// ListAPIKeys returns the user's keys, newest first
+func (s *MongoAPIKeyStore) ListAPIKeys(ctx context.Context, userID primitive.ObjectID) ([]model.APIKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []model.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash finds a key by its hash
+func (s *MongoAPIKeyStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (model.APIKey, error) {
+	var key model.APIKey
+	err := s.collection.FindOne(ctx, bson.M{"key_hash": keyHash}).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.APIKey{}, ErrAPIKeyNotFound
+	}
+	return key, err
+}
+
+// RevokeAPIKey sets revoked_at on one of the user's keys
+// Revoking an already revoked key keeps the original revocation time
+func (s *MongoAPIKeyStore) RevokeAPIKey(ctx context.Context, userID, id primitive.ObjectID) (model.APIKey, error) {
+	filter := bson.M{"_id": id, "user_id": userID}
+	pipeline := []bson.M{
+		{"$set": bson.M{"revoked_at": bson.M{"$ifNull": bson.A{"$revoked_at", time.Now()}}}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var key model.APIKey
+	err := s.collection.FindOneAndUpdate(ctx, filter, pipeline, opts).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.APIKey{}, ErrAPIKeyNotFound
+	}
+	return key, err
+}
+
+// TouchAPIKey stores the last time the key was used
+func (s *MongoAPIKeyStore) TouchAPIKey(ctx context.Context, id primitive.ObjectID, usedAt time.Time) error {
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": usedAt}})
+	return err
+}
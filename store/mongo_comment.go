@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoCommentStore is the MongoDB implementation of CommentStore
+// The edit history is embedded in each comment document
+type MongoCommentStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCommentStore returns the store and creates its indexes
+func NewMongoCommentStore(ctx context.Context, client *mongo.Client) (*MongoCommentStore, error) {
+	collection := client.Database("Go").Collection("comments")
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// Serves the thread of a task, oldest first
+			Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "metadata.created_at", Value: 1}},
+			Options: options.Index().SetName("task_created_at"),
+		},
+		{
+			// Serves SearchComments
+			Keys:    bson.D{{Key: "body", Value: "text"}},
+			Options: options.Index().SetName("text").SetWeights(bson.D{{Key: "body", Value: commentWeight}}),
+		},
+		{
+			// Serves DeleteCommentsByOwner
+			Keys:    bson.D{{Key: "owner_id", Value: 1}},
+			Options: options.Index().SetName("owner"),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoCommentStore{collection: collection}, nil
+}
+
+// CreateComment inserts the comment document as-is
+func (s *MongoCommentStore) CreateComment(ctx context.Context, comment *model.Comment) error {
+	_, err := s.collection.InsertOne(ctx, comment)
+	return err
+}
+
+// GetComment finds one comment of the task inside the scope
+func (s *MongoCommentStore) GetComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID) (model.Comment, error) {
+	var comment model.Comment
+	err := s.collection.FindOne(ctx, commentFilter(scope, taskID, id)).Decode(&comment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Comment{}, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+// ListComments returns one page of the task's comments, oldest first, without their history
+func (s *MongoCommentStore) ListComments(ctx context.Context, scope Scope, taskID primitive.ObjectID, opts ListOptions) ([]model.Comment, int64, error) {
+	filter := scopeFilter(scope)
+	filter["task_id"] = taskID
+
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "metadata.created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(opts.Skip())).
+		SetLimit(int64(opts.Limit)).
+		SetProjection(bson.M{"history": 0})
+	cursor, err := s.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []model.Comment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// EditComment moves the current body into the history and sets the new one in a single
+// pipeline update, so two concurrent edits can't lose a revision
+func (s *MongoCommentStore) EditComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, body string, editedAt time.Time) (model.Comment, error) {
+	filter := commentFilter(scope, taskID, id)
+	filter["deleted_at"] = bson.M{"$exists": false}
+
+	revision := bson.M{
+		"body":       "$body",
+		"written_at": bson.M{"$ifNull": bson.A{"$edited_at", "$metadata.created_at"}},
+	}
+	update := bson.A{bson.M{"$set": bson.M{
+		"history":             bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$history", bson.A{}}}, bson.A{revision}}},
+		"body":                bson.M{"$literal": body}, // A body starting with "$" must not be read as a field path
+		"edited_at":           editedAt,
+		"metadata.updated_at": editedAt,
+	}}}
+	return s.updateComment(ctx, scope, taskID, id, filter, update)
+}
+
+// DeleteComment sets deleted_at on a comment that doesn't have it yet
+func (s *MongoCommentStore) DeleteComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, deletedAt time.Time) (model.Comment, error) {
+	filter := commentFilter(scope, taskID, id)
+	filter["deleted_at"] = bson.M{"$exists": false}
+
+	update := bson.M{"$set": bson.M{"deleted_at": deletedAt, "metadata.updated_at": deletedAt}}
+	return s.updateComment(ctx, scope, taskID, id, filter, update)
+}
+
+// SearchComments runs the query on the text index, best matches first
+func (s *MongoCommentStore) SearchComments(ctx context.Context, scope Scope, query TextQuery, limit int) ([]CommentMatch, error) {
+	filter := scopeFilter(scope)
+	filter["$text"] = bson.M{"$search": query.MongoSearch()}
+	filter["deleted_at"] = nil // Matches a missing field as well as null
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"task_id": 1, "body": 1, "score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID     primitive.ObjectID `bson:"_id"`
+		TaskID primitive.ObjectID `bson:"task_id"`
+		Body   string             `bson:"body"`
+		Score  float64            `bson:"score"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	matches := make([]CommentMatch, len(results))
+	for i, result := range results {
+		matches[i] = CommentMatch{TaskID: result.TaskID, CommentID: result.ID, Body: result.Body, Score: result.Score}
+	}
+	return matches, nil
+}
+
+// DeleteCommentsByTasks removes every comment of the tasks
+func (s *MongoCommentStore) DeleteCommentsByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+	result, err := s.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteCommentsByOwner removes every comment on ownerID's tasks
+func (s *MongoCommentStore) DeleteCommentsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// updateComment applies an update to a comment that isn't deleted and returns the new document
+func (s *MongoCommentStore) updateComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, filter bson.M, update interface{}) (model.Comment, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var comment model.Comment
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&comment)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return comment, err
+	}
+
+	// Tell "no such comment" apart from "the comment was deleted"
+	if _, err := s.GetComment(ctx, scope, taskID, id); err != nil {
+		return model.Comment{}, err
+	}
+	return model.Comment{}, ErrCommentDeleted
+}
+
+// commentFilter matches one comment of a task inside the scope
+func commentFilter(scope Scope, taskID, id primitive.ObjectID) bson.M {
+	filter := scopedByID(scope, id)
+	filter["task_id"] = taskID
+	return filter
+}
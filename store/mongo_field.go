@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoCustomFieldStore is the MongoDB implementation of CustomFieldStore
+type MongoCustomFieldStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCustomFieldStore returns the store and creates its indexes
+func NewMongoCustomFieldStore(ctx context.Context, client *mongo.Client) (*MongoCustomFieldStore, error) {
+	collection := client.Database("Go").Collection("custom_fields")
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "metadata.created_at", Value: 1}},
+			Options: options.Index().SetName("owner_created_at"),
+		},
+		{
+			// Two definitions of one owner can't share a key, even when created at the same time
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetName("owner_key").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoCustomFieldStore{collection: collection}, nil
+}
+
+// CreateCustomField inserts the definition document as-is
+func (s *MongoCustomFieldStore) CreateCustomField(ctx context.Context, field *model.CustomField) error {
+	_, err := s.collection.InsertOne(ctx, field)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrCustomFieldKeyTaken
+	}
+	return err
+}
+
+// GetCustomField finds one definition inside the scope
+func (s *MongoCustomFieldStore) GetCustomField(ctx context.Context, scope Scope, id primitive.ObjectID) (model.CustomField, error) {
+	var field model.CustomField
+	err := s.collection.FindOne(ctx, scopedByID(scope, id)).Decode(&field)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.CustomField{}, ErrCustomFieldNotFound
+	}
+	return field, err
+}
+
+// ListCustomFields returns the definitions in the scope, oldest first
+func (s *MongoCustomFieldStore) ListCustomFields(ctx context.Context, scope Scope) ([]model.CustomField, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "metadata.created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, scopeFilter(scope), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	fields := []model.CustomField{}
+	if err := cursor.All(ctx, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// UpdateCustomField sets the fields present on the update and returns the new document
+func (s *MongoCustomFieldStore) UpdateCustomField(ctx context.Context, scope Scope, id primitive.ObjectID, update CustomFieldUpdate) (model.CustomField, error) {
+	set := bson.M{"metadata.updated_at": time.Now()}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.Options != nil {
+		set["options"] = *update.Options
+	}
+	if update.Required != nil {
+		set["required"] = *update.Required
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var field model.CustomField
+	err := s.collection.FindOneAndUpdate(ctx, scopedByID(scope, id), bson.M{"$set": set}, opts).Decode(&field)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.CustomField{}, ErrCustomFieldNotFound
+	}
+	return field, err
+}
+
+// DeleteCustomField removes the definition and reports ErrCustomFieldNotFound when nothing was deleted
+func (s *MongoCustomFieldStore) DeleteCustomField(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, scopedByID(scope, id))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrCustomFieldNotFound
+	}
+	return nil
+}
+
+// DeleteCustomFieldsByOwner removes every definition of ownerID
+func (s *MongoCustomFieldStore) DeleteCustomFieldsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoProjectStore is the MongoDB implementation of ProjectStore
+type MongoProjectStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoProjectStore returns the store and creates its index
+func NewMongoProjectStore(ctx context.Context, client *mongo.Client) (*MongoProjectStore, error) {
+	collection := client.Database("Go").Collection("projects")
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "metadata.created_at", Value: 1}},
+		Options: options.Index().SetName("owner_created_at"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoProjectStore{collection: collection}, nil
+}
+
+// CreateProject inserts the project document as-is
+func (s *MongoProjectStore) CreateProject(ctx context.Context, project *model.Project) error {
+	_, err := s.collection.InsertOne(ctx, project)
+	return err
+}
+
+// GetProject finds one project inside the scope
+func (s *MongoProjectStore) GetProject(ctx context.Context, scope Scope, id primitive.ObjectID) (model.Project, error) {
+	var project model.Project
+	err := s.collection.FindOne(ctx, scopedByID(scope, id)).Decode(&project)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Project{}, ErrProjectNotFound
+	}
+	return project, err
+}
+
+// ListProjects returns the projects in the scope, oldest first
+func (s *MongoProjectStore) ListProjects(ctx context.Context, scope Scope, includeArchived bool) ([]model.Project, error) {
+	filter := scopeFilter(scope)
+	if !includeArchived {
+		filter["archived"] = false
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "metadata.created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	projects := []model.Project{}
+	if err := cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// UpdateProject sets the fields present on the update and returns the new document
+func (s *MongoProjectStore) UpdateProject(ctx context.Context, scope Scope, id primitive.ObjectID, update ProjectUpdate) (model.Project, error) {
+	set := bson.M{"metadata.updated_at": time.Now()}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.Color != nil {
+		set["color"] = *update.Color
+	}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.Archived != nil {
+		set["archived"] = *update.Archived
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var project model.Project
+	err := s.collection.FindOneAndUpdate(ctx, scopedByID(scope, id), bson.M{"$set": set}, opts).Decode(&project)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Project{}, ErrProjectNotFound
+	}
+	return project, err
+}
+
+// DeleteProject removes the project and reports ErrProjectNotFound when nothing was deleted
+func (s *MongoProjectStore) DeleteProject(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, scopedByID(scope, id))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// DeleteProjectsByOwner removes every project that belongs to ownerID
+func (s *MongoProjectStore) DeleteProjectsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoTimeEntryStore is the MongoDB implementation of TimeEntryStore
+type MongoTimeEntryStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoTimeEntryStore returns the store and creates its indexes
+func NewMongoTimeEntryStore(ctx context.Context, client *mongo.Client) (*MongoTimeEntryStore, error) {
+	collection := client.Database("Go").Collection("time_entries")
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// Serves the entries of a task, most recent first, and the task totals
+			Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "started_at", Value: -1}},
+			Options: options.Index().SetName("task_started_at"),
+		},
+		{
+			// Serves the report over one owner's tasks and DeleteTimeEntriesByOwner
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "started_at", Value: 1}},
+			Options: options.Index().SetName("owner_started_at"),
+		},
+		{
+			// At most one running timer per user, enforced by the database so two starts can't race
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().
+				SetName("user_running").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"running": true}),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoTimeEntryStore{collection: collection}, nil
+}
+
+// CreateTimeEntry inserts the entry document as-is
+func (s *MongoTimeEntryStore) CreateTimeEntry(ctx context.Context, entry *model.TimeEntry) error {
+	_, err := s.collection.InsertOne(ctx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrTimerRunning
+	}
+	return err
+}
+
+// RunningTimer finds the user's running timer through the user_running index
+func (s *MongoTimeEntryStore) RunningTimer(ctx context.Context, userID primitive.ObjectID) (model.TimeEntry, error) {
+	var entry model.TimeEntry
+	err := s.collection.FindOne(ctx, bson.M{"user_id": userID, "running": true}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	return entry, err
+}
+
+// StopTimer ends the running timer with a pipeline update, so the duration is computed from the stored start
+func (s *MongoTimeEntryStore) StopTimer(ctx context.Context, userID primitive.ObjectID, endedAt time.Time) (model.TimeEntry, error) {
+	elapsed := bson.M{"$floor": bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{endedAt, "$started_at"}}, 1000}}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"running":             false,
+		"ended_at":            endedAt,
+		"seconds":             bson.M{"$toLong": bson.M{"$max": bson.A{0, elapsed}}},
+		"metadata.updated_at": endedAt,
+	}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var entry model.TimeEntry
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"user_id": userID, "running": true}, update, opts).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	return entry, err
+}
+
+// ListTimeEntries returns one page of the task's entries, most recent first
+func (s *MongoTimeEntryStore) ListTimeEntries(ctx context.Context, scope Scope, taskID primitive.ObjectID, opts ListOptions) ([]model.TimeEntry, int64, error) {
+	filter := scopeFilter(scope)
+	filter["task_id"] = taskID
+
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(opts.Skip())).
+		SetLimit(int64(opts.Limit))
+	cursor, err := s.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []model.TimeEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// DeleteTimeEntry removes one entry of the task inside the scope
+func (s *MongoTimeEntryStore) DeleteTimeEntry(ctx context.Context, scope Scope, taskID, id primitive.ObjectID) error {
+	filter := scopedByID(scope, id)
+	filter["task_id"] = taskID
+
+	result, err := s.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrTimeEntryNotFound
+	}
+	return nil
+}
+
+// SumTimeByTask groups the matching stopped entries by task in the database
+func (s *MongoTimeEntryStore) SumTimeByTask(ctx context.Context, scope Scope, filter TimeFilter) ([]model.TaskTime, error) {
+	match := scopeFilter(scope)
+	match["running"] = false
+	if filter.TaskIDs != nil {
+		match["task_id"] = bson.M{"$in": filter.TaskIDs}
+	}
+	started := bson.M{}
+	if filter.From != nil {
+		started["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		started["$lt"] = *filter.To
+	}
+	if len(started) > 0 {
+		match["started_at"] = started
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$task_id",
+			"seconds": bson.M{"$sum": "$seconds"},
+			"entries": bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sums := []model.TaskTime{}
+	if err := cursor.All(ctx, &sums); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// DeleteTimeEntriesByTasks removes every entry of the tasks
+func (s *MongoTimeEntryStore) DeleteTimeEntriesByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+	result, err := s.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteTimeEntriesByOwner removes every entry on ownerID's tasks
+func (s *MongoTimeEntryStore) DeleteTimeEntriesByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
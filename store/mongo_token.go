@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoRefreshTokenStore is the MongoDB implementation of RefreshTokenStore
+type MongoRefreshTokenStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoRefreshTokenStore returns the store and creates its indexes
+// - token_hash is unique because it is how tokens are looked up
+// - the TTL index lets MongoDB delete expired tokens on its own
+func NewMongoRefreshTokenStore(ctx context.Context, client *mongo.Client) (*MongoRefreshTokenStore, error) {
+	collection := client.Database("Go").Collection("refresh_tokens")
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("token_hash_unique"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl"),
+		},
+		{
+			Keys:    bson.D{{Key: "family_id", Value: 1}},
+			Options: options.Index().SetName("family_id"),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoRefreshTokenStore{collection: collection}, nil
+}
+
+// CreateRefreshToken inserts a new token record
+func (s *MongoRefreshTokenStore) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	_, err := s.collection.InsertOne(ctx, token)
+	return err
+}
+
+// GetRefreshToken finds a token by its hash
+func (s *MongoRefreshTokenStore) GetRefreshToken(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := s.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.RefreshToken{}, ErrTokenNotFound
+	}
+	return token, err
+}
+
+// RevokeRefreshToken sets revoked_at only if it isn't set yet
+// Putting the condition in the filter makes the check and the write one atomic step
+func (s *MongoRefreshTokenStore) RevokeRefreshToken(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	result, err := s.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// RevokeRefreshFamily revokes every still active token of a login session
+func (s *MongoRefreshTokenStore) RevokeRefreshFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	filter := bson.M{"family_id": familyID, "revoked_at": bson.M{"$exists": false}}
+	_, err := s.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoUserStore is the MongoDB implementation of UserStore
+// Users live in the "users" collection next to the tasks
+type MongoUserStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoUserStore returns a UserStore and makes sure the unique email index exists
+// CreateIndexes is idempotent, so running it on every startup is safe
+func NewMongoUserStore(ctx context.Context, client *mongo.Client) (*MongoUserStore, error) {
+	collection := client.Database("Go").Collection("users")
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("email_unique"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoUserStore{collection: collection}, nil
+}
+
+// CreateUser inserts the user; the unique index rejects duplicate emails
+func (s *MongoUserStore) CreateUser(ctx context.Context, user *model.User) error {
+	_, err := s.collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// GetUserByID finds a user by ObjectID
+func (s *MongoUserStore) GetUserByID(ctx context.Context, id primitive.ObjectID) (model.User, error) {
+	return s.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetUserByEmail finds a user by their (already normalized) email
+func (s *MongoUserStore) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	return s.findOne(ctx, bson.M{"email": email})
+}
+
+// ListUsers returns one page of users in sign-up order
+func (s *MongoUserStore) ListUsers(ctx context.Context, opts ListOptions) ([]model.User, int64, error) {
+	total, err := s.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "metadata.created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(opts.Skip())).
+		SetLimit(int64(opts.Limit))
+
+	cursor, err := s.collection.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []model.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// UpdateUserRole sets the role and returns the user after the change
+func (s *MongoUserStore) UpdateUserRole(ctx context.Context, id primitive.ObjectID, role model.Role) (model.User, error) {
+	update := bson.M{"$set": bson.M{"role": role, "metadata.updated_at": time.Now()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user model.User
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.User{}, ErrUserNotFound
+	}
+	return user, err
+}
+
+// DeleteUser removes the user document
+func (s *MongoUserStore) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// findOne decodes the first user matching filter
+func (s *MongoUserStore) findOne(ctx context.Context, filter bson.M) (model.User, error) {
+	var user model.User
+	err := s.collection.FindOne(ctx, filter).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.User{}, ErrUserNotFound
+	}
+	return user, err
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sortFixture is six tasks with plenty of ties; t<n> has the n-th smallest ID
+// Its tables below were worked out by hand, so every store must agree with them, not just with each other
+//
+//	task  priority  due_at  title  created  custom field sev
+//	t1    high      day 2   b      T0       2
+//	t2    low       -       a      T0       -
+//	t3    medium    day 1   c      T1       1
+//	t4    high      -       b      T1       -
+//	t5    high      day 2   a      T2       2
+//	t6    low       day 1   d      T2       -
+func sortFixture() []model.Task {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := func(n int) *time.Time {
+		t := base.AddDate(0, 0, n)
+		return &t
+	}
+	rows := []struct {
+		priority model.Priority
+		due      *time.Time
+		title    string
+		created  int
+		sev      interface{}
+	}{
+		{model.PriorityHigh, day(2), "b", 0, 2.0},
+		{model.PriorityLow, nil, "a", 0, nil},
+		{model.PriorityMedium, day(1), "c", 1, 1.0},
+		{model.PriorityHigh, nil, "b", 1, nil},
+		{model.PriorityHigh, day(2), "a", 2, 2.0},
+		{model.PriorityLow, day(1), "d", 2, nil},
+	}
+
+	owner := primitive.NewObjectID()
+	tasks := make([]model.Task, len(rows))
+	for i, row := range rows {
+		id, _ := primitive.ObjectIDFromHex(fmt.Sprintf("%024x", i+1))
+		created := base.Add(time.Duration(row.created) * time.Hour)
+		tasks[i] = model.Task{
+			ID: id, OwnerID: owner, Title: row.title, Description: "d", Priority: row.priority,
+			Tags: []string{"t"}, Image: []string{}, Status: model.StatusTodo, DueAt: row.due,
+			Metadata: model.Metadata{CreatedAt: created, UpdatedAt: created},
+		}
+		if row.sev != nil {
+			tasks[i].Fields = model.FieldValues{"sev": row.sev}
+		}
+	}
+	return tasks
+}
+
+// sortCases lists sorts with the order of sortFixture's tasks they must produce
+var sortCases = []struct {
+	name string
+	sort TaskSort
+	want []string
+}{
+	{"default newest first, ties on _id", nil,
+		[]string{"t6", "t5", "t4", "t3", "t2", "t1"}},
+	{"priority rank desc then due asc with nulls first", TaskSort{{Field: "priority", Desc: true}, {Field: "due_at"}},
+		[]string{"t4", "t5", "t1", "t3", "t2", "t6"}},
+	{"priority rank asc", TaskSort{{Field: "priority"}},
+		[]string{"t6", "t2", "t3", "t5", "t4", "t1"}},
+	{"due desc with nulls last then title", TaskSort{{Field: "due_at", Desc: true}, {Field: "title"}},
+		[]string{"t5", "t1", "t3", "t6", "t2", "t4"}},
+	{"title then created desc", TaskSort{{Field: "title"}, {Field: "created_at", Desc: true}},
+		[]string{"t5", "t2", "t4", "t1", "t3", "t6"}},
+	{"custom field desc with missing last", TaskSort{{Field: "sev", Custom: true, Desc: true}},
+		[]string{"t5", "t1", "t3", "t6", "t4", "t2"}},
+}
+
+func TestListSortAndKeysetPaging(t *testing.T) {
+	ctx := context.Background()
+	for backend, stores := range testStores(t) {
+		tasks := stores.Tasks
+		fixture := sortFixture()
+		createOwner(t, stores, fixture[0].OwnerID)
+		for i := range fixture {
+			if err := tasks.Create(ctx, &fixture[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		scope := OwnedBy(fixture[0].OwnerID)
+		names := map[primitive.ObjectID]string{}
+		byName := map[string]model.Task{}
+		for i, task := range fixture {
+			names[task.ID] = fmt.Sprintf("t%d", i+1)
+			byName[names[task.ID]] = task
+		}
+		list := func(t *testing.T, opts ListOptions) []string {
+			t.Helper()
+			page, total, err := tasks.List(ctx, scope, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != int64(len(fixture)) {
+				t.Errorf("total = %d, want %d", total, len(fixture))
+			}
+			out := []string{}
+			for _, task := range page {
+				out = append(out, names[task.ID])
+			}
+			return out
+		}
+
+		for _, tc := range sortCases {
+			t.Run(backend+"/"+tc.name, func(t *testing.T) {
+				keys := tc.sort.OrDefault()
+				if got := list(t, ListOptions{Page: 1, Limit: 100, Sort: tc.sort}); !slices.Equal(got, tc.want) {
+					t.Fatalf("order = %v, want %v", got, tc.want)
+				}
+				if got := list(t, ListOptions{Page: 2, Limit: 4, Sort: tc.sort}); !slices.Equal(got, tc.want[4:]) {
+					t.Errorf("offset page 2 = %v, want %v", got, tc.want[4:])
+				}
+
+				// Walk forward two at a time, each page starting after the last task of the one before
+				var pages [][]string
+				page := list(t, ListOptions{Page: 1, Limit: 2, Sort: tc.sort})
+				for len(page) > 0 {
+					pages = append(pages, page)
+					from := PositionOf(keys, byName[page[len(page)-1]])
+					page = list(t, ListOptions{Limit: 2, Sort: tc.sort, From: &from})
+				}
+				if got := slices.Concat(pages...); !slices.Equal(got, tc.want) {
+					t.Fatalf("forward walk = %v, want %v", got, tc.want)
+				}
+
+				// Going back from every page must land on the page before it
+				for i := len(pages) - 1; i > 0; i-- {
+					from := PositionOf(keys, byName[pages[i][0]])
+					from.Before = true
+					if got := list(t, ListOptions{Limit: 2, Sort: tc.sort, From: &from}); !slices.Equal(got, pages[i-1]) {
+						t.Errorf("prev of %v = %v, want %v", pages[i], got, pages[i-1])
+					}
+				}
+				from := PositionOf(keys, byName[pages[0][0]])
+				from.Before = true
+				if got := list(t, ListOptions{Limit: 2, Sort: tc.sort, From: &from}); len(got) != 0 {
+					t.Errorf("prev of the first page = %v, want nothing", got)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,1335 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	_ "modernc.org/sqlite" // Registers the pure Go "sqlite" driver with database/sql
+)
+
+// SQLiteTaskStore keeps tasks in a single SQLite database file
+// It is meant for small deployments that don't want to run MongoDB
+type SQLiteTaskStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens (or creates) the database file at path, runs migrations
+// and returns every store backed by that one file
+func OpenSQLite(path string) (Stores, error) {
+	// foreign_keys makes ON DELETE CASCADE work, busy_timeout waits for locks instead of failing
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return Stores{}, err
+	}
+
+	// SQLite allows a single writer, so one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := migrateSQLite(ctx, db); err != nil {
+		db.Close()
+		return Stores{}, err
+	}
+
+	return Stores{
+		Tasks:         &SQLiteTaskStore{db: db},
+		Users:         &SQLiteUserStore{db: db},
+		RefreshTokens: &SQLiteRefreshTokenStore{db: db},
+		APIKeys:       &SQLiteAPIKeyStore{db: db},
+		Projects:      &SQLiteProjectStore{db: db},
+		Comments:      &SQLiteCommentStore{db: db},
+		TimeEntries:   &SQLiteTimeEntryStore{db: db},
+		CustomFields:  &SQLiteCustomFieldStore{db: db},
+		Close:         db.Close,
+	}, nil
+}
+
+// Create inserts the task row together with its tags, images and checklist
+func (s *SQLiteTaskStore) Create(ctx context.Context, task *model.Task) error {
+	recurrence, err := jsonOrNull(task.Recurrence)
+	if err != nil {
+		return err
+	}
+	statusSince, err := statusTimesOrNull(task.StatusSince)
+	if err != nil {
+		return err
+	}
+	fields, err := fieldValuesOrNull(task.Fields)
+	if err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tasks (id, owner_id, parent_id, project_id, title, description, priority, completed, status,
+				status_entered_at, rank, start_at, due_at, recurrence, points, estimate_minutes, custom_fields,
+				created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			task.ID.Hex(), task.OwnerID.Hex(), hexOrNull(task.ParentID), hexOrNull(task.ProjectID), task.Title,
+			task.Description, string(task.Priority), task.Completed, string(task.EffectiveStatus()), statusSince,
+			task.Rank, nanosOrNull(task.StartAt), nanosOrNull(task.DueAt), recurrence, task.Points, task.Estimate,
+			fields, task.Metadata.CreatedAt.UnixNano(), task.Metadata.UpdatedAt.UnixNano())
+		if err != nil {
+			return err
+		}
+		if err := writeTaskLists(ctx, tx, *task); err != nil {
+			return err
+		}
+		// Only the next occurrence of a recurring task starts with a checklist
+		for position, item := range task.Checklist {
+			if err := insertChecklistItem(ctx, tx, task.ID, item, position); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get loads one task by ID
+func (s *SQLiteTaskStore) Get(ctx context.Context, scope Scope, id primitive.ObjectID) (model.Task, error) {
+	where, args := scopedByIDClause(scope, id)
+	tasks, err := s.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks WHERE `+where, args...)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if len(tasks) == 0 {
+		return model.Task{}, ErrNotFound
+	}
+	return tasks[0], nil
+}
+
+// List returns one page of the caller's matching tasks, in the requested order, and their total count
+func (s *SQLiteTaskStore) List(ctx context.Context, scope Scope, opts ListOptions) ([]model.Task, int64, error) {
+	where, args := scopeClause(scope)
+	filterWhere, filterArgs := filterClause(opts.Filter)
+	where += filterWhere
+	args = append(args, filterArgs...)
+
+	var total int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	keys := opts.Sort.OrDefault()
+	offset := opts.Skip()
+	if opts.From != nil {
+		positionWhere, positionArgs := positionClause(keys, *opts.From)
+		where += positionWhere
+		args = append(args, positionArgs...)
+		offset = 0
+	}
+	order, orderArgs := orderClause(keys, opts.From != nil && opts.From.Before)
+	args = append(args, orderArgs...)
+
+	tasks, err := s.queryTasks(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE `+where+` ORDER BY `+order+` LIMIT ? OFFSET ?`,
+		append(args, opts.Limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if opts.From != nil && opts.From.Before {
+		slices.Reverse(tasks)
+	}
+	return tasks, total, nil
+}
+
+// Update loads the task, applies the patch in Go and writes it back in one transaction
+func (s *SQLiteTaskStore) Update(ctx context.Context, scope Scope, id primitive.ObjectID, update TaskUpdate) (model.Task, error) {
+	var task model.Task
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		where, args := scopedByIDClause(scope, id)
+		tasks, err := queryTasks(ctx, tx, `SELECT `+taskColumns+` FROM tasks WHERE `+where, args...)
+		if err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			return ErrNotFound
+		}
+
+		task = tasks[0]
+		// The transaction holds the write lock, so nobody can move the task between this check and the UPDATE
+		if update.Transition != nil && task.EffectiveStatus() != update.Transition.From {
+			return ErrStatusConflict
+		}
+		update.Apply(&task)
+		task.Metadata.UpdatedAt = time.Now()
+
+		recurrence, err := jsonOrNull(task.Recurrence)
+		if err != nil {
+			return err
+		}
+		statusSince, err := statusTimesOrNull(task.StatusSince)
+		if err != nil {
+			return err
+		}
+		fields, err := fieldValuesOrNull(task.Fields)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE tasks
+			SET parent_id = ?, project_id = ?, title = ?, description = ?, priority = ?, completed = ?, status = ?,
+				status_entered_at = ?, rank = ?, start_at = ?, due_at = ?, recurrence = ?, points = ?,
+				estimate_minutes = ?, custom_fields = ?, updated_at = ?
+			WHERE id = ?`,
+			hexOrNull(task.ParentID), hexOrNull(task.ProjectID), task.Title, task.Description, string(task.Priority),
+			task.Completed, string(task.EffectiveStatus()), statusSince, task.Rank, nanosOrNull(task.StartAt),
+			nanosOrNull(task.DueAt), recurrence, task.Points, task.Estimate, fields, task.Metadata.UpdatedAt.UnixNano(),
+			task.ID.Hex())
+		if err != nil {
+			return err
+		}
+
+		// Only rewrite the child tables when the patch actually touched them
+		if update.Tags != nil || update.Image != nil {
+			return writeTaskLists(ctx, tx, task)
+		}
+		return nil
+	})
+	return task, err
+}
+
+// Delete removes the task; tags and images go with it thanks to ON DELETE CASCADE
+func (s *SQLiteTaskStore) Delete(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	where, args := scopedByIDClause(scope, id)
+	result, err := s.db.ExecContext(ctx, `DELETE FROM tasks WHERE `+where, args...)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// AssignOwnerless sets owner_id on the rows created before tasks had owners
+func (s *SQLiteTaskStore) AssignOwnerless(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `UPDATE tasks SET owner_id = ? WHERE owner_id IS NULL`, ownerID.Hex())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteByOwner removes every task of one owner; tags and images cascade
+func (s *SQLiteTaskStore) DeleteByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM tasks WHERE owner_id = ?`, ownerID.Hex())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListChildren returns the direct subtasks of parentID, oldest first
+func (s *SQLiteTaskStore) ListChildren(ctx context.Context, scope Scope, parentID primitive.ObjectID) ([]model.Task, error) {
+	where, args := scopeClause(scope)
+	return s.queryTasks(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE parent_id = ? AND `+where+` ORDER BY created_at, id`,
+		append([]interface{}{parentID.Hex()}, args...)...)
+}
+
+// DetachChildren sets parent_id to NULL on the direct subtasks of parentID
+func (s *SQLiteTaskStore) DetachChildren(ctx context.Context, scope Scope, parentID primitive.ObjectID) (int64, error) {
+	where, args := scopeClause(scope)
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE tasks SET parent_id = NULL, updated_at = ? WHERE parent_id = ? AND `+where,
+		append([]interface{}{time.Now().UnixNano(), parentID.Hex()}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteTree deletes the task and all of its subtasks in one statement
+// The recursive CTE starts from the task (inside the scope) and keeps joining
+// children onto the rows it already found until no new rows turn up
+func (s *SQLiteTaskStore) DeleteTree(ctx context.Context, scope Scope, id primitive.ObjectID) (int64, error) {
+	where, args := scopedByIDClause(scope, id)
+	result, err := s.db.ExecContext(ctx, `
+		WITH RECURSIVE tree (id) AS (
+			SELECT id FROM tasks WHERE `+where+`
+			UNION
+			SELECT tasks.id FROM tasks JOIN tree ON tasks.parent_id = tree.id
+		)
+		DELETE FROM tasks WHERE id IN (SELECT id FROM tree)`, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if deleted == 0 {
+		return 0, ErrNotFound
+	}
+	return deleted, nil
+}
+
+// GetMany loads the requested tasks with one IN (...) query
+func (s *SQLiteTaskStore) GetMany(ctx context.Context, scope Scope, ids []primitive.ObjectID) ([]model.Task, error) {
+	if len(ids) == 0 {
+		return []model.Task{}, nil
+	}
+
+	where, args := scopeClause(scope)
+	idArgs := make([]interface{}, len(ids))
+	for i, id := range ids {
+		idArgs[i] = id.Hex()
+	}
+	return s.queryTasks(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE id IN (`+placeholders(len(ids))+`) AND `+where,
+		append(idArgs, args...)...)
+}
+
+// AddDependency inserts the edge; INSERT OR IGNORE makes adding it twice harmless
+// The cycle check is a recursive query inside the same transaction, after the UPDATE has taken the write lock
+func (s *SQLiteTaskStore) AddDependency(ctx context.Context, scope Scope, id, blockerID primitive.ObjectID) (model.Task, error) {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		where, args := scopedByIDClause(scope, id)
+		result, err := tx.ExecContext(ctx, `UPDATE tasks SET updated_at = ? WHERE `+where,
+			append([]interface{}{time.Now().UnixNano()}, args...)...)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+
+		// upstream holds every task that blocks blockerID, at any depth
+		cycle := id == blockerID
+		if !cycle {
+			err = tx.QueryRowContext(ctx, `
+				WITH RECURSIVE upstream(id) AS (
+					SELECT blocker_id FROM task_dependencies WHERE task_id = ?
+					UNION
+					SELECT d.blocker_id FROM task_dependencies d JOIN upstream u ON d.task_id = u.id
+				)
+				SELECT EXISTS (SELECT 1 FROM upstream WHERE id = ?)`,
+				blockerID.Hex(), id.Hex()).Scan(&cycle)
+			if err != nil {
+				return err
+			}
+		}
+		if cycle {
+			return ErrDependencyCycle // Rolls back the updated_at change too
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO task_dependencies (task_id, blocker_id, created_at) VALUES (?, ?, ?)`,
+			id.Hex(), blockerID.Hex(), time.Now().UnixNano())
+		return err
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// RemoveDependency deletes the edge row
+func (s *SQLiteTaskStore) RemoveDependency(ctx context.Context, scope Scope, id, blockerID primitive.ObjectID) (model.Task, error) {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		where, args := scopedByIDClause(scope, id)
+		result, err := tx.ExecContext(ctx, `UPDATE tasks SET updated_at = ? WHERE `+where,
+			append([]interface{}{time.Now().UnixNano()}, args...)...)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+
+		result, err = tx.ExecContext(ctx,
+			`DELETE FROM task_dependencies WHERE task_id = ? AND blocker_id = ?`, id.Hex(), blockerID.Hex())
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrDependencyNotFound // Rolls back the updated_at change too
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// AddAttachment inserts the attachment row and touches the task in one transaction
+func (s *SQLiteTaskStore) AddAttachment(ctx context.Context, scope Scope, id primitive.ObjectID, attachment model.Attachment) (model.Task, error) {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		where, args := scopedByIDClause(scope, id)
+		result, err := tx.ExecContext(ctx, `UPDATE tasks SET updated_at = ? WHERE `+where,
+			append([]interface{}{time.Now().UnixNano()}, args...)...)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO task_attachments (id, task_id, file_name, content_type, size, width, height, blob_key,
+				thumbnail_key, uploaded_by, uploaded_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			attachment.ID.Hex(), id.Hex(), attachment.FileName, attachment.ContentType, attachment.Size,
+			attachment.Width, attachment.Height, attachment.Key, attachment.ThumbnailKey,
+			attachment.UploadedBy.Hex(), attachment.UploadedAt.UnixNano())
+		return err
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// RemoveAttachment deletes the attachment row
+func (s *SQLiteTaskStore) RemoveAttachment(ctx context.Context, scope Scope, id, attachmentID primitive.ObjectID) (model.Task, error) {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		where, args := scopedByIDClause(scope, id)
+		result, err := tx.ExecContext(ctx, `UPDATE tasks SET updated_at = ? WHERE `+where,
+			append([]interface{}{time.Now().UnixNano()}, args...)...)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+
+		result, err = tx.ExecContext(ctx,
+			`DELETE FROM task_attachments WHERE id = ? AND task_id = ?`, attachmentID.Hex(), id.Hex())
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrAttachmentNotFound // Rolls back the updated_at change too
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// AddChecklistItem shifts the items at and after position down one place and inserts the new item there
+func (s *SQLiteTaskStore) AddChecklistItem(ctx context.Context, scope Scope, id primitive.ObjectID, item model.ChecklistItem, position int) (model.Task, error) {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := touchTask(ctx, tx, scope, id); err != nil {
+			return err
+		}
+
+		var count int
+		err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM task_checklist WHERE task_id = ?`, id.Hex()).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if position < 0 || position > count {
+			position = count
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`UPDATE task_checklist SET position = position + 1 WHERE task_id = ? AND position >= ?`, id.Hex(), position)
+		if err != nil {
+			return err
+		}
+		return insertChecklistItem(ctx, tx, id, item, position)
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// UpdateChecklistItem changes one item row; COALESCE keeps the fields that weren't sent
+func (s *SQLiteTaskStore) UpdateChecklistItem(ctx context.Context, scope Scope, id, itemID primitive.ObjectID, update ChecklistItemUpdate) (model.Task, error) {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := touchTask(ctx, tx, scope, id); err != nil {
+			return err
+		}
+
+		// Checking an item records when, unchecking it clears that; not sending done leaves both alone
+		var doneAt interface{}
+		if update.Done != nil && *update.Done {
+			doneAt = time.Now().UnixNano()
+		}
+		result, err := tx.ExecContext(ctx, `
+			UPDATE task_checklist
+			SET text = COALESCE(?, text), done = COALESCE(?, done),
+				done_at = CASE WHEN ? IS NULL THEN done_at ELSE ? END
+			WHERE id = ? AND task_id = ?`,
+			update.Text, update.Done, update.Done, doneAt, itemID.Hex(), id.Hex())
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrChecklistItemNotFound // Rolls back the updated_at change too
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// RemoveChecklistItem deletes the item row and closes the gap it leaves in the positions
+func (s *SQLiteTaskStore) RemoveChecklistItem(ctx context.Context, scope Scope, id, itemID primitive.ObjectID) (model.Task, error) {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := touchTask(ctx, tx, scope, id); err != nil {
+			return err
+		}
+
+		var position int
+		err := tx.QueryRowContext(ctx, `SELECT position FROM task_checklist WHERE id = ? AND task_id = ?`,
+			itemID.Hex(), id.Hex()).Scan(&position)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrChecklistItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_checklist WHERE id = ?`, itemID.Hex()); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE task_checklist SET position = position - 1 WHERE task_id = ? AND position > ?`, id.Hex(), position)
+		return err
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// ReorderChecklist checks itemIDs names exactly the stored items, then renumbers them in that order
+func (s *SQLiteTaskStore) ReorderChecklist(ctx context.Context, scope Scope, id primitive.ObjectID, itemIDs []primitive.ObjectID) (model.Task, error) {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := touchTask(ctx, tx, scope, id); err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM task_checklist WHERE task_id = ?`, id.Hex())
+		if err != nil {
+			return err
+		}
+		stored := map[string]bool{}
+		for rows.Next() {
+			var itemID string
+			if err := rows.Scan(&itemID); err != nil {
+				rows.Close()
+				return err
+			}
+			stored[itemID] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(itemIDs) != len(stored) {
+			return ErrChecklistMismatch
+		}
+		for position, itemID := range itemIDs {
+			if !stored[itemID.Hex()] {
+				return ErrChecklistMismatch
+			}
+			delete(stored, itemID.Hex()) // A repeated ID then fails the check above
+			_, err := tx.ExecContext(ctx, `UPDATE task_checklist SET position = ? WHERE id = ?`, position, itemID.Hex())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// CountByProject counts open and completed tasks per project with one GROUP BY query
+func (s *SQLiteTaskStore) CountByProject(ctx context.Context, scope Scope, projectIDs []primitive.ObjectID) (map[primitive.ObjectID]model.ProjectCounts, error) {
+	counts := make(map[primitive.ObjectID]model.ProjectCounts)
+	if len(projectIDs) == 0 {
+		return counts, nil
+	}
+
+	where, args := scopeClause(scope)
+	idArgs := make([]interface{}, len(projectIDs))
+	for i, id := range projectIDs {
+		idArgs[i] = id.Hex()
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT project_id, SUM(completed = 0), SUM(completed = 1)
+		FROM tasks
+		WHERE project_id IN (`+placeholders(len(projectIDs))+`) AND `+where+`
+		GROUP BY project_id`,
+		append(idArgs, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			projectID string
+			count     model.ProjectCounts
+		)
+		if err := rows.Scan(&projectID, &count.Open, &count.Completed); err != nil {
+			return nil, err
+		}
+		id, err := primitive.ObjectIDFromHex(projectID)
+		if err != nil {
+			return nil, err
+		}
+		counts[id] = count
+	}
+	return counts, rows.Err()
+}
+
+// DetachProject sets project_id to NULL on every task in projectID
+func (s *SQLiteTaskStore) DetachProject(ctx context.Context, scope Scope, projectID primitive.ObjectID) (int64, error) {
+	where, args := scopeClause(scope)
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE tasks SET project_id = NULL, updated_at = ? WHERE project_id = ? AND `+where,
+		append([]interface{}{time.Now().UnixNano(), projectID.Hex()}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SearchTasks runs the query on the tasks_fts index, best matches first
+// bm25 ranks better matches lower, so its sign is flipped to make higher scores better
+func (s *SQLiteTaskStore) SearchTasks(ctx context.Context, scope Scope, query TextQuery, limit int) ([]TextMatch, error) {
+	where, args := scopeClause(scope)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tasks.id, -bm25(tasks_fts, 0, ?, ?) AS score
+		FROM tasks_fts JOIN tasks ON tasks.id = tasks_fts.id
+		WHERE tasks_fts MATCH ? AND `+where+`
+		ORDER BY score DESC, tasks.id DESC
+		LIMIT ?`,
+		append(append([]interface{}{titleWeight, descriptionWeight, query.FTSMatch()}, args...), limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []TextMatch
+	for rows.Next() {
+		var (
+			id    string
+			match TextMatch
+		)
+		if err := rows.Scan(&id, &match.Score); err != nil {
+			return nil, err
+		}
+		if match.TaskID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// ClearCustomField removes one key from the custom_fields of every task that has it
+// The column goes back to NULL when the last value is removed, as if it was never set
+func (s *SQLiteTaskStore) ClearCustomField(ctx context.Context, scope Scope, key string) (int64, error) {
+	where, args := scopeClause(scope)
+	path := fieldPath(key)
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE tasks
+		SET custom_fields = NULLIF(json_remove(custom_fields, ?), '{}'), updated_at = ?
+		WHERE json_type(custom_fields, ?) IS NOT NULL AND `+where,
+		append([]interface{}{path, time.Now().UnixNano(), path}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scopeClause turns a Scope into a SQL condition plus its arguments
+func scopeClause(scope Scope) (string, []interface{}) {
+	if scope.AllOwners {
+		return "1 = 1", nil
+	}
+	return "owner_id = ?", []interface{}{scope.OwnerID.Hex()}
+}
+
+// sqliteSortColumns maps SortFields to the expressions they sort on
+var sqliteSortColumns = map[string]string{
+	"created_at":       "created_at",
+	"updated_at":       "updated_at",
+	"start_at":         "start_at",
+	"due_at":           "due_at",
+	"priority":         priorityRankSQL(),
+	"title":            "title",
+	"points":           "points",
+	"estimate_minutes": "estimate_minutes",
+}
+
+// sortColumn returns the expression a key sorts on and the arguments it needs
+func sortColumn(key SortKey) (string, []interface{}) {
+	if key.Custom {
+		return "json_extract(custom_fields, ?)", []interface{}{fieldPath(key.Field)}
+	}
+	return sqliteSortColumns[key.Field], nil
+}
+
+// orderClause turns keys into an ORDER BY list that ends on the ID, newest first
+// reverse flips every direction, for reading the tasks before a position nearest first
+// SQLite puts NULLs first in ascending order and last in descending order, as TaskSort wants
+func orderClause(keys TaskSort, reverse bool) (string, []interface{}) {
+	var order []string
+	var args []interface{}
+	for _, key := range keys {
+		column, columnArgs := sortColumn(key)
+		if key.Desc != reverse {
+			column += " DESC"
+		}
+		order = append(order, column)
+		args = append(args, columnArgs...)
+	}
+	if reverse {
+		return strings.Join(append(order, "id"), ", "), args
+	}
+	return strings.Join(append(order, "id DESC"), ", "), args
+}
+
+// positionClause keeps the tasks that come after pos in keys order, or before it when pos.Before is set
+// For keys (a, b) that is: a beyond pos.a, or a equal and b beyond pos.b, or both equal and the ID beyond
+func positionClause(keys TaskSort, pos Position) (string, []interface{}) {
+	var alternatives []string
+	var args, equalArgs []interface{}
+	var equal []string
+	for i := 0; i <= len(keys); i++ {
+		column, columnArgs, desc, value := "id", []interface{}(nil), true, interface{}(pos.ID.Hex())
+		if i < len(keys) {
+			column, columnArgs = sortColumn(keys[i])
+			desc, value = keys[i].Desc, sqliteSortArg(pos.Values[i])
+		}
+		if pos.Before {
+			desc = !desc
+		}
+
+		// NULLs sort below every value: nothing is beyond NULL in descending order
+		beyond, beyondArgs := "", columnArgs
+		switch {
+		case value == nil && !desc:
+			beyond = column + " IS NOT NULL"
+		case value != nil && !desc:
+			beyond, beyondArgs = column+" > ?", append(columnArgs, value)
+		case value != nil && desc:
+			beyond, beyondArgs = "("+column+" < ? OR "+column+" IS NULL)", append(append(columnArgs, value), columnArgs...)
+		}
+		if beyond != "" {
+			alternatives = append(alternatives, "("+strings.Join(append(append([]string{}, equal...), beyond), " AND ")+")")
+			args = append(append(args, equalArgs...), beyondArgs...)
+		}
+
+		if value == nil {
+			equal = append(equal, column+" IS NULL")
+			equalArgs = append(equalArgs, columnArgs...)
+		} else {
+			equal = append(equal, column+" = ?")
+			equalArgs = append(append(equalArgs, columnArgs...), value)
+		}
+	}
+	return " AND (" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// sqliteSortArg converts a SortKey value to what the column holds: times are stored as nanoseconds, booleans as 0 or 1
+func sqliteSortArg(value interface{}) interface{} {
+	switch value := value.(type) {
+	case time.Time:
+		return value.UnixNano()
+	case bool:
+		if value {
+			return 1
+		}
+		return 0
+	}
+	return value
+}
+
+// priorityRankSQL is a CASE expression for Priority.Rank, NULL for an unknown priority
+func priorityRankSQL() string {
+	var b strings.Builder
+	b.WriteString("CASE priority")
+	for rank, priority := range model.Priorities {
+		fmt.Fprintf(&b, " WHEN '%s' THEN %d", priority, rank)
+	}
+	b.WriteString(" END")
+	return b.String()
+}
+
+// filterClause turns a TaskFilter into extra " AND ..." conditions plus their arguments
+// Comparisons against a NULL due_at are never true, so undated tasks drop out of the due filters
+func filterClause(f TaskFilter) (string, []interface{}) {
+	var (
+		where strings.Builder
+		args  []interface{}
+	)
+	if f.DueBefore != nil {
+		where.WriteString(" AND due_at < ?")
+		args = append(args, f.DueBefore.UnixNano())
+	}
+	if f.DueAfter != nil {
+		where.WriteString(" AND due_at > ?")
+		args = append(args, f.DueAfter.UnixNano())
+	}
+	if f.Overdue != nil {
+		overdue := "(completed = 0 AND due_at IS NOT NULL AND due_at < ?)"
+		if !*f.Overdue {
+			overdue = "NOT " + overdue
+		}
+		where.WriteString(" AND " + overdue)
+		args = append(args, f.Now.UnixNano())
+	}
+	if f.ProjectID != nil {
+		where.WriteString(" AND project_id = ?")
+		args = append(args, f.ProjectID.Hex())
+	}
+	if f.NoProject {
+		where.WriteString(" AND project_id IS NULL")
+	}
+	if f.Status != nil {
+		where.WriteString(" AND status = ?")
+		args = append(args, string(*f.Status))
+	}
+	if len(f.Tags) > 0 {
+		// With all tags, every one of them must have its own row; the handlers send each tag once
+		in := placeholders(len(f.Tags))
+		if f.AllTags {
+			where.WriteString(" AND (SELECT COUNT(DISTINCT tag) FROM task_tags WHERE task_id = tasks.id AND tag IN (" + in + ")) = ?")
+		} else {
+			where.WriteString(" AND EXISTS (SELECT 1 FROM task_tags WHERE task_id = tasks.id AND tag IN (" + in + "))")
+		}
+		for _, tag := range f.Tags {
+			args = append(args, tag)
+		}
+		if f.AllTags {
+			args = append(args, len(f.Tags))
+		}
+	}
+	if len(f.Priorities) > 0 {
+		where.WriteString(" AND priority IN (" + placeholders(len(f.Priorities)) + ")")
+		for _, priority := range f.Priorities {
+			args = append(args, string(priority))
+		}
+	}
+	if f.Completed != nil {
+		where.WriteString(" AND completed = ?")
+		args = append(args, *f.Completed)
+	}
+	if f.Title != "" {
+		// instr matches the text literally, unlike LIKE where % and _ would need escaping
+		where.WriteString(" AND instr(lower(title), lower(?)) > 0")
+		args = append(args, f.Title)
+	}
+	for _, bound := range []struct {
+		clause string
+		at     *time.Time
+	}{
+		{" AND created_at < ?", f.CreatedBefore},
+		{" AND created_at > ?", f.CreatedAfter},
+		{" AND updated_at < ?", f.UpdatedBefore},
+		{" AND updated_at > ?", f.UpdatedAfter},
+	} {
+		if bound.at != nil {
+			where.WriteString(bound.clause)
+			args = append(args, bound.at.UnixNano())
+		}
+	}
+	for _, condition := range f.Fields {
+		clause, conditionArgs := fieldClause(condition)
+		where.WriteString(" AND " + clause)
+		args = append(args, conditionArgs...)
+	}
+	return where.String(), args
+}
+
+// fieldClause turns one custom field condition into SQL
+// json_each yields the items of a list and a plain value as a single row, so equality covers multi_select too;
+// true and false come out of JSON as 1 and 0, which is also how the driver binds a bool
+func fieldClause(c FieldCondition) (string, []interface{}) {
+	path := fieldPath(c.Key)
+	var (
+		clause string
+		args   = []interface{}{path, c.Value}
+	)
+	switch c.Op {
+	case FieldGt:
+		clause = "json_extract(custom_fields, ?) > ?"
+	case FieldGte:
+		clause = "json_extract(custom_fields, ?) >= ?"
+	case FieldLt:
+		clause = "json_extract(custom_fields, ?) < ?"
+	case FieldLte:
+		clause = "json_extract(custom_fields, ?) <= ?"
+	default:
+		clause = "EXISTS (SELECT 1 FROM json_each(tasks.custom_fields, ?) WHERE value = ?)"
+	}
+	if c.Missing {
+		clause = "(" + clause + " OR json_type(custom_fields, ?) IS NULL)"
+		args = append(args, path)
+	}
+	return clause, args
+}
+
+// fieldPath is the JSON path of a custom field value; keys are validated to be plain identifiers
+func fieldPath(key string) string {
+	return "$." + key
+}
+
+// scopedByIDClause matches one task by ID, but only inside the scope
+func scopedByIDClause(scope Scope, id primitive.ObjectID) (string, []interface{}) {
+	where, args := scopeClause(scope)
+	return "id = ? AND " + where, append([]interface{}{id.Hex()}, args...)
+}
+
+// taskColumns lists the columns scanTask expects, in order
+const taskColumns = `id, owner_id, parent_id, project_id, title, description, priority, completed, status,
+	status_entered_at, rank, start_at, due_at, recurrence, points, estimate_minutes, custom_fields, created_at,
+	updated_at`
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+// so the same read helpers work inside and outside a transaction
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryTasks runs a SELECT on the store's database
+func (s *SQLiteTaskStore) queryTasks(ctx context.Context, query string, args ...interface{}) ([]model.Task, error) {
+	return queryTasks(ctx, s.db, query, args...)
+}
+
+// queryTasks runs a SELECT returning taskColumns and fills in tags and images
+func queryTasks(ctx context.Context, q querier, query string, args ...interface{}) ([]model.Task, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []model.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	// Close the rows before running the next queries: with a single
+	// connection an open result set would block them
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadTaskLists(ctx, q, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// scanTask reads one row of taskColumns into a model.Task
+func scanTask(rows *sql.Rows) (model.Task, error) {
+	var (
+		task                 model.Task
+		id, priority, status string
+		statusSince          sql.NullString // JSON object, NULL for tasks that never changed status
+		ownerID              sql.NullString // NULL for tasks that predate ownership
+		parentID             sql.NullString // NULL for top-level tasks
+		projectID            sql.NullString // NULL for tasks outside any project
+		startAt, dueAt       sql.NullInt64
+		recurrence           sql.NullString // JSON document, NULL for one-off tasks
+		points, estimate     sql.NullInt64  // NULL while the task isn't estimated
+		fields               sql.NullString // JSON object, NULL without custom field values
+		createdAt, updatedAt int64
+	)
+	err := rows.Scan(&id, &ownerID, &parentID, &projectID, &task.Title, &task.Description, &priority, &task.Completed,
+		&status, &statusSince, &task.Rank, &startAt, &dueAt, &recurrence, &points, &estimate, &fields, &createdAt,
+		&updatedAt)
+	if err != nil {
+		return task, err
+	}
+
+	task.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return task, err
+	}
+	if ownerID.Valid {
+		task.OwnerID, err = primitive.ObjectIDFromHex(ownerID.String)
+		if err != nil {
+			return task, err
+		}
+	}
+	if parentID.Valid {
+		parent, err := primitive.ObjectIDFromHex(parentID.String)
+		if err != nil {
+			return task, err
+		}
+		task.ParentID = &parent
+	}
+	if projectID.Valid {
+		project, err := primitive.ObjectIDFromHex(projectID.String)
+		if err != nil {
+			return task, err
+		}
+		task.ProjectID = &project
+	}
+	task.Priority = model.Priority(priority)
+	task.Status = model.Status(status)
+	if statusSince.Valid {
+		var nanos map[model.Status]int64
+		if err := json.Unmarshal([]byte(statusSince.String), &nanos); err != nil {
+			return task, err
+		}
+		task.StatusSince = make(model.StatusTimes, len(nanos))
+		for entered, n := range nanos {
+			task.StatusSince[entered] = time.Unix(0, n)
+		}
+	}
+	task.StartAt = nullTime(startAt)
+	task.DueAt = nullTime(dueAt)
+	task.Points = nullInt(points)
+	task.Estimate = nullInt(estimate)
+	if recurrence.Valid {
+		task.Recurrence = &model.Recurrence{}
+		if err := json.Unmarshal([]byte(recurrence.String), task.Recurrence); err != nil {
+			return task, err
+		}
+	}
+	if fields.Valid {
+		if err := json.Unmarshal([]byte(fields.String), &task.Fields); err != nil {
+			return task, err
+		}
+	}
+	task.Metadata.CreatedAt = time.Unix(0, createdAt)
+	task.Metadata.UpdatedAt = time.Unix(0, updatedAt)
+	return task, nil
+}
+
+// loadTaskLists fetches the tags, images, blockers and attachments for all tasks with one query per table
+// (instead of one query per task, the classic "N+1 queries" problem)
+func loadTaskLists(ctx context.Context, q querier, tasks []model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	// Remember where each task sits in the slice so rows can be attached quickly
+	index := make(map[string]int, len(tasks))
+	args := make([]interface{}, len(tasks))
+	for i, task := range tasks {
+		index[task.ID.Hex()] = i
+		args[i] = task.ID.Hex()
+		tasks[i].Tags = []string{}
+		tasks[i].Image = []string{}
+		tasks[i].BlockedBy = nil
+		tasks[i].Attachments = nil
+		tasks[i].Checklist = nil
+	}
+	in := placeholders(len(tasks))
+
+	tagRows, err := q.QueryContext(ctx,
+		`SELECT task_id, tag FROM task_tags WHERE task_id IN (`+in+`) ORDER BY task_id, position`, args...)
+	if err != nil {
+		return err
+	}
+	for tagRows.Next() {
+		var taskID, tag string
+		if err := tagRows.Scan(&taskID, &tag); err != nil {
+			tagRows.Close()
+			return err
+		}
+		i := index[taskID]
+		tasks[i].Tags = append(tasks[i].Tags, tag)
+	}
+	tagRows.Close()
+	if err := tagRows.Err(); err != nil {
+		return err
+	}
+
+	imageRows, err := q.QueryContext(ctx,
+		`SELECT task_id, url FROM task_images WHERE task_id IN (`+in+`) ORDER BY task_id, position`, args...)
+	if err != nil {
+		return err
+	}
+	for imageRows.Next() {
+		var taskID, url string
+		if err := imageRows.Scan(&taskID, &url); err != nil {
+			imageRows.Close()
+			return err
+		}
+		i := index[taskID]
+		tasks[i].Image = append(tasks[i].Image, url)
+	}
+	imageRows.Close()
+	if err := imageRows.Err(); err != nil {
+		return err
+	}
+
+	dependencyRows, err := q.QueryContext(ctx,
+		`SELECT task_id, blocker_id FROM task_dependencies WHERE task_id IN (`+in+`) ORDER BY task_id, created_at`,
+		args...)
+	if err != nil {
+		return err
+	}
+	for dependencyRows.Next() {
+		var taskID, blockerID string
+		if err := dependencyRows.Scan(&taskID, &blockerID); err != nil {
+			dependencyRows.Close()
+			return err
+		}
+		blocker, err := primitive.ObjectIDFromHex(blockerID)
+		if err != nil {
+			dependencyRows.Close()
+			return err
+		}
+		i := index[taskID]
+		tasks[i].BlockedBy = append(tasks[i].BlockedBy, blocker)
+	}
+	dependencyRows.Close()
+	if err := dependencyRows.Err(); err != nil {
+		return err
+	}
+
+	attachmentRows, err := q.QueryContext(ctx, `
+		SELECT task_id, id, file_name, content_type, size, width, height, blob_key, thumbnail_key, uploaded_by,
+			uploaded_at
+		FROM task_attachments WHERE task_id IN (`+in+`) ORDER BY task_id, uploaded_at, id`, args...)
+	if err != nil {
+		return err
+	}
+	for attachmentRows.Next() {
+		var (
+			attachment             model.Attachment
+			taskID, id, uploadedBy string
+			uploadedAt             int64
+		)
+		err := attachmentRows.Scan(&taskID, &id, &attachment.FileName, &attachment.ContentType, &attachment.Size,
+			&attachment.Width, &attachment.Height, &attachment.Key, &attachment.ThumbnailKey, &uploadedBy, &uploadedAt)
+		if err == nil {
+			attachment.ID, err = primitive.ObjectIDFromHex(id)
+		}
+		if err == nil {
+			attachment.UploadedBy, err = primitive.ObjectIDFromHex(uploadedBy)
+		}
+		if err != nil {
+			attachmentRows.Close()
+			return err
+		}
+		attachment.UploadedAt = time.Unix(0, uploadedAt)
+		i := index[taskID]
+		tasks[i].Attachments = append(tasks[i].Attachments, attachment)
+	}
+	attachmentRows.Close()
+	if err := attachmentRows.Err(); err != nil {
+		return err
+	}
+
+	checklistRows, err := q.QueryContext(ctx, `
+		SELECT task_id, id, text, done, done_at, created_at
+		FROM task_checklist WHERE task_id IN (`+in+`) ORDER BY task_id, position`, args...)
+	if err != nil {
+		return err
+	}
+	for checklistRows.Next() {
+		var (
+			item       model.ChecklistItem
+			taskID, id string
+			doneAt     sql.NullInt64
+			createdAt  int64
+		)
+		err := checklistRows.Scan(&taskID, &id, &item.Text, &item.Done, &doneAt, &createdAt)
+		if err == nil {
+			item.ID, err = primitive.ObjectIDFromHex(id)
+		}
+		if err != nil {
+			checklistRows.Close()
+			return err
+		}
+		item.DoneAt = nullTime(doneAt)
+		item.CreatedAt = time.Unix(0, createdAt)
+		i := index[taskID]
+		tasks[i].Checklist = append(tasks[i].Checklist, item)
+	}
+	checklistRows.Close()
+	return checklistRows.Err()
+}
+
+// writeTaskLists replaces the stored tags and images of a task
+func writeTaskLists(ctx context.Context, tx *sql.Tx, task model.Task) error {
+	id := task.ID.Hex()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	for position, tag := range task.Tags {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO task_tags (task_id, position, tag) VALUES (?, ?, ?)`, id, position, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_images WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	for position, url := range task.Image {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO task_images (task_id, position, url) VALUES (?, ?, ?)`, id, position, url)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// touchTask bumps updated_at of a task inside the scope, or returns ErrNotFound
+// Changes to a task's child rows call it first, so they fail for tasks out of reach
+func touchTask(ctx context.Context, tx *sql.Tx, scope Scope, id primitive.ObjectID) error {
+	where, args := scopedByIDClause(scope, id)
+	result, err := tx.ExecContext(ctx, `UPDATE tasks SET updated_at = ? WHERE `+where,
+		append([]interface{}{time.Now().UnixNano()}, args...)...)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// insertChecklistItem writes one checklist row at the given position
+func insertChecklistItem(ctx context.Context, tx *sql.Tx, taskID primitive.ObjectID, item model.ChecklistItem, position int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO task_checklist (id, task_id, position, text, done, done_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		item.ID.Hex(), taskID.Hex(), position, item.Text, item.Done, nanosOrNull(item.DoneAt),
+		item.CreatedAt.UnixNano())
+	return err
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling back on error
+func (s *SQLiteTaskStore) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return inTx(ctx, s.db, fn)
+}
+
+// inTx is withTx for the stores that share the task store's database
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback() // The original error is more useful than a rollback error
+		return err
+	}
+	return tx.Commit()
+}
+
+// requireAffected turns "no rows changed" into ErrNotFound
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// nullInt turns a nullable INTEGER column into an *int
+func nullInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	n := int(value.Int64)
+	return &n
+}
+
+// nanosOrNull stores an optional time as nanoseconds, or NULL when it is missing
+func nanosOrNull(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UnixNano()
+}
+
+// jsonOrNull encodes an optional value as a JSON column, or NULL when it is nil
+func jsonOrNull[T any](value *T) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// statusTimesOrNull encodes the status timestamps as a JSON object of Unix nanoseconds,
+// like every other time in this database, or NULL when there are none
+func statusTimesOrNull(since model.StatusTimes) (interface{}, error) {
+	if len(since) == 0 {
+		return nil, nil
+	}
+	nanos := make(map[model.Status]int64, len(since))
+	for status, at := range since {
+		nanos[status] = at.UnixNano()
+	}
+	encoded, err := json.Marshal(nanos)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// fieldValuesOrNull encodes the custom field values as a JSON object, or NULL when there are none
+func fieldValuesOrNull(values model.FieldValues) (interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// hexOrNull stores an optional ObjectID as its hex string, or NULL when it is missing
+func hexOrNull(id *primitive.ObjectID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.Hex()
+}
+
+// placeholders returns "?, ?, ?" with n question marks for an IN (...) clause
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLiteAPIKeyStore keeps API keys in the shared SQLite database
+type SQLiteAPIKeyStore struct {
+	db *sql.DB
+}
+
+// CreateAPIKey inserts a new key row
+func (s *SQLiteAPIKeyStore) CreateAPIKey(ctx context.Context, key *model.APIKey) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scope, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key.ID.Hex(), key.UserID.Hex(), key.Name, key.Prefix, key.KeyHash, string(key.Scope),
+		key.CreatedAt.UnixNano())
+	return err
+}
+
+// ListAPIKeys returns the user's keys, newest first
+func (s *SQLiteAPIKeyStore) ListAPIKeys(ctx context.Context, userID primitive.ObjectID) ([]model.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? ORDER BY created_at DESC`, userID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// GetAPIKeyByHash loads a key by its hash
+func (s *SQLiteAPIKeyStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (model.APIKey, error) {
+	return s.getAPIKey(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, keyHash)
+}
+
+// RevokeAPIKey revokes one of the user's keys, keeping the first revocation time
+func (s *SQLiteAPIKeyStore) RevokeAPIKey(ctx context.Context, userID, id primitive.ObjectID) (model.APIKey, error) {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND user_id = ?`,
+		time.Now().UnixNano(), id.Hex(), userID.Hex())
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	return s.getAPIKey(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ? AND user_id = ?`, id.Hex(), userID.Hex())
+}
+
+// TouchAPIKey records when the key was last used
+func (s *SQLiteAPIKeyStore) TouchAPIKey(ctx context.Context, id primitive.ObjectID, usedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE api_keys SET last_used_at = ? WHERE id = ?`, usedAt.UnixNano(), id.Hex())
+	return err
+}
+
+// apiKeyColumns lists the columns scanAPIKey expects, in order
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scope, created_at, last_used_at, revoked_at`
+
+// getAPIKey runs a query expected to return at most one key
+func (s *SQLiteAPIKeyStore) getAPIKey(ctx context.Context, query string, args ...interface{}) (model.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return model.APIKey{}, err
+		}
+		return model.APIKey{}, ErrAPIKeyNotFound
+	}
+	return scanAPIKey(rows)
+}
+
+// scanAPIKey reads one row of apiKeyColumns into a model.APIKey
+func scanAPIKey(rows *sql.Rows) (model.APIKey, error) {
+	var (
+		key                 model.APIKey
+		id, userID, scope   string
+		createdAt           int64
+		lastUsedAt, revoked sql.NullInt64
+	)
+	err := rows.Scan(&id, &userID, &key.Name, &key.Prefix, &key.KeyHash, &scope, &createdAt, &lastUsedAt, &revoked)
+	if err != nil {
+		return key, err
+	}
+
+	if key.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return key, err
+	}
+	if key.UserID, err = primitive.ObjectIDFromHex(userID); err != nil {
+		return key, err
+	}
+	key.Scope = model.APIKeyScope(scope)
+	key.CreatedAt = time.Unix(0, createdAt)
+	key.LastUsedAt = nullTime(lastUsedAt)
+	key.RevokedAt = nullTime(revoked)
+	return key, nil
+}
+
+// nullTime converts a nullable nanosecond column into an optional time
+func nullTime(value sql.NullInt64) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := time.Unix(0, value.Int64)
+	return &t
+}
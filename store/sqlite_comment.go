@@ -0,0 +1,247 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLiteCommentStore keeps comments in the shared SQLite database
+// The edit history lives in comment_revisions, one row per earlier body
+type SQLiteCommentStore struct {
+	db *sql.DB
+}
+
+// commentColumns lists the columns scanComment reads, in order
+const commentColumns = `id, task_id, owner_id, author_id, body, edited_at, deleted_at, created_at, updated_at`
+
+// CreateComment inserts a new comment row
+func (s *SQLiteCommentStore) CreateComment(ctx context.Context, comment *model.Comment) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO comments (id, task_id, owner_id, author_id, body, edited_at, deleted_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		comment.ID.Hex(), comment.TaskID.Hex(), comment.OwnerID.Hex(), comment.AuthorID.Hex(), comment.Body,
+		nanosOrNull(comment.EditedAt), nanosOrNull(comment.DeletedAt),
+		comment.Metadata.CreatedAt.UnixNano(), comment.Metadata.UpdatedAt.UnixNano())
+	return err
+}
+
+// GetComment loads one comment of the task together with its history
+func (s *SQLiteCommentStore) GetComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID) (model.Comment, error) {
+	where, args := commentClause(scope, taskID, id)
+	comment, err := scanComment(s.db.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE `+where, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Comment{}, ErrCommentNotFound
+	}
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT body, written_at FROM comment_revisions WHERE comment_id = ? ORDER BY position`, id.Hex())
+	if err != nil {
+		return model.Comment{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			revision  model.CommentRevision
+			writtenAt int64
+		)
+		if err := rows.Scan(&revision.Body, &writtenAt); err != nil {
+			return model.Comment{}, err
+		}
+		revision.WrittenAt = time.Unix(0, writtenAt)
+		comment.History = append(comment.History, revision)
+	}
+	return comment, rows.Err()
+}
+
+// ListComments returns one page of the task's comments, oldest first
+func (s *SQLiteCommentStore) ListComments(ctx context.Context, scope Scope, taskID primitive.ObjectID, opts ListOptions) ([]model.Comment, int64, error) {
+	where, args := scopeClause(scope)
+	where = "task_id = ? AND " + where
+	args = append([]interface{}{taskID.Hex()}, args...)
+
+	var total int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM comments WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+commentColumns+` FROM comments WHERE `+where+` ORDER BY created_at, id LIMIT ? OFFSET ?`,
+		append(args, opts.Limit, opts.Skip())...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	comments := []model.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, total, rows.Err()
+}
+
+// EditComment copies the current body into comment_revisions and replaces it, in one transaction
+func (s *SQLiteCommentStore) EditComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, body string, editedAt time.Time) (model.Comment, error) {
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		where, args := commentClause(scope, taskID, id)
+		current, err := scanComment(tx.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE `+where, args...))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current.IsDeleted() {
+			return ErrCommentDeleted
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO comment_revisions (comment_id, position, body, written_at)
+			VALUES (?, (SELECT COUNT(*) FROM comment_revisions WHERE comment_id = ?), ?, ?)`,
+			id.Hex(), id.Hex(), current.Body, writtenAt(current).UnixNano())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE comments SET body = ?, edited_at = ?, updated_at = ? WHERE id = ?`,
+			body, editedAt.UnixNano(), editedAt.UnixNano(), id.Hex())
+		return err
+	})
+	if err != nil {
+		return model.Comment{}, err
+	}
+	return s.GetComment(ctx, scope, taskID, id)
+}
+
+// DeleteComment sets deleted_at on a comment that doesn't have it yet
+func (s *SQLiteCommentStore) DeleteComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, deletedAt time.Time) (model.Comment, error) {
+	where, args := commentClause(scope, taskID, id)
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE comments SET deleted_at = ?, updated_at = ? WHERE deleted_at IS NULL AND `+where,
+		append([]interface{}{deletedAt.UnixNano(), deletedAt.UnixNano()}, args...)...)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	comment, err := s.GetComment(ctx, scope, taskID, id)
+	if err == nil && affected == 0 {
+		// The comment exists, so it was deleted already
+		return model.Comment{}, ErrCommentDeleted
+	}
+	return comment, err
+}
+
+// SearchComments runs the query on the comments_fts index, which only holds live comments
+func (s *SQLiteCommentStore) SearchComments(ctx context.Context, scope Scope, query TextQuery, limit int) ([]CommentMatch, error) {
+	where, args := scopeClause(scope)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT comments.id, comments.task_id, comments.body, -bm25(comments_fts, 0, ?) AS score
+		FROM comments_fts JOIN comments ON comments.id = comments_fts.id
+		WHERE comments_fts MATCH ? AND `+where+`
+		ORDER BY score DESC, comments.id DESC
+		LIMIT ?`,
+		append(append([]interface{}{commentWeight, query.FTSMatch()}, args...), limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []CommentMatch
+	for rows.Next() {
+		var (
+			id, taskID string
+			match      CommentMatch
+		)
+		if err := rows.Scan(&id, &taskID, &match.Body, &match.Score); err != nil {
+			return nil, err
+		}
+		if match.CommentID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return nil, err
+		}
+		if match.TaskID, err = primitive.ObjectIDFromHex(taskID); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// DeleteCommentsByTasks removes every comment of the tasks
+// Deleting a task already cascades to its comments; this catches comments left behind otherwise
+func (s *SQLiteCommentStore) DeleteCommentsByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+	args := make([]interface{}, len(taskIDs))
+	for i, taskID := range taskIDs {
+		args[i] = taskID.Hex()
+	}
+	result, err := s.db.ExecContext(ctx, `DELETE FROM comments WHERE task_id IN (`+placeholders(len(args))+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteCommentsByOwner removes every comment on ownerID's tasks
+func (s *SQLiteCommentStore) DeleteCommentsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM comments WHERE owner_id = ?`, ownerID.Hex())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// commentClause matches one comment of a task inside the scope
+func commentClause(scope Scope, taskID, id primitive.ObjectID) (string, []interface{}) {
+	where, args := scopedByIDClause(scope, id)
+	return "task_id = ? AND " + where, append([]interface{}{taskID.Hex()}, args...)
+}
+
+// scanComment reads one row of commentColumns into a model.Comment
+func scanComment(row rowScanner) (model.Comment, error) {
+	var (
+		comment                     model.Comment
+		id, taskID, ownerID, author string
+		editedAt, deletedAt         sql.NullInt64
+		createdAt, updatedAt        int64
+	)
+	err := row.Scan(&id, &taskID, &ownerID, &author, &comment.Body, &editedAt, &deletedAt, &createdAt, &updatedAt)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	if comment.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return model.Comment{}, err
+	}
+	if comment.TaskID, err = primitive.ObjectIDFromHex(taskID); err != nil {
+		return model.Comment{}, err
+	}
+	if comment.OwnerID, err = primitive.ObjectIDFromHex(ownerID); err != nil {
+		return model.Comment{}, err
+	}
+	if comment.AuthorID, err = primitive.ObjectIDFromHex(author); err != nil {
+		return model.Comment{}, err
+	}
+	comment.EditedAt = nullTime(editedAt)
+	comment.DeletedAt = nullTime(deletedAt)
+	comment.Metadata.CreatedAt = time.Unix(0, createdAt)
+	comment.Metadata.UpdatedAt = time.Unix(0, updatedAt)
+	return comment, nil
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLiteCustomFieldStore keeps custom field definitions in the shared SQLite database
+type SQLiteCustomFieldStore struct {
+	db *sql.DB
+}
+
+// customFieldColumns lists the columns scanCustomField reads, in order
+const customFieldColumns = `id, owner_id, key, name, type, options, required, created_at, updated_at`
+
+// CreateCustomField inserts a new definition row; the UNIQUE constraint guards the key
+func (s *SQLiteCustomFieldStore) CreateCustomField(ctx context.Context, field *model.CustomField) error {
+	options, err := optionsOrNull(field.Options)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO custom_fields (id, owner_id, key, name, type, options, required, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		field.ID.Hex(), field.OwnerID.Hex(), field.Key, field.Name, string(field.Type), options, field.Required,
+		field.Metadata.CreatedAt.UnixNano(), field.Metadata.UpdatedAt.UnixNano())
+	if err != nil && isUniqueViolation(err) {
+		return ErrCustomFieldKeyTaken
+	}
+	return err
+}
+
+// GetCustomField loads one definition inside the scope
+func (s *SQLiteCustomFieldStore) GetCustomField(ctx context.Context, scope Scope, id primitive.ObjectID) (model.CustomField, error) {
+	where, args := scopedByIDClause(scope, id)
+	field, err := scanCustomField(s.db.QueryRowContext(ctx,
+		`SELECT `+customFieldColumns+` FROM custom_fields WHERE `+where, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.CustomField{}, ErrCustomFieldNotFound
+	}
+	return field, err
+}
+
+// ListCustomFields returns the definitions in the scope, oldest first
+func (s *SQLiteCustomFieldStore) ListCustomFields(ctx context.Context, scope Scope) ([]model.CustomField, error) {
+	where, args := scopeClause(scope)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+customFieldColumns+` FROM custom_fields WHERE `+where+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []model.CustomField{}
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, rows.Err()
+}
+
+// UpdateCustomField changes the fields present on the update in a single statement
+// Like UpdateProject, COALESCE keeps the current value for the fields bound as NULL
+func (s *SQLiteCustomFieldStore) UpdateCustomField(ctx context.Context, scope Scope, id primitive.ObjectID, update CustomFieldUpdate) (model.CustomField, error) {
+	var options interface{}
+	if update.Options != nil {
+		var err error
+		if options, err = optionsOrNull(*update.Options); err != nil {
+			return model.CustomField{}, err
+		}
+	}
+
+	where, args := scopedByIDClause(scope, id)
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE custom_fields
+		SET name = COALESCE(?, name), options = COALESCE(?, options), required = COALESCE(?, required), updated_at = ?
+		WHERE `+where,
+		append([]interface{}{update.Name, options, update.Required, time.Now().UnixNano()}, args...)...)
+	if err != nil {
+		return model.CustomField{}, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return model.CustomField{}, err
+	}
+	if affected == 0 {
+		return model.CustomField{}, ErrCustomFieldNotFound
+	}
+	return s.GetCustomField(ctx, scope, id)
+}
+
+// DeleteCustomField removes the definition row
+func (s *SQLiteCustomFieldStore) DeleteCustomField(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	where, args := scopedByIDClause(scope, id)
+	result, err := s.db.ExecContext(ctx, `DELETE FROM custom_fields WHERE `+where, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCustomFieldNotFound
+	}
+	return nil
+}
+
+// DeleteCustomFieldsByOwner removes every definition of one owner
+func (s *SQLiteCustomFieldStore) DeleteCustomFieldsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM custom_fields WHERE owner_id = ?`, ownerID.Hex())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// optionsOrNull encodes the options of a select field as a JSON array, or NULL for the other types
+func optionsOrNull(options []string) (interface{}, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// scanCustomField reads one row of customFieldColumns into a model.CustomField
+func scanCustomField(row rowScanner) (model.CustomField, error) {
+	var (
+		field                model.CustomField
+		id, ownerID, kind    string
+		options              sql.NullString // JSON array, NULL for fields without options
+		createdAt, updatedAt int64
+	)
+	err := row.Scan(&id, &ownerID, &field.Key, &field.Name, &kind, &options, &field.Required, &createdAt, &updatedAt)
+	if err != nil {
+		return model.CustomField{}, err
+	}
+
+	if field.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return model.CustomField{}, err
+	}
+	if field.OwnerID, err = primitive.ObjectIDFromHex(ownerID); err != nil {
+		return model.CustomField{}, err
+	}
+	field.Type = model.FieldType(kind)
+	if options.Valid {
+		if err := json.Unmarshal([]byte(options.String), &field.Options); err != nil {
+			return model.CustomField{}, err
+		}
+	}
+	field.Metadata.CreatedAt = time.Unix(0, createdAt)
+	field.Metadata.UpdatedAt = time.Unix(0, updatedAt)
+	return field, nil
+}
@@ -0,0 +1,337 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// sqliteMigrations is the ordered list of schema changes for the SQLite backend
+// Each entry runs exactly once; its position in the slice (starting at 1) is its version
+// Never edit a migration that has shipped - append a new one instead
+var sqliteMigrations = []string{
+	// 1: tasks with their tags and images
+	// Timestamps are stored as Unix nanoseconds so they sort and compare as plain integers
+	// Tags and images keep their order through the position column
+	`
+	CREATE TABLE tasks (
+		id          TEXT PRIMARY KEY,
+		title       TEXT    NOT NULL,
+		description TEXT    NOT NULL DEFAULT '',
+		priority    TEXT    NOT NULL,
+		completed   INTEGER NOT NULL DEFAULT 0,
+		created_at  INTEGER NOT NULL,
+		updated_at  INTEGER NOT NULL
+	);
+	CREATE INDEX idx_tasks_created_at ON tasks (created_at);
+
+	CREATE TABLE task_tags (
+		task_id  TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		tag      TEXT    NOT NULL,
+		PRIMARY KEY (task_id, position)
+	);
+
+	CREATE TABLE task_images (
+		task_id  TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		url      TEXT    NOT NULL,
+		PRIMARY KEY (task_id, position)
+	);
+	`,
+
+	// 2: user accounts; COLLATE NOCASE makes the unique email check case-insensitive
+	`
+	CREATE TABLE users (
+		id            TEXT PRIMARY KEY,
+		name          TEXT    NOT NULL,
+		email         TEXT    NOT NULL UNIQUE COLLATE NOCASE,
+		password_hash TEXT    NOT NULL,
+		created_at    INTEGER NOT NULL,
+		updated_at    INTEGER NOT NULL
+	);
+	`,
+
+	// 3: refresh tokens, looked up by the hash of their value
+	`
+	CREATE TABLE refresh_tokens (
+		id         TEXT PRIMARY KEY,
+		user_id    TEXT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+		family_id  TEXT    NOT NULL,
+		token_hash TEXT    NOT NULL UNIQUE,
+		expires_at INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		revoked_at INTEGER
+	);
+	CREATE INDEX idx_refresh_tokens_family ON refresh_tokens (family_id);
+	`,
+
+	// 4: task ownership; existing rows keep a NULL owner until AssignOwnerless claims them
+	`
+	ALTER TABLE tasks ADD COLUMN owner_id TEXT REFERENCES users (id) ON DELETE CASCADE;
+	CREATE INDEX idx_tasks_owner_created_at ON tasks (owner_id, created_at);
+	`,
+
+	// 5: personal API keys, looked up by the hash of their value
+	`
+	CREATE TABLE api_keys (
+		id           TEXT PRIMARY KEY,
+		user_id      TEXT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+		name         TEXT    NOT NULL,
+		prefix       TEXT    NOT NULL,
+		key_hash     TEXT    NOT NULL UNIQUE,
+		scope        TEXT    NOT NULL,
+		created_at   INTEGER NOT NULL,
+		last_used_at INTEGER,
+		revoked_at   INTEGER
+	);
+	CREATE INDEX idx_api_keys_user ON api_keys (user_id, created_at);
+	`,
+
+	// 6: user roles; existing accounts become members
+	`
+	ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+	`,
+
+	// 7: planning dates, stored like every other time as UTC nanoseconds
+	`
+	ALTER TABLE tasks ADD COLUMN start_at INTEGER;
+	ALTER TABLE tasks ADD COLUMN due_at INTEGER;
+	CREATE INDEX idx_tasks_owner_due_at ON tasks (owner_id, due_at);
+	`,
+
+	// 8: recurrence rules, kept as a JSON document because they are always read and written whole
+	`
+	ALTER TABLE tasks ADD COLUMN recurrence TEXT;
+	`,
+
+	// 9: subtasks; deleting a parent outside DeleteTree leaves its children as top-level tasks
+	`
+	ALTER TABLE tasks ADD COLUMN parent_id TEXT REFERENCES tasks (id) ON DELETE SET NULL;
+	CREATE INDEX idx_tasks_parent ON tasks (parent_id);
+	`,
+
+	// 10: "blocker blocks task" edges; deleting either task removes the edge
+	`
+	CREATE TABLE task_dependencies (
+		task_id    TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		blocker_id TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (task_id, blocker_id)
+	);
+	CREATE INDEX idx_task_dependencies_blocker ON task_dependencies (blocker_id);
+	`,
+
+	// 11: projects; deleting a project outside the API leaves its tasks without a project
+	`
+	CREATE TABLE projects (
+		id          TEXT PRIMARY KEY,
+		owner_id    TEXT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+		name        TEXT    NOT NULL,
+		color       TEXT    NOT NULL DEFAULT '',
+		description TEXT    NOT NULL DEFAULT '',
+		archived    INTEGER NOT NULL DEFAULT 0,
+		created_at  INTEGER NOT NULL,
+		updated_at  INTEGER NOT NULL
+	);
+	CREATE INDEX idx_projects_owner_created_at ON projects (owner_id, created_at);
+
+	ALTER TABLE tasks ADD COLUMN project_id TEXT REFERENCES projects (id) ON DELETE SET NULL;
+	CREATE INDEX idx_tasks_owner_project ON tasks (owner_id, project_id);
+	`,
+
+	// 12: workflow statuses; existing tasks start in the status their completed flag implies
+	// status_entered_at is a JSON object from status to the UTC nanoseconds it was last entered
+	`
+	ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'todo';
+	ALTER TABLE tasks ADD COLUMN status_entered_at TEXT;
+	UPDATE tasks SET status = 'done' WHERE completed = 1;
+	CREATE INDEX idx_tasks_owner_status ON tasks (owner_id, status);
+	`,
+
+	// 13: board positions; an empty rank means the task was never moved and ranks by its ID
+	`
+	ALTER TABLE tasks ADD COLUMN rank TEXT NOT NULL DEFAULT '';
+	`,
+
+	// 14: attachment metadata; the files themselves live in the blob store
+	`
+	CREATE TABLE task_attachments (
+		id            TEXT PRIMARY KEY,
+		task_id       TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		file_name     TEXT    NOT NULL,
+		content_type  TEXT    NOT NULL,
+		size          INTEGER NOT NULL,
+		width         INTEGER NOT NULL DEFAULT 0,
+		height        INTEGER NOT NULL DEFAULT 0,
+		blob_key      TEXT    NOT NULL,
+		thumbnail_key TEXT    NOT NULL DEFAULT '',
+		uploaded_by   TEXT    NOT NULL,
+		uploaded_at   INTEGER NOT NULL
+	);
+	CREATE INDEX idx_task_attachments_task ON task_attachments (task_id, uploaded_at);
+	`,
+
+	// 15: comments and their edit history; deleting a task removes its thread
+	`
+	CREATE TABLE comments (
+		id         TEXT PRIMARY KEY,
+		task_id    TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		owner_id   TEXT    NOT NULL,
+		author_id  TEXT    NOT NULL,
+		body       TEXT    NOT NULL,
+		edited_at  INTEGER,
+		deleted_at INTEGER,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+	CREATE INDEX idx_comments_task_created_at ON comments (task_id, created_at);
+	CREATE INDEX idx_comments_owner ON comments (owner_id);
+
+	CREATE TABLE comment_revisions (
+		comment_id TEXT    NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+		position   INTEGER NOT NULL,
+		body       TEXT    NOT NULL,
+		written_at INTEGER NOT NULL,
+		PRIMARY KEY (comment_id, position)
+	);
+	`,
+
+	// 16: checklist items, kept in order by the position column
+	`
+	CREATE TABLE task_checklist (
+		id         TEXT PRIMARY KEY,
+		task_id    TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		position   INTEGER NOT NULL,
+		text       TEXT    NOT NULL,
+		done       INTEGER NOT NULL DEFAULT 0,
+		done_at    INTEGER,
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX idx_task_checklist_task ON task_checklist (task_id, position);
+	`,
+
+	// 17: time entries; the partial unique index allows one running timer per user
+	`
+	CREATE TABLE time_entries (
+		id         TEXT PRIMARY KEY,
+		task_id    TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		owner_id   TEXT    NOT NULL,
+		user_id    TEXT    NOT NULL,
+		started_at INTEGER NOT NULL,
+		ended_at   INTEGER,
+		seconds    INTEGER NOT NULL DEFAULT 0,
+		running    INTEGER NOT NULL DEFAULT 0,
+		manual     INTEGER NOT NULL DEFAULT 0,
+		note       TEXT    NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+	CREATE INDEX idx_time_entries_task_started_at ON time_entries (task_id, started_at);
+	CREATE INDEX idx_time_entries_owner_started_at ON time_entries (owner_id, started_at);
+	CREATE UNIQUE INDEX idx_time_entries_user_running ON time_entries (user_id) WHERE running = 1;
+	`,
+
+	// 18: optional estimates, NULL while a task isn't estimated
+	`
+	ALTER TABLE tasks ADD COLUMN points INTEGER;
+	ALTER TABLE tasks ADD COLUMN estimate_minutes INTEGER;
+	`,
+
+	// 19: custom field definitions; task values live in one JSON object column, NULL when there are none
+	`
+	CREATE TABLE custom_fields (
+		id         TEXT PRIMARY KEY,
+		owner_id   TEXT    NOT NULL,
+		key        TEXT    NOT NULL,
+		name       TEXT    NOT NULL,
+		type       TEXT    NOT NULL,
+		options    TEXT,
+		required   INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		UNIQUE (owner_id, key)
+	);
+	ALTER TABLE tasks ADD COLUMN custom_fields TEXT;
+	`,
+
+	// 20: full-text indexes of tasks and live comments, kept in sync by triggers
+	// The row IDs are carried in UNINDEXED columns because the implicit rowid of tasks may change on VACUUM
+	`
+	CREATE VIRTUAL TABLE tasks_fts USING fts5 (id UNINDEXED, title, description, tokenize = 'porter unicode61');
+	INSERT INTO tasks_fts (id, title, description) SELECT id, title, description FROM tasks;
+
+	CREATE TRIGGER tasks_fts_insert AFTER INSERT ON tasks BEGIN
+		INSERT INTO tasks_fts (id, title, description) VALUES (new.id, new.title, new.description);
+	END;
+	CREATE TRIGGER tasks_fts_update AFTER UPDATE OF title, description ON tasks
+	WHEN old.title IS NOT new.title OR old.description IS NOT new.description BEGIN
+		DELETE FROM tasks_fts WHERE id = old.id;
+		INSERT INTO tasks_fts (id, title, description) VALUES (new.id, new.title, new.description);
+	END;
+	CREATE TRIGGER tasks_fts_delete AFTER DELETE ON tasks BEGIN
+		DELETE FROM tasks_fts WHERE id = old.id;
+	END;
+
+	CREATE VIRTUAL TABLE comments_fts USING fts5 (id UNINDEXED, body, tokenize = 'porter unicode61');
+	INSERT INTO comments_fts (id, body) SELECT id, body FROM comments WHERE deleted_at IS NULL;
+
+	CREATE TRIGGER comments_fts_insert AFTER INSERT ON comments WHEN new.deleted_at IS NULL BEGIN
+		INSERT INTO comments_fts (id, body) VALUES (new.id, new.body);
+	END;
+	CREATE TRIGGER comments_fts_update AFTER UPDATE OF body, deleted_at ON comments BEGIN
+		DELETE FROM comments_fts WHERE id = old.id;
+		INSERT INTO comments_fts (id, body) SELECT new.id, new.body WHERE new.deleted_at IS NULL;
+	END;
+	CREATE TRIGGER comments_fts_delete AFTER DELETE ON comments BEGIN
+		DELETE FROM comments_fts WHERE id = old.id;
+	END;
+	`,
+}
+
+// migrateSQLite brings the database schema up to the latest version
+// The schema_migrations table remembers which versions were already applied
+func migrateSQLite(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			applied_at INTEGER NOT NULL
+		)`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current int
+	err = db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for i := current; i < len(sqliteMigrations); i++ {
+		version := i + 1
+
+		// Run each migration in its own transaction so a failure leaves
+		// the schema at the last good version instead of half applied
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("apply migration %d: %w", version, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+			version, time.Now().UnixNano())
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("record migration %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLiteProjectStore keeps projects in the shared SQLite database
+type SQLiteProjectStore struct {
+	db *sql.DB
+}
+
+// projectColumns lists the columns scanProject reads, in order
+const projectColumns = `id, owner_id, name, color, description, archived, created_at, updated_at`
+
+// CreateProject inserts a new project row
+func (s *SQLiteProjectStore) CreateProject(ctx context.Context, project *model.Project) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO projects (id, owner_id, name, color, description, archived, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		project.ID.Hex(), project.OwnerID.Hex(), project.Name, project.Color, project.Description,
+		project.Archived, project.Metadata.CreatedAt.UnixNano(), project.Metadata.UpdatedAt.UnixNano())
+	return err
+}
+
+// GetProject loads one project inside the scope
+func (s *SQLiteProjectStore) GetProject(ctx context.Context, scope Scope, id primitive.ObjectID) (model.Project, error) {
+	where, args := scopedByIDClause(scope, id)
+	project, err := scanProject(s.db.QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE `+where, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Project{}, ErrProjectNotFound
+	}
+	return project, err
+}
+
+// ListProjects returns the projects in the scope, oldest first
+func (s *SQLiteProjectStore) ListProjects(ctx context.Context, scope Scope, includeArchived bool) ([]model.Project, error) {
+	where, args := scopeClause(scope)
+	if !includeArchived {
+		where += " AND archived = 0"
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+projectColumns+` FROM projects WHERE `+where+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []model.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+// UpdateProject changes the fields present on the update in a single statement
+// A nil pointer is bound as NULL, so COALESCE keeps the current value for fields that weren't sent
+func (s *SQLiteProjectStore) UpdateProject(ctx context.Context, scope Scope, id primitive.ObjectID, update ProjectUpdate) (model.Project, error) {
+	where, args := scopedByIDClause(scope, id)
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE projects
+		SET name = COALESCE(?, name), color = COALESCE(?, color), description = COALESCE(?, description),
+			archived = COALESCE(?, archived), updated_at = ?
+		WHERE `+where,
+		append([]interface{}{update.Name, update.Color, update.Description, update.Archived,
+			time.Now().UnixNano()}, args...)...)
+	if err != nil {
+		return model.Project{}, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return model.Project{}, err
+	}
+	if affected == 0 {
+		return model.Project{}, ErrProjectNotFound
+	}
+	return s.GetProject(ctx, scope, id)
+}
+
+// DeleteProject removes the project; ON DELETE SET NULL takes its tasks out of it
+func (s *SQLiteProjectStore) DeleteProject(ctx context.Context, scope Scope, id primitive.ObjectID) error {
+	where, args := scopedByIDClause(scope, id)
+	result, err := s.db.ExecContext(ctx, `DELETE FROM projects WHERE `+where, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// DeleteProjectsByOwner removes every project of one owner
+func (s *SQLiteProjectStore) DeleteProjectsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM projects WHERE owner_id = ?`, ownerID.Hex())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanProject reads one row of projectColumns into a model.Project
+func scanProject(row rowScanner) (model.Project, error) {
+	var (
+		project              model.Project
+		id, ownerID          string
+		createdAt, updatedAt int64
+	)
+	err := row.Scan(&id, &ownerID, &project.Name, &project.Color, &project.Description, &project.Archived,
+		&createdAt, &updatedAt)
+	if err != nil {
+		return model.Project{}, err
+	}
+
+	if project.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return model.Project{}, err
+	}
+	if project.OwnerID, err = primitive.ObjectIDFromHex(ownerID); err != nil {
+		return model.Project{}, err
+	}
+	project.Metadata.CreatedAt = time.Unix(0, createdAt)
+	project.Metadata.UpdatedAt = time.Unix(0, updatedAt)
+	return project, nil
+}
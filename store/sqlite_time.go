@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLiteTimeEntryStore keeps time entries in the shared SQLite database
+type SQLiteTimeEntryStore struct {
+	db *sql.DB
+}
+
+// timeEntryColumns lists the columns scanTimeEntry reads, in order
+const timeEntryColumns = `id, task_id, owner_id, user_id, started_at, ended_at, seconds, running, manual, note, created_at, updated_at`
+
+// CreateTimeEntry inserts a new entry row; the partial unique index rejects a second running timer
+func (s *SQLiteTimeEntryStore) CreateTimeEntry(ctx context.Context, entry *model.TimeEntry) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO time_entries (`+timeEntryColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID.Hex(), entry.TaskID.Hex(), entry.OwnerID.Hex(), entry.UserID.Hex(),
+		entry.StartedAt.UnixNano(), nanosOrNull(entry.EndedAt), entry.Seconds, entry.Running, entry.Manual, entry.Note,
+		entry.Metadata.CreatedAt.UnixNano(), entry.Metadata.UpdatedAt.UnixNano())
+	if err != nil && isUniqueViolation(err) {
+		return ErrTimerRunning
+	}
+	return err
+}
+
+// RunningTimer returns the user's running timer
+func (s *SQLiteTimeEntryStore) RunningTimer(ctx context.Context, userID primitive.ObjectID) (model.TimeEntry, error) {
+	entry, err := scanTimeEntry(s.db.QueryRowContext(ctx,
+		`SELECT `+timeEntryColumns+` FROM time_entries WHERE user_id = ? AND running = 1`, userID.Hex()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	return entry, err
+}
+
+// StopTimer ends the user's running timer; the duration is computed from the stored start in the same statement
+func (s *SQLiteTimeEntryStore) StopTimer(ctx context.Context, userID primitive.ObjectID, endedAt time.Time) (model.TimeEntry, error) {
+	var id string
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE time_entries
+		SET running = 0, ended_at = ?, seconds = MAX(0, (? - started_at) / 1000000000), updated_at = ?
+		WHERE user_id = ? AND running = 1
+		RETURNING id`,
+		endedAt.UnixNano(), endedAt.UnixNano(), endedAt.UnixNano(), userID.Hex()).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	entry, err := scanTimeEntry(s.db.QueryRowContext(ctx, `SELECT `+timeEntryColumns+` FROM time_entries WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	return entry, err
+}
+
+// ListTimeEntries returns one page of the task's entries, most recent first
+func (s *SQLiteTimeEntryStore) ListTimeEntries(ctx context.Context, scope Scope, taskID primitive.ObjectID, opts ListOptions) ([]model.TimeEntry, int64, error) {
+	where, args := scopeClause(scope)
+	where = "task_id = ? AND " + where
+	args = append([]interface{}{taskID.Hex()}, args...)
+
+	var total int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM time_entries WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+timeEntryColumns+` FROM time_entries WHERE `+where+` ORDER BY started_at DESC, id DESC LIMIT ? OFFSET ?`,
+		append(args, opts.Limit, opts.Skip())...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []model.TimeEntry{}
+	for rows.Next() {
+		entry, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
+
+// DeleteTimeEntry removes one entry of the task inside the scope
+func (s *SQLiteTimeEntryStore) DeleteTimeEntry(ctx context.Context, scope Scope, taskID, id primitive.ObjectID) error {
+	where, args := scopedByIDClause(scope, id)
+	result, err := s.db.ExecContext(ctx, `DELETE FROM time_entries WHERE task_id = ? AND `+where,
+		append([]interface{}{taskID.Hex()}, args...)...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTimeEntryNotFound
+	}
+	return nil
+}
+
+// SumTimeByTask groups the matching stopped entries by task in SQL
+func (s *SQLiteTimeEntryStore) SumTimeByTask(ctx context.Context, scope Scope, filter TimeFilter) ([]model.TaskTime, error) {
+	where, args := scopeClause(scope)
+
+	var clause strings.Builder
+	clause.WriteString(where + " AND running = 0")
+	if filter.TaskIDs != nil {
+		if len(filter.TaskIDs) == 0 {
+			return []model.TaskTime{}, nil
+		}
+		clause.WriteString(" AND task_id IN (" + placeholders(len(filter.TaskIDs)) + ")")
+		for _, taskID := range filter.TaskIDs {
+			args = append(args, taskID.Hex())
+		}
+	}
+	if filter.From != nil {
+		clause.WriteString(" AND started_at >= ?")
+		args = append(args, filter.From.UnixNano())
+	}
+	if filter.To != nil {
+		clause.WriteString(" AND started_at < ?")
+		args = append(args, filter.To.UnixNano())
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT task_id, SUM(seconds), COUNT(*) FROM time_entries WHERE `+clause.String()+` GROUP BY task_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sums := []model.TaskTime{}
+	for rows.Next() {
+		var (
+			sum    model.TaskTime
+			taskID string
+		)
+		if err := rows.Scan(&taskID, &sum.Seconds, &sum.Entries); err != nil {
+			return nil, err
+		}
+		if sum.TaskID, err = primitive.ObjectIDFromHex(taskID); err != nil {
+			return nil, err
+		}
+		sums = append(sums, sum)
+	}
+	return sums, rows.Err()
+}
+
+// DeleteTimeEntriesByTasks removes every entry of the tasks
+// Deleting a task already cascades to its entries; this catches entries left behind otherwise
+func (s *SQLiteTimeEntryStore) DeleteTimeEntriesByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+	args := make([]interface{}, len(taskIDs))
+	for i, taskID := range taskIDs {
+		args[i] = taskID.Hex()
+	}
+	result, err := s.db.ExecContext(ctx, `DELETE FROM time_entries WHERE task_id IN (`+placeholders(len(args))+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteTimeEntriesByOwner removes every entry on ownerID's tasks
+func (s *SQLiteTimeEntryStore) DeleteTimeEntriesByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM time_entries WHERE owner_id = ?`, ownerID.Hex())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanTimeEntry reads one row of timeEntryColumns into a model.TimeEntry
+func scanTimeEntry(row rowScanner) (model.TimeEntry, error) {
+	var (
+		entry                       model.TimeEntry
+		id, taskID, ownerID, userID string
+		startedAt                   int64
+		endedAt                     sql.NullInt64
+		createdAt, updatedAt        int64
+	)
+	err := row.Scan(&id, &taskID, &ownerID, &userID, &startedAt, &endedAt, &entry.Seconds,
+		&entry.Running, &entry.Manual, &entry.Note, &createdAt, &updatedAt)
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	if entry.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return model.TimeEntry{}, err
+	}
+	if entry.TaskID, err = primitive.ObjectIDFromHex(taskID); err != nil {
+		return model.TimeEntry{}, err
+	}
+	if entry.OwnerID, err = primitive.ObjectIDFromHex(ownerID); err != nil {
+		return model.TimeEntry{}, err
+	}
+	if entry.UserID, err = primitive.ObjectIDFromHex(userID); err != nil {
+		return model.TimeEntry{}, err
+	}
+	entry.StartedAt = time.Unix(0, startedAt)
+	entry.EndedAt = nullTime(endedAt)
+	entry.Metadata.CreatedAt = time.Unix(0, createdAt)
+	entry.Metadata.UpdatedAt = time.Unix(0, updatedAt)
+	return entry, nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLiteRefreshTokenStore keeps refresh tokens in the shared SQLite database
+type SQLiteRefreshTokenStore struct {
+	db *sql.DB
+}
+
+// CreateRefreshToken inserts a new token row
+func (s *SQLiteRefreshTokenStore) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		token.ID.Hex(), token.UserID.Hex(), token.FamilyID.Hex(), token.TokenHash,
+		token.ExpiresAt.UnixNano(), token.CreatedAt.UnixNano())
+	return err
+}
+
+// GetRefreshToken loads a token by its hash
+func (s *SQLiteRefreshTokenStore) GetRefreshToken(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	var (
+		token                model.RefreshToken
+		id, userID, familyID string
+		expiresAt, createdAt int64
+		revokedAt            sql.NullInt64
+	)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked_at
+		FROM refresh_tokens WHERE token_hash = ?`, tokenHash).
+		Scan(&id, &userID, &familyID, &token.TokenHash, &expiresAt, &createdAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.RefreshToken{}, ErrTokenNotFound
+	}
+	if err != nil {
+		return model.RefreshToken{}, err
+	}
+
+	if token.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return model.RefreshToken{}, err
+	}
+	if token.UserID, err = primitive.ObjectIDFromHex(userID); err != nil {
+		return model.RefreshToken{}, err
+	}
+	if token.FamilyID, err = primitive.ObjectIDFromHex(familyID); err != nil {
+		return model.RefreshToken{}, err
+	}
+	token.ExpiresAt = time.Unix(0, expiresAt)
+	token.CreatedAt = time.Unix(0, createdAt)
+	token.RevokedAt = nullTime(revokedAt)
+	return token, nil
+}
+
+// RevokeRefreshToken revokes the token only if it is still active
+func (s *SQLiteRefreshTokenStore) RevokeRefreshToken(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`,
+		time.Now().UnixNano(), id.Hex())
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// RevokeRefreshFamily revokes every active token of the family
+func (s *SQLiteRefreshTokenStore) RevokeRefreshFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`,
+		time.Now().UnixNano(), familyID.Hex())
+	return err
+}
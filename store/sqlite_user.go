@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLiteUserStore keeps users in the same database file as the tasks
+type SQLiteUserStore struct {
+	db *sql.DB
+}
+
+// CreateUser inserts the user; the UNIQUE constraint on email rejects duplicates
+func (s *SQLiteUserStore) CreateUser(ctx context.Context, user *model.User) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO users (id, name, email, password_hash, role, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		user.ID.Hex(), user.Name, user.Email, user.PasswordHash, string(user.EffectiveRole()),
+		user.Metadata.CreatedAt.UnixNano(), user.Metadata.UpdatedAt.UnixNano())
+	if err != nil && isUniqueViolation(err) {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// GetUserByID loads a user by ID
+func (s *SQLiteUserStore) GetUserByID(ctx context.Context, id primitive.ObjectID) (model.User, error) {
+	return s.getUser(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id.Hex())
+}
+
+// GetUserByEmail loads a user by email
+func (s *SQLiteUserStore) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	return s.getUser(ctx, `SELECT `+userColumns+` FROM users WHERE email = ?`, email)
+}
+
+// userColumns lists the columns getUser scans, in order
+const userColumns = `id, name, email, password_hash, role, created_at, updated_at`
+
+// ListUsers returns one page of users in sign-up order
+func (s *SQLiteUserStore) ListUsers(ctx context.Context, opts ListOptions) ([]model.User, int64, error) {
+	var total int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+userColumns+` FROM users ORDER BY created_at, id LIMIT ? OFFSET ?`, opts.Limit, opts.Skip())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+// UpdateUserRole changes the role column and returns the updated user
+func (s *SQLiteUserStore) UpdateUserRole(ctx context.Context, id primitive.ObjectID, role model.Role) (model.User, error) {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE users SET role = ?, updated_at = ? WHERE id = ?`, string(role), time.Now().UnixNano(), id.Hex())
+	if err != nil {
+		return model.User{}, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return model.User{}, err
+	}
+	if affected == 0 {
+		return model.User{}, ErrUserNotFound
+	}
+	return s.GetUserByID(ctx, id)
+}
+
+// DeleteUser removes the user; their tasks, tokens and keys cascade
+func (s *SQLiteUserStore) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id.Hex())
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// getUser runs a single-row query and scans it into a model.User
+func (s *SQLiteUserStore) getUser(ctx context.Context, query string, args ...interface{}) (model.User, error) {
+	user, err := scanUser(s.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, ErrUserNotFound
+	}
+	return user, err
+}
+
+// scanUser reads one row of userColumns into a model.User
+func scanUser(row rowScanner) (model.User, error) {
+	var (
+		user                 model.User
+		id, role             string
+		createdAt, updatedAt int64
+	)
+	err := row.Scan(&id, &user.Name, &user.Email, &user.PasswordHash, &role, &createdAt, &updatedAt)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return model.User{}, err
+	}
+	user.Role = model.Role(role)
+	user.Metadata.CreatedAt = time.Unix(0, createdAt)
+	user.Metadata.UpdatedAt = time.Unix(0, updatedAt)
+	return user, nil
+}
+
+// isUniqueViolation reports whether SQLite rejected a write because of a UNIQUE constraint
+// Matching on the message keeps us independent from the driver's error types
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,851 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrNotFound is returned by a store when no task matches the requested ID
+	// Handlers check for it with errors.Is to decide between a 404 and a 500
+	ErrNotFound = errors.New("task not found")
+
+	// ErrUserNotFound is returned when no user matches the requested ID or email
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrEmailTaken is returned when registering an email that already has an account
+	ErrEmailTaken = errors.New("email already registered")
+
+	// ErrTokenNotFound is returned when a refresh token is unknown or already revoked
+	ErrTokenNotFound = errors.New("refresh token not found")
+
+	// ErrAPIKeyNotFound is returned when an API key is unknown or belongs to someone else
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrDependencyNotFound is returned when removing a dependency the task doesn't have
+	ErrDependencyNotFound = errors.New("dependency not found")
+
+	// ErrDependencyCycle is returned when a new dependency would make a task block itself,
+	// directly or through other tasks
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+	// ErrProjectNotFound is returned when no project matches the requested ID inside the scope
+	ErrProjectNotFound = errors.New("project not found")
+
+	// ErrStatusConflict is returned when a status transition expected the task
+	// in another status than it is in, because someone else moved it first
+	ErrStatusConflict = errors.New("task status changed concurrently")
+
+	// ErrAttachmentNotFound is returned when removing an attachment the task doesn't have
+	ErrAttachmentNotFound = errors.New("attachment not found")
+
+	// ErrChecklistItemNotFound is returned when changing a checklist item the task doesn't have
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+
+	// ErrChecklistMismatch is returned when a new checklist order doesn't name every item exactly once,
+	// usually because an item was added or removed since the client loaded the task
+	ErrChecklistMismatch = errors.New("checklist items changed")
+
+	// ErrTimeEntryNotFound is returned when no time entry matches, or when the user has no running timer
+	ErrTimeEntryNotFound = errors.New("time entry not found")
+
+	// ErrTimerRunning is returned when starting a timer while the user already has one running
+	ErrTimerRunning = errors.New("a timer is already running")
+
+	// ErrCustomFieldNotFound is returned when no custom field matches the requested ID inside the scope
+	ErrCustomFieldNotFound = errors.New("custom field not found")
+
+	// ErrCustomFieldKeyTaken is returned when the owner already has a custom field with that key
+	ErrCustomFieldKeyTaken = errors.New("custom field key already in use")
+
+	// ErrCommentNotFound is returned when no comment of the task matches the requested ID
+	ErrCommentNotFound = errors.New("comment not found")
+
+	// ErrCommentDeleted is returned when editing or deleting a comment that was already deleted
+	ErrCommentDeleted = errors.New("comment was deleted")
+)
+
+// Stores bundles every store the API needs so they can be handed around together
+// Close releases whatever the backend holds open (connections, files)
+type Stores struct {
+	Tasks         TaskStore
+	Users         UserStore
+	RefreshTokens RefreshTokenStore
+	APIKeys       APIKeyStore
+	Projects      ProjectStore
+	Comments      CommentStore
+	TimeEntries   TimeEntryStore
+	CustomFields  CustomFieldStore
+	Close         func() error
+}
+
+// TaskStore describes everything the task handlers need from a storage backend
+// Handlers only talk to this interface, so the backend (MongoDB today) can be
+// swapped without touching any HTTP code
+// Every read and write takes a Scope so a user can only ever reach their own tasks
+type TaskStore interface {
+	// Create inserts a new task; the caller is responsible for setting ID, owner and timestamps
+	Create(ctx context.Context, task *model.Task) error
+	// Get returns a single task by ID or ErrNotFound
+	Get(ctx context.Context, scope Scope, id primitive.ObjectID) (model.Task, error)
+	// List returns one page of tasks together with the total number of tasks
+	List(ctx context.Context, scope Scope, opts ListOptions) ([]model.Task, int64, error)
+	// Update applies a partial update and returns the task as it is after the change
+	// An update carrying a Transition only applies while the task is still in Transition.From
+	Update(ctx context.Context, scope Scope, id primitive.ObjectID, update TaskUpdate) (model.Task, error)
+	// Delete removes a task by ID or returns ErrNotFound
+	Delete(ctx context.Context, scope Scope, id primitive.ObjectID) error
+	// AssignOwnerless gives every task without an owner to ownerID and returns how many changed
+	// It is the migration path for tasks created before tasks had owners
+	AssignOwnerless(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+	// DeleteByOwner removes every task of one owner, used when their account is deleted
+	DeleteByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+	// ListChildren returns the direct subtasks of a task, oldest first
+	ListChildren(ctx context.Context, scope Scope, parentID primitive.ObjectID) ([]model.Task, error)
+	// DetachChildren turns the direct subtasks of a task into top-level tasks
+	DetachChildren(ctx context.Context, scope Scope, parentID primitive.ObjectID) (int64, error)
+	// DeleteTree removes a task together with its subtasks at every depth
+	// It returns how many tasks were deleted, or ErrNotFound if the task itself doesn't exist
+	DeleteTree(ctx context.Context, scope Scope, id primitive.ObjectID) (int64, error)
+	// GetMany loads the tasks with the given IDs that exist inside the scope, in no particular order
+	GetMany(ctx context.Context, scope Scope, ids []primitive.ObjectID) ([]model.Task, error)
+	// AddDependency records that blockerID blocks id; adding an existing edge changes nothing
+	// It returns ErrDependencyCycle when id already blocks blockerID at any depth; the check and the write
+	// are one step, so two requests adding opposite edges at the same time can't both succeed
+	AddDependency(ctx context.Context, scope Scope, id, blockerID primitive.ObjectID) (model.Task, error)
+	// RemoveDependency deletes the edge, or returns ErrDependencyNotFound if there was none
+	RemoveDependency(ctx context.Context, scope Scope, id, blockerID primitive.ObjectID) (model.Task, error)
+	// CountByProject counts the open and completed tasks of each project in projectIDs
+	// Projects without tasks are missing from the map
+	CountByProject(ctx context.Context, scope Scope, projectIDs []primitive.ObjectID) (map[primitive.ObjectID]model.ProjectCounts, error)
+	// DetachProject removes every task from a project, used before the project is deleted
+	DetachProject(ctx context.Context, scope Scope, projectID primitive.ObjectID) (int64, error)
+	// SearchTasks finds the tasks whose title or description match the query, best first, at most limit of them
+	SearchTasks(ctx context.Context, scope Scope, query TextQuery, limit int) ([]TextMatch, error)
+	// ClearCustomField removes the value of one custom field from every task, used before its definition is deleted
+	ClearCustomField(ctx context.Context, scope Scope, key string) (int64, error)
+	// AddAttachment records the metadata of a file uploaded to the task
+	AddAttachment(ctx context.Context, scope Scope, id primitive.ObjectID, attachment model.Attachment) (model.Task, error)
+	// RemoveAttachment deletes the metadata, or returns ErrAttachmentNotFound if the task has no such attachment
+	RemoveAttachment(ctx context.Context, scope Scope, id, attachmentID primitive.ObjectID) (model.Task, error)
+	// AddChecklistItem inserts an item at position (0-based); a position past the end appends it
+	AddChecklistItem(ctx context.Context, scope Scope, id primitive.ObjectID, item model.ChecklistItem, position int) (model.Task, error)
+	// UpdateChecklistItem changes one item, or returns ErrChecklistItemNotFound if the task has no such item
+	UpdateChecklistItem(ctx context.Context, scope Scope, id, itemID primitive.ObjectID, update ChecklistItemUpdate) (model.Task, error)
+	// RemoveChecklistItem deletes one item, or returns ErrChecklistItemNotFound if the task has no such item
+	RemoveChecklistItem(ctx context.Context, scope Scope, id, itemID primitive.ObjectID) (model.Task, error)
+	// ReorderChecklist puts the items in the order of itemIDs, which must name every item exactly once,
+	// or returns ErrChecklistMismatch
+	ReorderChecklist(ctx context.Context, scope Scope, id primitive.ObjectID, itemIDs []primitive.ObjectID) (model.Task, error)
+}
+
+// ChecklistItemUpdate holds the fields a PATCH request may change on a checklist item
+// Like TaskUpdate, a nil pointer means "leave this field alone"
+type ChecklistItemUpdate struct {
+	Text *string `json:"text"`
+	Done *bool   `json:"done"`
+}
+
+// Apply copies the fields that are set onto the item; checking an item records when
+func (u ChecklistItemUpdate) Apply(item *model.ChecklistItem, now time.Time) {
+	if u.Text != nil {
+		item.Text = *u.Text
+	}
+	if u.Done != nil {
+		item.Done = *u.Done
+		item.DoneAt = nil
+		if *u.Done {
+			item.DoneAt = &now
+		}
+	}
+}
+
+// Scope limits a store call to the tasks of one owner
+// A task outside the scope behaves exactly like a missing one (ErrNotFound),
+// so the API never reveals that another user's task exists
+type Scope struct {
+	OwnerID   primitive.ObjectID
+	AllOwners bool // Only admin routes build a scope with this set
+}
+
+// OwnedBy returns the scope for tasks owned by the given user
+func OwnedBy(ownerID primitive.ObjectID) Scope {
+	return Scope{OwnerID: ownerID}
+}
+
+// AnyOwner returns a scope that reaches every user's tasks
+func AnyOwner() Scope {
+	return Scope{AllOwners: true}
+}
+
+// Allows reports whether the task is inside the scope
+// Stores that filter in Go (like the in-memory store) use it directly
+func (s Scope) Allows(task model.Task) bool {
+	return s.AllowsOwner(task.OwnerID)
+}
+
+// AllowsOwner reports whether records owned by ownerID are inside the scope
+func (s Scope) AllowsOwner(ownerID primitive.ObjectID) bool {
+	return s.AllOwners || ownerID == s.OwnerID
+}
+
+// blocksTransitively reports whether id blocks blockerID, directly or through other tasks,
+// which is when "blockerID blocks id" would close a loop
+// It walks up the blockers of blockerID one level at a time with load, which returns the tasks with
+// the given IDs; the walk follows every edge whoever owns the tasks, since a loop is a loop for everyone
+func blocksTransitively(ctx context.Context, load func(context.Context, []primitive.ObjectID) ([]model.Task, error), id, blockerID primitive.ObjectID) (bool, error) {
+	if id == blockerID {
+		return true, nil
+	}
+
+	seen := map[primitive.ObjectID]bool{blockerID: true}
+	level := []primitive.ObjectID{blockerID}
+	for len(level) > 0 {
+		tasks, err := load(ctx, level)
+		if err != nil {
+			return false, err
+		}
+
+		level = nil
+		for _, task := range tasks {
+			for _, upstream := range task.BlockedBy {
+				if upstream == id {
+					return true, nil
+				}
+				if !seen[upstream] {
+					seen[upstream] = true
+					level = append(level, upstream)
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// UserStore persists user accounts
+// Emails are unique: CreateUser returns ErrEmailTaken for a duplicate
+type UserStore interface {
+	CreateUser(ctx context.Context, user *model.User) error
+	GetUserByID(ctx context.Context, id primitive.ObjectID) (model.User, error)
+	GetUserByEmail(ctx context.Context, email string) (model.User, error)
+	// ListUsers returns one page of users, oldest first, and the total number of users
+	ListUsers(ctx context.Context, opts ListOptions) ([]model.User, int64, error)
+	// UpdateUserRole changes a user's role and returns the updated user
+	UpdateUserRole(ctx context.Context, id primitive.ObjectID, role model.Role) (model.User, error)
+	// DeleteUser removes a user account or returns ErrUserNotFound
+	DeleteUser(ctx context.Context, id primitive.ObjectID) error
+}
+
+// RefreshTokenStore persists refresh tokens by the hash of their value
+type RefreshTokenStore interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	// GetRefreshToken returns the token with this hash, revoked or not
+	GetRefreshToken(ctx context.Context, tokenHash string) (model.RefreshToken, error)
+	// RevokeRefreshToken marks a still active token as revoked
+	// It returns ErrTokenNotFound if the token was already revoked, which makes
+	// "check then revoke" safe when two requests race with the same token
+	RevokeRefreshToken(ctx context.Context, id primitive.ObjectID) error
+	// RevokeRefreshFamily revokes every token created from the same login
+	RevokeRefreshFamily(ctx context.Context, familyID primitive.ObjectID) error
+}
+
+// APIKeyStore persists API keys
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *model.APIKey) error
+	// ListAPIKeys returns every key of a user, newest first, revoked ones included
+	ListAPIKeys(ctx context.Context, userID primitive.ObjectID) ([]model.APIKey, error)
+	// GetAPIKeyByHash finds a key by the hash of its value, revoked or not
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (model.APIKey, error)
+	// RevokeAPIKey revokes one of the user's keys and returns it
+	RevokeAPIKey(ctx context.Context, userID, id primitive.ObjectID) (model.APIKey, error)
+	// TouchAPIKey records when a key was last used
+	TouchAPIKey(ctx context.Context, id primitive.ObjectID, usedAt time.Time) error
+}
+
+// ProjectStore persists projects
+// Projects are scoped like tasks: a project outside the scope behaves like a missing one
+type ProjectStore interface {
+	CreateProject(ctx context.Context, project *model.Project) error
+	// GetProject returns one project or ErrProjectNotFound
+	GetProject(ctx context.Context, scope Scope, id primitive.ObjectID) (model.Project, error)
+	// ListProjects returns the projects in the scope, oldest first; archived ones only when asked for
+	ListProjects(ctx context.Context, scope Scope, includeArchived bool) ([]model.Project, error)
+	// UpdateProject applies a partial update and returns the project after the change
+	UpdateProject(ctx context.Context, scope Scope, id primitive.ObjectID, update ProjectUpdate) (model.Project, error)
+	// DeleteProject removes a project or returns ErrProjectNotFound
+	DeleteProject(ctx context.Context, scope Scope, id primitive.ObjectID) error
+	// DeleteProjectsByOwner removes every project of one owner, used when their account is deleted
+	DeleteProjectsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+}
+
+// CommentStore persists the discussion threads of tasks
+// A comment is scoped through the owner of its task, so it is out of reach whenever its task is
+type CommentStore interface {
+	CreateComment(ctx context.Context, comment *model.Comment) error
+	// GetComment returns one comment of the task, with its history, or ErrCommentNotFound
+	GetComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID) (model.Comment, error)
+	// ListComments returns one page of the task's comments, oldest first, deleted ones included,
+	// and the total number of comments; the history is left out
+	ListComments(ctx context.Context, scope Scope, taskID primitive.ObjectID, opts ListOptions) ([]model.Comment, int64, error)
+	// EditComment replaces the body and appends the old one to the history,
+	// or returns ErrCommentDeleted if the comment was deleted
+	EditComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, body string, editedAt time.Time) (model.Comment, error)
+	// DeleteComment marks the comment deleted, or returns ErrCommentDeleted if it already was
+	DeleteComment(ctx context.Context, scope Scope, taskID, id primitive.ObjectID, deletedAt time.Time) (model.Comment, error)
+	// SearchComments finds the comments that match the query, best first, at most limit of them
+	// Deleted comments are never found
+	SearchComments(ctx context.Context, scope Scope, query TextQuery, limit int) ([]CommentMatch, error)
+	// DeleteCommentsByTasks removes every comment of the tasks for good, used when the tasks are deleted
+	DeleteCommentsByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error)
+	// DeleteCommentsByOwner removes every comment on one owner's tasks, used when their account is deleted
+	DeleteCommentsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+}
+
+// TimeEntryStore persists the time tracked on tasks
+// Entries are scoped through the owner of their task like comments, timers belong to the user running them
+type TimeEntryStore interface {
+	// CreateTimeEntry stores a logged entry or a timer that was just started
+	// Starting a timer while the same user has one running returns ErrTimerRunning
+	CreateTimeEntry(ctx context.Context, entry *model.TimeEntry) error
+	// RunningTimer returns the user's running timer, or ErrTimeEntryNotFound when none runs
+	RunningTimer(ctx context.Context, userID primitive.ObjectID) (model.TimeEntry, error)
+	// StopTimer ends the user's running timer at endedAt, or returns ErrTimeEntryNotFound when none runs
+	StopTimer(ctx context.Context, userID primitive.ObjectID, endedAt time.Time) (model.TimeEntry, error)
+	// ListTimeEntries returns one page of the task's entries, most recent first, and the total number of entries
+	ListTimeEntries(ctx context.Context, scope Scope, taskID primitive.ObjectID, opts ListOptions) ([]model.TimeEntry, int64, error)
+	// DeleteTimeEntry removes one entry of the task or returns ErrTimeEntryNotFound
+	DeleteTimeEntry(ctx context.Context, scope Scope, taskID, id primitive.ObjectID) error
+	// SumTimeByTask adds up the stopped entries matching the filter, one total per task; running timers don't count
+	SumTimeByTask(ctx context.Context, scope Scope, filter TimeFilter) ([]model.TaskTime, error)
+	// DeleteTimeEntriesByTasks removes every entry of the tasks, used when the tasks are deleted
+	DeleteTimeEntriesByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error)
+	// DeleteTimeEntriesByOwner removes every entry on one owner's tasks, used when their account is deleted
+	DeleteTimeEntriesByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+}
+
+// TimeFilter narrows the entries SumTimeByTask adds up; the zero value matches every entry
+// Entries count for the range they started in
+type TimeFilter struct {
+	TaskIDs []primitive.ObjectID // only entries of these tasks, nil for every task
+	From    *time.Time           // started at or after this time
+	To      *time.Time           // started strictly before this time
+}
+
+// CustomFieldStore persists the custom field definitions
+// Definitions are scoped like projects: a definition outside the scope behaves like a missing one
+type CustomFieldStore interface {
+	// CreateCustomField stores a definition, or returns ErrCustomFieldKeyTaken when the owner already uses its key
+	CreateCustomField(ctx context.Context, field *model.CustomField) error
+	// GetCustomField returns one definition or ErrCustomFieldNotFound
+	GetCustomField(ctx context.Context, scope Scope, id primitive.ObjectID) (model.CustomField, error)
+	// ListCustomFields returns the definitions in the scope, oldest first
+	ListCustomFields(ctx context.Context, scope Scope) ([]model.CustomField, error)
+	// UpdateCustomField applies a partial update and returns the definition after the change
+	UpdateCustomField(ctx context.Context, scope Scope, id primitive.ObjectID, update CustomFieldUpdate) (model.CustomField, error)
+	// DeleteCustomField removes a definition or returns ErrCustomFieldNotFound
+	DeleteCustomField(ctx context.Context, scope Scope, id primitive.ObjectID) error
+	// DeleteCustomFieldsByOwner removes every definition of one owner, used when their account is deleted
+	DeleteCustomFieldsByOwner(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+}
+
+// CustomFieldUpdate holds the fields a PATCH request may change on a custom field
+// The key and the type can't change, values already stored depend on them
+type CustomFieldUpdate struct {
+	Name     *string   `json:"name"`
+	Options  *[]string `json:"options"`
+	Required *bool     `json:"required"`
+}
+
+// Apply copies every field that is set on the update onto the definition
+func (u CustomFieldUpdate) Apply(field *model.CustomField) {
+	if u.Name != nil {
+		field.Name = *u.Name
+	}
+	if u.Options != nil {
+		field.Options = append([]string(nil), (*u.Options)...)
+	}
+	if u.Required != nil {
+		field.Required = *u.Required
+	}
+}
+
+// ProjectUpdate holds the fields a PATCH request may change on a project
+// Like TaskUpdate, a nil pointer means "leave this field alone"
+type ProjectUpdate struct {
+	Name        *string `json:"name"`
+	Color       *string `json:"color"`
+	Description *string `json:"description"`
+	Archived    *bool   `json:"archived"`
+}
+
+// Apply copies every field that is set on the update onto the project
+func (u ProjectUpdate) Apply(project *model.Project) {
+	if u.Name != nil {
+		project.Name = *u.Name
+	}
+	if u.Color != nil {
+		project.Color = *u.Color
+	}
+	if u.Description != nil {
+		project.Description = *u.Description
+	}
+	if u.Archived != nil {
+		project.Archived = *u.Archived
+	}
+}
+
+// ListOptions controls which page of tasks List returns
+type ListOptions struct {
+	Page   int        // 1-based page number
+	Limit  int        // maximum number of tasks per page
+	Filter TaskFilter // only tasks matching the filter are counted and returned
+	Sort   TaskSort   // the order of the tasks, DefaultSort when empty
+	From   *Position  // when set, the page starts next to this position instead of at Page
+}
+
+// Position is a place in a sorted task list, next to which a page can start
+// Paging from a position skips nothing, so tasks added or removed meanwhile don't shift the later pages
+type Position struct {
+	Values []interface{} // SortKey.Value of the task at the position, for every key of the sort
+	ID     primitive.ObjectID
+	Before bool // the page holds the tasks just before the position rather than just after it
+}
+
+// PositionOf returns the position of task in a list sorted by keys; the page after it starts with the next task
+func PositionOf(keys TaskSort, task model.Task) Position {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = key.Value(task)
+	}
+	return Position{Values: values, ID: task.ID}
+}
+
+// TaskSort orders a task list by one or more keys, the first one deciding first
+// Tasks without a value come first in ascending order and last in descending order, like NULLs in SQL
+// The ID breaks whatever ties remain, newest first, so the order never changes between calls
+type TaskSort []SortKey
+
+// SortKey is one key of a TaskSort
+type SortKey struct {
+	Field  string // One of SortFields, or the key of a custom field when Custom is set
+	Custom bool
+	Desc   bool
+}
+
+// SortFields are the task fields a list can be sorted by
+// Priority sorts by rank, low before medium before high, and title compares bytes, so "B" comes before "a"
+var SortFields = []string{"created_at", "updated_at", "start_at", "due_at", "priority", "title", "points", "estimate_minutes"}
+
+// DefaultSort is the order of a list that asks for none: newest first
+var DefaultSort = TaskSort{{Field: "created_at", Desc: true}}
+
+// OrDefault returns s, or DefaultSort when s is empty
+func (s TaskSort) OrDefault() TaskSort {
+	if len(s) == 0 {
+		return DefaultSort
+	}
+	return s
+}
+
+// Value returns what the key sorts task by: a time.Time, a string, a float64, a custom field value,
+// or nil when the task has no value
+func (k SortKey) Value(task model.Task) interface{} {
+	if k.Custom {
+		return task.Fields[k.Field]
+	}
+	switch k.Field {
+	case "created_at":
+		return task.Metadata.CreatedAt
+	case "updated_at":
+		return task.Metadata.UpdatedAt
+	case "start_at":
+		if task.StartAt != nil {
+			return *task.StartAt
+		}
+	case "due_at":
+		if task.DueAt != nil {
+			return *task.DueAt
+		}
+	case "priority":
+		if rank := task.Priority.Rank(); rank >= 0 {
+			return float64(rank)
+		}
+	case "title":
+		return task.Title
+	case "points":
+		if task.Points != nil {
+			return float64(*task.Points)
+		}
+	case "estimate_minutes":
+		if task.Estimate != nil {
+			return float64(*task.Estimate)
+		}
+	}
+	return nil
+}
+
+// TaskFilter narrows a task list down; the zero value matches every task
+// Tasks without a due date never match the due date filters
+type TaskFilter struct {
+	DueBefore *time.Time // due_at strictly before this time
+	DueAfter  *time.Time // due_at strictly after this time
+	Overdue   *bool      // open and past due (true) or not (false)
+	Now       time.Time  // the "current time" Overdue is measured against
+
+	ProjectID *primitive.ObjectID // only tasks in this project
+	NoProject bool                // only tasks outside any project
+	Status    *model.Status       // only tasks in this status
+
+	Tags       []string         // only tasks with any of these tags, or all of them with AllTags
+	AllTags    bool             // Tags must all be on the task instead of any one of them
+	Priorities []model.Priority // only tasks with one of these priorities
+	Completed  *bool            // only completed (true) or open (false) tasks
+	Title      string           // only tasks whose title contains this text, ignoring case
+
+	CreatedBefore *time.Time // metadata.created_at strictly before this time
+	CreatedAfter  *time.Time // metadata.created_at strictly after this time
+	UpdatedBefore *time.Time // metadata.updated_at strictly before this time
+	UpdatedAfter  *time.Time // metadata.updated_at strictly after this time
+
+	Fields []FieldCondition // only tasks whose custom field values pass every condition
+}
+
+// FieldCondition compares one custom field value of a task with Value
+// Eq on a multi_select field matches tasks whose list contains Value
+type FieldCondition struct {
+	Key     string
+	Op      FieldOp
+	Value   interface{} // string, float64 or bool, normalized like stored values
+	Missing bool        // also match tasks without a value, like an unchecked checkbox
+}
+
+// FieldOp is how a FieldCondition compares
+type FieldOp string
+
+const (
+	FieldEq  FieldOp = "eq"
+	FieldGt  FieldOp = "gt"
+	FieldGte FieldOp = "gte"
+	FieldLt  FieldOp = "lt"
+	FieldLte FieldOp = "lte"
+)
+
+// Matches reports whether the task passes the filter
+// Stores that filter in Go (like the in-memory store) use it directly
+func (f TaskFilter) Matches(task model.Task) bool {
+	if f.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.DueAfter != nil && (task.DueAt == nil || !task.DueAt.After(*f.DueAfter)) {
+		return false
+	}
+	if f.Overdue != nil && task.IsOverdue(f.Now) != *f.Overdue {
+		return false
+	}
+	if f.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *f.ProjectID) {
+		return false
+	}
+	if f.NoProject && task.ProjectID != nil {
+		return false
+	}
+	if f.Status != nil && task.EffectiveStatus() != *f.Status {
+		return false
+	}
+	if len(f.Tags) > 0 && !matchesTags(task.Tags, f.Tags, f.AllTags) {
+		return false
+	}
+	if len(f.Priorities) > 0 && !containsPriority(f.Priorities, task.Priority) {
+		return false
+	}
+	if f.Completed != nil && task.Completed != *f.Completed {
+		return false
+	}
+	if f.Title != "" && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(f.Title)) {
+		return false
+	}
+	if f.CreatedBefore != nil && !task.Metadata.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.CreatedAfter != nil && !task.Metadata.CreatedAt.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.UpdatedBefore != nil && !task.Metadata.UpdatedAt.Before(*f.UpdatedBefore) {
+		return false
+	}
+	if f.UpdatedAfter != nil && !task.Metadata.UpdatedAt.After(*f.UpdatedAfter) {
+		return false
+	}
+	for _, condition := range f.Fields {
+		if !condition.Matches(task.Fields) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesTags reports whether the task has any of the wanted tags, or all of them when all is set
+func matchesTags(tags, wanted []string, all bool) bool {
+	has := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		has[tag] = true
+	}
+	for _, tag := range wanted {
+		if has[tag] && !all {
+			return true
+		}
+		if !has[tag] && all {
+			return false
+		}
+	}
+	return all
+}
+
+// containsPriority reports whether priority is in the list
+func containsPriority(priorities []model.Priority, priority model.Priority) bool {
+	for _, p := range priorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the values pass the condition
+func (c FieldCondition) Matches(values model.FieldValues) bool {
+	value, ok := values[c.Key]
+	if !ok || value == nil {
+		return c.Missing
+	}
+	if list, ok := fieldList(value); ok {
+		for _, item := range list {
+			if cmp, ok := compareFieldValues(item, c.Value); ok && cmp == 0 && c.Op == FieldEq {
+				return true
+			}
+		}
+		return false
+	}
+
+	cmp, ok := compareFieldValues(value, c.Value)
+	if !ok {
+		return false
+	}
+	switch c.Op {
+	case FieldGt:
+		return cmp > 0
+	case FieldGte:
+		return cmp >= 0
+	case FieldLt:
+		return cmp < 0
+	case FieldLte:
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// compareFieldValues orders two custom field values, or two SortKey values, of the same type
+// ok is false when the values have different types and can't be compared
+func compareFieldValues(a, b interface{}) (cmp int, ok bool) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b), true
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			switch {
+			case a == b:
+				return 0, true
+			case b:
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+// fieldList returns the items of a multi_select value, which is a []string when set by the
+// handlers and a generic list once decoded by a database driver
+func fieldList(value interface{}) ([]interface{}, bool) {
+	switch list := value.(type) {
+	case []string:
+		items := make([]interface{}, len(list))
+		for i, item := range list {
+			items[i] = item
+		}
+		return items, true
+	case []interface{}:
+		return list, true
+	case primitive.A:
+		return list, true
+	}
+	return nil, false
+}
+
+// Skip returns how many tasks come before the requested page
+func (o ListOptions) Skip() int {
+	return (o.Page - 1) * o.Limit
+}
+
+// TaskUpdate holds the fields a PATCH request may change
+// A nil pointer means "leave this field alone", which is how we tell
+// "not sent" apart from "set to the zero value"
+type TaskUpdate struct {
+	Title       *string                      `json:"title"`
+	Description *string                      `json:"description"`
+	Image       *[]string                    `json:"image"`
+	Priority    *model.Priority              `json:"priority"`
+	Tags        *[]string                    `json:"tags"`
+	Completed   *bool                        `json:"completed"`
+	StartAt     Optional[time.Time]          `json:"start_at"`
+	DueAt       Optional[time.Time]          `json:"due_at"`
+	Recurrence  Optional[model.Recurrence]   `json:"recurrence"`
+	ParentID    Optional[primitive.ObjectID] `json:"parent_id"`
+	ProjectID   Optional[primitive.ObjectID] `json:"project_id"`
+	Points      Optional[int]                `json:"points"`
+	Estimate    Optional[int]                `json:"estimate_minutes"`
+	Fields      model.FieldValues            `json:"custom_fields"` // Merged into the task's values; null clears a field
+
+	// Transition moves the task to another status; it is never read from the body,
+	// the handlers set it after checking the move against the workflow
+	Transition *StatusChange `json:"-"`
+
+	// Rank is the task's new board position, only set by the board's move endpoint
+	Rank *string `json:"-"`
+}
+
+// mergeFields returns a new map with the changes applied on top of the current values
+// A nil change removes the value; nil is returned when no value is left
+func mergeFields(current, changes model.FieldValues) model.FieldValues {
+	merged := make(model.FieldValues, len(current)+len(changes))
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// StatusChange is a move from one status to another at a given time
+// From is what the handler saw, so a store can refuse the change with
+// ErrStatusConflict when the task was moved by someone else in the meantime
+type StatusChange struct {
+	From model.Status
+	To   model.Status
+	At   time.Time
+}
+
+// Optional is a PATCH field that can also be cleared by sending null
+// Set tells "not sent" apart from "sent as null" (Set with a nil Value)
+type Optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// UnmarshalJSON is only called when the field is present in the body
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+	return nil
+}
+
+// Apply copies every field that is set on the update onto the task
+// Backends that don't have a native partial update (like the in-memory store)
+// load the task, call Apply and save it back
+func (u TaskUpdate) Apply(task *model.Task) {
+	if u.Title != nil {
+		task.Title = *u.Title
+	}
+	if u.Description != nil {
+		task.Description = *u.Description
+	}
+	if u.Image != nil {
+		task.Image = append([]string(nil), (*u.Image)...)
+	}
+	if u.Priority != nil {
+		task.Priority = *u.Priority
+	}
+	if u.Tags != nil {
+		task.Tags = append([]string(nil), (*u.Tags)...)
+	}
+	if u.Completed != nil {
+		task.Completed = *u.Completed
+	}
+	if u.StartAt.Set {
+		task.StartAt = u.StartAt.Value
+	}
+	if u.DueAt.Set {
+		task.DueAt = u.DueAt.Value
+	}
+	if u.Recurrence.Set {
+		task.Recurrence = u.Recurrence.Value
+	}
+	if u.ParentID.Set {
+		task.ParentID = u.ParentID.Value
+	}
+	if u.ProjectID.Set {
+		task.ProjectID = u.ProjectID.Value
+	}
+	if u.Points.Set {
+		task.Points = u.Points.Value
+	}
+	if u.Estimate.Set {
+		task.Estimate = u.Estimate.Value
+	}
+	if u.Fields != nil {
+		task.Fields = mergeFields(task.Fields, u.Fields)
+	}
+	if u.Rank != nil {
+		task.Rank = *u.Rank
+	}
+	if u.Transition != nil {
+		task.Status = u.Transition.To
+		task.Completed = u.Transition.To.IsTerminal()
+
+		// Copy the map so the caller's task doesn't share it with the result
+		since := make(model.StatusTimes, len(task.StatusSince)+1)
+		for status, at := range task.StatusSince {
+			since[status] = at
+		}
+		since[u.Transition.To] = u.Transition.At
+		task.StatusSince = since
+	}
+}
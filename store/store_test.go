@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testStores returns the stores of every backend that runs without an external service,
+// plus MongoDB when TEST_MONGODB_URI is set; point that at a throwaway server, the stores write to its "Go" database
+func testStores(t *testing.T) map[string]Stores {
+	t.Helper()
+	sqlite, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	stores := map[string]Stores{"memory": OpenMemory(), "sqlite": sqlite}
+
+	if uri := os.Getenv("TEST_MONGODB_URI"); uri != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { client.Disconnect(context.Background()) })
+		if stores["mongo"], err = OpenMongo(ctx, client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return stores
+}
+
+// createOwner adds the user that test tasks belong to, which SQLite's foreign keys insist on
+// The user and their tasks are removed again when the test ends, so a shared MongoDB starts clean next time
+func createOwner(t *testing.T, stores Stores, id primitive.ObjectID) {
+	t.Helper()
+	owner := model.User{ID: id, Name: "Owner", Email: id.Hex() + "@example.com", Role: model.RoleMember}
+	if err := stores.Users.CreateUser(context.Background(), &owner); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		stores.Tasks.DeleteByOwner(context.Background(), id)
+		stores.Users.DeleteUser(context.Background(), id)
+	})
+}
+
+// createTask stores a minimal valid task of owner and returns it
+func createTask(t *testing.T, stores Stores, owner primitive.ObjectID, title string) model.Task {
+	t.Helper()
+	task := model.Task{
+		ID: primitive.NewObjectID(), OwnerID: owner, Title: title, Description: "d", Priority: model.PriorityLow,
+		Tags: []string{"t"}, Image: []string{}, Status: model.StatusTodo,
+	}
+	if err := stores.Tasks.Create(context.Background(), &task); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
@@ -0,0 +1,305 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Every store ranks a word in a title above the same word in a description, and both above a comment
+const (
+	titleWeight       = 10
+	descriptionWeight = 3
+	commentWeight     = 1
+)
+
+// TextQuery is a full-text search as typed by the user, read like MongoDB's $search strings:
+// - words: a document matches when it contains any of them
+// - "quoted phrases": a document must contain every phrase; words then only add to the relevance
+// - -word or -"phrase": documents containing them never match
+// Matching ignores case and the common English endings, so "fixes" finds "fixed"
+type TextQuery struct {
+	Terms    []string
+	Phrases  []string
+	Excluded []string // Words and phrases alike
+}
+
+// TextMatch is a task found by a search together with its relevance, higher is better
+// Scores come from the store's own ranking and can only be compared within one store
+type TextMatch struct {
+	TaskID primitive.ObjectID
+	Score  float64
+}
+
+// CommentMatch is a comment found by a search
+type CommentMatch struct {
+	TaskID    primitive.ObjectID
+	CommentID primitive.ObjectID
+	Body      string
+	Score     float64
+}
+
+// ParseTextQuery splits the search string into words, phrases and exclusions
+// A quote left open runs to the end of the string; a lone "-" is ignored
+func ParseTextQuery(q string) TextQuery {
+	var query TextQuery
+	for rest := strings.TrimSpace(q); rest != ""; rest = strings.TrimLeftFunc(rest, unicode.IsSpace) {
+		excluded := strings.HasPrefix(rest, "-")
+		if excluded {
+			rest = rest[1:]
+		}
+
+		var part string
+		phrase := strings.HasPrefix(rest, `"`)
+		if phrase {
+			var closed bool
+			part, rest, closed = strings.Cut(rest[1:], `"`)
+			if !closed {
+				rest = ""
+			}
+			part = strings.Join(strings.Fields(part), " ")
+		} else {
+			end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(rest)
+			}
+			part, rest = rest[:end], rest[end:]
+		}
+		if len(textTokens(part)) == 0 {
+			continue // Punctuation alone can't match anything
+		}
+
+		switch {
+		case excluded:
+			query.Excluded = append(query.Excluded, part)
+		case phrase:
+			query.Phrases = append(query.Phrases, part)
+		default:
+			query.Terms = append(query.Terms, part)
+		}
+	}
+	return query
+}
+
+// Empty reports whether nothing would match: a search of exclusions only finds nothing
+func (q TextQuery) Empty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// MongoSearch writes the query back as a $search string, with every phrase quoted
+func (q TextQuery) MongoSearch() string {
+	parts := make([]string, 0, len(q.Terms)+len(q.Phrases)+len(q.Excluded))
+	parts = append(parts, q.Terms...)
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `"`+phrase+`"`)
+	}
+	for _, excluded := range q.Excluded {
+		if strings.ContainsFunc(excluded, unicode.IsSpace) {
+			excluded = `"` + excluded + `"`
+		}
+		parts = append(parts, "-"+excluded)
+	}
+	return strings.Join(parts, " ")
+}
+
+// FTSMatch writes the query as an SQLite FTS5 MATCH expression
+// Every word and phrase is quoted, so nothing the user types is read as FTS5 syntax
+func (q TextQuery) FTSMatch() string {
+	quote := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	// Phrases must all be there; without phrases any word will do
+	parts, operator := q.Terms, " OR "
+	if len(q.Phrases) > 0 {
+		parts, operator = q.Phrases, " AND "
+	}
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = quote(part)
+	}
+
+	match := "(" + strings.Join(quoted, operator) + ")"
+	for _, excluded := range q.Excluded {
+		match += " NOT " + quote(excluded)
+	}
+	return match
+}
+
+// WeightedText is one field of a document with how much a match in it counts
+type WeightedText struct {
+	Text   string
+	Weight float64
+}
+
+// Score matches the query against the fields of one document, for stores that search in Go
+// Every word or phrase found adds the weight of its field; ok is false when the document doesn't match
+func (q TextQuery) Score(fields ...WeightedText) (score float64, ok bool) {
+	docs := make([][]string, len(fields))
+	for i, field := range fields {
+		docs[i] = stems(textTokens(field.Text))
+	}
+
+	for _, excluded := range q.Excluded {
+		for _, doc := range docs {
+			if countSequence(doc, stems(textTokens(excluded))) > 0 {
+				return 0, false
+			}
+		}
+	}
+
+	for _, phrase := range q.Phrases {
+		found := 0
+		for i, doc := range docs {
+			n := countSequence(doc, stems(textTokens(phrase)))
+			found += n
+			score += float64(n) * fields[i].Weight
+		}
+		if found == 0 {
+			return 0, false
+		}
+	}
+
+	for _, term := range q.Terms {
+		for i, doc := range docs {
+			score += float64(countSequence(doc, stems(textTokens(term)))) * fields[i].Weight
+		}
+	}
+	return score, score > 0
+}
+
+// Excludes reports whether text contains one of the excluded words or phrases
+func (q TextQuery) Excludes(text string) bool {
+	doc := stems(textTokens(text))
+	for _, excluded := range q.Excluded {
+		if countSequence(doc, stems(textTokens(excluded))) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Highlights returns the byte ranges of the words in text that the query matched, in order
+func (q TextQuery) Highlights(text string) [][2]int {
+	wanted := map[string]bool{}
+	for _, part := range append(append([]string{}, q.Terms...), q.Phrases...) {
+		for _, stem := range stems(textTokens(part)) {
+			wanted[stem] = true
+		}
+	}
+
+	var spans [][2]int
+	for _, token := range textTokens(text) {
+		if wanted[stem(token.word)] {
+			spans = append(spans, [2]int{token.start, token.end})
+		}
+	}
+	return spans
+}
+
+// bestTextMatches orders matches best first, newest first on equal scores, and keeps at most limit
+func bestTextMatches(matches []TextMatch, limit int) []TextMatch {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].TaskID.Hex() > matches[j].TaskID.Hex()
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// bestCommentMatches is bestTextMatches for comments
+func bestCommentMatches(matches []CommentMatch, limit int) []CommentMatch {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].CommentID.Hex() > matches[j].CommentID.Hex()
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// textToken is one word of a text with where it sits, in bytes
+type textToken struct {
+	word       string
+	start, end int
+}
+
+// textTokens splits text into lowercase words made of letters and digits
+func textTokens(text string) []textToken {
+	var tokens []textToken
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && start < 0 {
+			start = i
+		}
+		if !inWord && start >= 0 {
+			tokens = append(tokens, textToken{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, textToken{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+// stems returns the stem of every token
+func stems(tokens []textToken) []string {
+	out := make([]string, len(tokens))
+	for i, token := range tokens {
+		out[i] = stem(token.word)
+	}
+	return out
+}
+
+// stem strips the common English endings, a rough stand-in for the stemmers of MongoDB and FTS5
+func stem(word string) string {
+	if utf8.RuneCountInString(word) <= 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "ing") && len(word) > 5:
+		return strings.TrimSuffix(word, "ing")
+	case strings.HasSuffix(word, "ed") && len(word) > 4:
+		return strings.TrimSuffix(word, "ed")
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// countSequence counts how often the words of seq appear one after the other in doc
+func countSequence(doc, seq []string) int {
+	if len(seq) == 0 {
+		return 0
+	}
+	count := 0
+	for i := 0; i+len(seq) <= len(doc); i++ {
+		match := true
+		for j := range seq {
+			if doc[i+j] != seq[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseTextQuery(t *testing.T) {
+	tests := []struct {
+		q    string
+		want TextQuery
+	}{
+		{"fix login", TextQuery{Terms: []string{"fix", "login"}}},
+		{`"login  page" bug`, TextQuery{Terms: []string{"bug"}, Phrases: []string{"login page"}}},
+		{`-draft -"old idea" x`, TextQuery{Terms: []string{"x"}, Excluded: []string{"draft", "old idea"}}},
+		{`"open quote runs`, TextQuery{Phrases: []string{"open quote runs"}}},
+		{`a"b"`, TextQuery{Terms: []string{"a"}, Phrases: []string{"b"}}},
+		{"- lone", TextQuery{Terms: []string{"lone"}}},
+		{`!!! "" - ...`, TextQuery{}},
+	}
+	for _, tt := range tests {
+		if got := ParseTextQuery(tt.q); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTextQuery(%q) = %+v, want %+v", tt.q, got, tt.want)
+		}
+	}
+
+	if !ParseTextQuery("-only -exclusions").Empty() {
+		t.Error("a query of exclusions only should be empty")
+	}
+}
+
+func TestTextQueryForStores(t *testing.T) {
+	q := ParseTextQuery(`fix "login page" -draft -"old idea"`)
+	if got, want := q.MongoSearch(), `fix "login page" -draft -"old idea"`; got != want {
+		t.Errorf("MongoSearch = %s, want %s", got, want)
+	}
+	// With a phrase every phrase must match and the words only rank
+	if got, want := q.FTSMatch(), `("login page") NOT "draft" NOT "old idea"`; got != want {
+		t.Errorf("FTSMatch = %s, want %s", got, want)
+	}
+	if got, want := ParseTextQuery("fix login").FTSMatch(), `("fix" OR "login")`; got != want {
+		t.Errorf("FTSMatch = %s, want %s", got, want)
+	}
+	// FTS5 operators typed by the user stay plain words
+	if got, want := ParseTextQuery("NEAR AND*").FTSMatch(), `("NEAR" OR "AND*")`; got != want {
+		t.Errorf("FTSMatch = %s, want %s", got, want)
+	}
+}
+
+func TestTextQueryScore(t *testing.T) {
+	title := WeightedText{Text: "Fix login bug", Weight: titleWeight}
+	description := WeightedText{Text: "Users see the login page fail", Weight: descriptionWeight}
+
+	tests := []struct {
+		q     string
+		score float64
+		ok    bool
+	}{
+		{"login", titleWeight + descriptionWeight, true},
+		{"fixes", titleWeight, true}, // Stems match
+		{"LOGIN", titleWeight + descriptionWeight, true},
+		{`"login page"`, descriptionWeight, true},
+		{`"page login"`, 0, false}, // Phrases keep their word order
+		{`"login page" fix`, descriptionWeight + titleWeight, true},
+		{`"login page" "dark mode"`, 0, false}, // Every phrase must be there
+		{"login -fail", 0, false},
+		{"login -failure", titleWeight + descriptionWeight, true},
+		{"missing", 0, false},
+		{"log", 0, false}, // Whole words only
+	}
+	for _, tt := range tests {
+		score, ok := ParseTextQuery(tt.q).Score(title, description)
+		if score != tt.score || ok != tt.ok {
+			t.Errorf("Score(%q) = %v, %v; want %v, %v", tt.q, score, ok, tt.score, tt.ok)
+		}
+	}
+}
+
+func TestTextQueryHighlights(t *testing.T) {
+	text := "Fixed the login; fixing later, logins too"
+	got := ParseTextQuery(`fix "login"`).Highlights(text)
+	want := [][2]int{{0, 5}, {10, 15}, {17, 23}, {31, 37}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Highlights = %v, want %v", got, want)
+	}
+	if got := ParseTextQuery("-fixed").Highlights(text); got != nil {
+		t.Errorf("exclusions highlighted %v", got)
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"fixes": "fix", "fixed": "fix", "fixing": "fix", "fix": "fix",
+		"stories": "story", "matches": "match", "classes": "class", "class": "class",
+		"tasks": "task", "need": "need", "sing": "sing", "bus": "bus", "wishes": "wish",
+	}
+	for word, want := range tests {
+		if got := stem(word); got != want {
+			t.Errorf("stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestTextTokens(t *testing.T) {
+	got := textTokens("Héllo, wörld! v2.0")
+	want := []textToken{{"héllo", 0, 6}, {"wörld", 8, 14}, {"v2", 16, 18}, {"0", 19, 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("textTokens = %v, want %v", got, want)
+	}
+}
+
+func TestSearchTasksOnEveryBackend(t *testing.T) {
+	ctx := context.Background()
+	for backend, stores := range testStores(t) {
+		owner, other := primitive.NewObjectID(), primitive.NewObjectID()
+		createOwner(t, stores, owner)
+		createOwner(t, stores, other)
+
+		inTitle := createTask(t, stores, owner, "Fix the login form")
+		inDescription := createTask(t, stores, owner, "Polish")
+		description := "Users fixing their login see errors"
+		if _, err := stores.Tasks.Update(ctx, OwnedBy(owner), inDescription.ID, TaskUpdate{Description: &description}); err != nil {
+			t.Fatal(err)
+		}
+		createTask(t, stores, owner, "Draft login copy")
+		createTask(t, stores, other, "Fix login for someone else")
+
+		ids := func(q string) []primitive.ObjectID {
+			t.Helper()
+			matches, err := stores.Tasks.SearchTasks(ctx, OwnedBy(owner), ParseTextQuery(q), 10)
+			if err != nil {
+				t.Fatalf("%s: search %q: %v", backend, q, err)
+			}
+			var ids []primitive.ObjectID
+			for _, match := range matches {
+				ids = append(ids, match.TaskID)
+			}
+			return ids
+		}
+
+		// A title match ranks above a description match, and other owners' tasks are never found
+		if got := ids("fixes login -draft"); !reflect.DeepEqual(got, []primitive.ObjectID{inTitle.ID, inDescription.ID}) {
+			t.Errorf("%s: fixes login -draft = %v, want the title match then the description match", backend, got)
+		}
+		if got := ids(`"login form"`); !reflect.DeepEqual(got, []primitive.ObjectID{inTitle.ID}) {
+			t.Errorf("%s: phrase = %v", backend, got)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// timer builds a running timer of user on task
+func timer(task model.Task, user primitive.ObjectID, startedAt time.Time) model.TimeEntry {
+	return model.TimeEntry{
+		ID: primitive.NewObjectID(), TaskID: task.ID, OwnerID: task.OwnerID, UserID: user,
+		StartedAt: startedAt, Running: true, Metadata: model.Metadata{CreatedAt: startedAt, UpdatedAt: startedAt},
+	}
+}
+
+func TestOneRunningTimerPerUser(t *testing.T) {
+	ctx := context.Background()
+	for backend, stores := range testStores(t) {
+		owner, other := primitive.NewObjectID(), primitive.NewObjectID()
+		createOwner(t, stores, owner)
+		createOwner(t, stores, other)
+		t.Cleanup(func() { stores.TimeEntries.DeleteTimeEntriesByOwner(ctx, owner) })
+		first, second := createTask(t, stores, owner, "first"), createTask(t, stores, owner, "second")
+		started := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+		if _, err := stores.TimeEntries.StopTimer(ctx, owner, time.Now()); !errors.Is(err, ErrTimeEntryNotFound) {
+			t.Errorf("%s: stop with no timer: %v, want ErrTimeEntryNotFound", backend, err)
+		}
+
+		running := timer(first, owner, started)
+		if err := stores.TimeEntries.CreateTimeEntry(ctx, &running); err != nil {
+			t.Fatalf("%s: start: %v", backend, err)
+		}
+		again := timer(second, owner, started)
+		if err := stores.TimeEntries.CreateTimeEntry(ctx, &again); !errors.Is(err, ErrTimerRunning) {
+			t.Errorf("%s: second timer of the same user: %v, want ErrTimerRunning", backend, err)
+		}
+		// Timers are per user, not per task
+		theirs := timer(first, other, started)
+		if err := stores.TimeEntries.CreateTimeEntry(ctx, &theirs); err != nil {
+			t.Errorf("%s: another user's timer: %v", backend, err)
+		}
+		if got, err := stores.TimeEntries.RunningTimer(ctx, owner); err != nil || got.ID != running.ID {
+			t.Errorf("%s: running timer = %v, %v", backend, got.ID, err)
+		}
+
+		// Running timers don't count towards the totals until they stop
+		if sums, err := stores.TimeEntries.SumTimeByTask(ctx, OwnedBy(owner), TimeFilter{}); err != nil || len(sums) != 0 {
+			t.Errorf("%s: sums with only running timers = %v, %v", backend, sums, err)
+		}
+
+		stopped, err := stores.TimeEntries.StopTimer(ctx, owner, started.Add(90*time.Minute))
+		if err != nil {
+			t.Fatalf("%s: stop: %v", backend, err)
+		}
+		if stopped.Running || stopped.EndedAt == nil || stopped.Seconds != 90*60 {
+			t.Errorf("%s: stopped entry = %+v", backend, stopped)
+		}
+		if _, err := stores.TimeEntries.RunningTimer(ctx, owner); !errors.Is(err, ErrTimeEntryNotFound) {
+			t.Errorf("%s: running timer after stop: %v", backend, err)
+		}
+		if err := stores.TimeEntries.CreateTimeEntry(ctx, &again); err != nil {
+			t.Errorf("%s: start after stop: %v", backend, err)
+		}
+
+		sums, err := stores.TimeEntries.SumTimeByTask(ctx, OwnedBy(owner), TimeFilter{})
+		if err != nil || len(sums) != 1 || sums[0].TaskID != first.ID || sums[0].Seconds != 90*60 {
+			t.Errorf("%s: sums = %+v, %v", backend, sums, err)
+		}
+	}
+}
+
+func TestConcurrentTimerStarts(t *testing.T) {
+	ctx := context.Background()
+	for backend, stores := range testStores(t) {
+		owner := primitive.NewObjectID()
+		createOwner(t, stores, owner)
+		t.Cleanup(func() { stores.TimeEntries.DeleteTimeEntriesByOwner(ctx, owner) })
+		task := createTask(t, stores, owner, "busy")
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			started int
+		)
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				entry := timer(task, owner, time.Now())
+				err := stores.TimeEntries.CreateTimeEntry(ctx, &entry)
+				if err != nil && !errors.Is(err, ErrTimerRunning) {
+					t.Errorf("%s: start: %v", backend, err)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if err == nil {
+					started++
+				}
+			}()
+		}
+		wg.Wait()
+		if started != 1 {
+			t.Errorf("%s: %d timers started at once, want 1", backend, started)
+		}
+	}
+}
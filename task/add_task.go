@@ -1,17 +1,17 @@
 package task
 
 import (
-	"context"
+	"fmt"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joshua-takyi/todo/connection"
+	"github.com/joshua-takyi/todo/auth"
 	"github.com/joshua-takyi/todo/helpers"
 	"github.com/joshua-takyi/todo/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func CreateTask(ctx *gin.Context) {
+func (h *Handler) CreateTask(ctx *gin.Context) {
 	// Parse the incoming JSON request into a Task struct
 	var task model.Task
 	if err := ctx.ShouldBindJSON(&task); err != nil {
@@ -19,28 +19,113 @@ func CreateTask(ctx *gin.Context) {
 		return
 	}
 
+	// Dates may arrive with any UTC offset; store them in UTC
+	normalizeSchedule(&task)
+
 	// Validate the task fields using the helper function
 	if err := helpers.ValidateTask(task); err != nil {
 		ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
 		return
 	}
 
+	// The task belongs to whoever is logged in, whatever the body says
+	scope, ok := auth.OwnerScope(ctx)
+	if !ok {
+		return
+	}
+
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+
+	// New tasks start in todo unless the body names another status
+	// That status counts as a move out of todo, so the workflow must allow it, and a task
+	// can't start out finished: completing it is what checks blockers and spawns the next occurrence
+	if task.Status == "" {
+		task.Status = model.StatusTodo
+	}
+	if !task.Status.Valid() {
+		ctx.JSON(400, gin.H{"error": fmt.Sprintf("Unknown status '%s'", task.Status)})
+		return
+	}
+	if task.Status.IsTerminal() {
+		ctx.JSON(400, gin.H{"error": fmt.Sprintf("A new task can't start as %s, create it and then move it", task.Status)})
+		return
+	}
+	if task.Status != model.StatusTodo && !h.Workflow.Allows(model.StatusTodo, task.Status) {
+		ctx.JSON(409, gin.H{
+			"error":   fmt.Sprintf("A task cannot move from %s to %s", model.StatusTodo, task.Status),
+			"allowed": h.Workflow.Next(model.StatusTodo),
+		})
+		return
+	}
+
 	// Set default values for the task
 	task.ID = primitive.NewObjectID()
+	task.OwnerID = scope.OwnerID
 	task.Metadata.CreatedAt = time.Now()
 	task.Metadata.UpdatedAt = time.Now()
-	task.Completed = false
+	task.Completed = task.Status.IsTerminal() // completed always follows the status
+	task.StatusSince = model.StatusTimes{task.Status: task.Metadata.CreatedAt}
+	task.BlockedBy = nil   // Dependencies are added through their own endpoint, which checks for cycles
+	task.Rank = ""         // New tasks go to the bottom of their board column, see model.Task.EffectiveRank
+	task.Attachments = nil // Files are uploaded to the task once it exists
+	task.Checklist = nil   // Items are added through their own endpoints, which give them IDs
+	if task.Image == nil {
+		task.Image = []string{} // Image URLs are optional now that files can be uploaded
+	}
+
+	// A recurring task starts its own series
+	if task.Recurrence != nil {
+		if err := prepareRecurrence(task.Recurrence, task, nil, loc); err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	// Create a timeout context for database operations
-	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	dbCtx, cancel := helpers.DBContext(ctx)
 	defer cancel() // Ensure resources are released when function completes
 
-	// Get a reference to the tasks collection
-	collection := connection.Client.Database("Go").Collection("tasks")
+	// A subtask must go under one of the caller's tasks without making the tree too deep
+	if task.ParentID != nil {
+		if err := h.checkParent(dbCtx, scope, task.ID, *task.ParentID, 1); err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if task.ProjectID != nil {
+		if err := h.checkProject(dbCtx, scope, *task.ProjectID); err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Custom field values must match the owner's definitions, and every required field must be set
+	fields, defs, verr := h.checkFieldValues(dbCtx, task.OwnerID, task.Fields)
+	if verr != nil {
+		ctx.JSON(verr.GetStatus(), gin.H{"error": verr.Error()})
+		return
+	}
+	if err := helpers.RequireFields(defs, fields); err != nil {
+		ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+		return
+	}
+	task.Fields = nil
+	for key, value := range fields {
+		if value == nil {
+			continue // Cleared values have nothing to store on a new task
+		}
+		if task.Fields == nil {
+			task.Fields = model.FieldValues{}
+		}
+		task.Fields[key] = value
+	}
 
-	// Insert the new task into the database
-	result, err := collection.InsertOne(dbCtx, task)
-	if err != nil {
+	// Insert the new task through the store
+	if err := h.Store.Create(dbCtx, &task); err != nil {
 		ctx.JSON(500, gin.H{"error": "Failed to create task: " + err.Error()})
 		return
 	}
@@ -48,7 +133,7 @@ func CreateTask(ctx *gin.Context) {
 	// Return a success response with the created task
 	ctx.JSON(201, gin.H{
 		"message": "Task created successfully",
-		"task":    task,
-		"id":      result.InsertedID,
+		"task":    present(task, loc, time.Now()),
+		"id":      task.ID,
 	})
 }
@@ -0,0 +1,388 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/blob"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxUploadBytes is the largest file a client may attach to a task
+const maxUploadBytes = 10 << 20
+
+// maxAttachments caps how many files one task can hold
+const maxAttachments = 20
+
+// allowedUploadTypes are the accepted content types, sniffed from the file's first bytes
+// The type the client claims is ignored: it is too easy to lie about
+var allowedUploadTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// attachmentPath is where present points clients to download an attachment
+const attachmentPath = "/api/v1/tasks/%s/attachments/%s"
+
+// blobContext gives file transfers more time than database calls: a 10 MB upload to S3 can take a while
+func blobContext(ctx *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx.Request.Context(), time.Minute)
+}
+
+// UploadAttachment stores a file sent as multipart/form-data (field "file") and records it on the task
+// The file must be one of allowedUploadTypes and at most maxUploadBytes; JPEG, PNG and GIF
+// images also get their dimensions recorded and a thumbnail made
+func (h *Handler) UploadAttachment(ctx *gin.Context) {
+	task, scope, _, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+	if len(task.Attachments) >= maxAttachments {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("Task already has %d attachments, remove one first", maxAttachments),
+		})
+		return
+	}
+
+	user, ok := auth.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	// Leave some room for the multipart envelope around the file itself
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUploadBytes+1<<20)
+	file, header, err := ctx.Request.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("File is too large, the limit is %d MB", maxUploadBytes>>20),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Send the file as multipart/form-data in a field named 'file'"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxUploadBytes+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the uploaded file: " + err.Error()})
+		return
+	}
+	if len(data) > maxUploadBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("File is too large, the limit is %d MB", maxUploadBytes>>20),
+		})
+		return
+	}
+	if len(data) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is empty"})
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !allowedType(contentType) {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error":   fmt.Sprintf("Files of type %s are not accepted", contentType),
+			"allowed": allowedUploadTypes,
+		})
+		return
+	}
+
+	attachment := model.Attachment{
+		ID:          primitive.NewObjectID(),
+		FileName:    cleanFileName(header.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		UploadedBy:  user.ID,
+		UploadedAt:  time.Now(),
+	}
+	attachment.Key = blob.AttachmentKey(task.OwnerID, task.ID, attachment.ID)
+
+	// Check the image really decodes before storing anything, and keep its dimensions
+	var thumbnail []byte
+	if decodableTypes[contentType] {
+		config, err := imageConfig(data)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is not a valid " + contentType + " image"})
+			return
+		}
+		if config.Width*config.Height > maxImagePixels {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": fmt.Sprintf("Image is too large, the limit is %d megapixels", maxImagePixels/1_000_000),
+			})
+			return
+		}
+		attachment.Width, attachment.Height = config.Width, config.Height
+
+		thumbnail, err = makeThumbnail(data, contentType)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is not a valid " + contentType + " image"})
+			return
+		}
+		attachment.ThumbnailKey = blob.ThumbnailKey(task.OwnerID, task.ID, attachment.ID)
+	}
+
+	blobCtx, cancelBlob := blobContext(ctx)
+	defer cancelBlob()
+
+	if err := h.Blobs.Put(blobCtx, attachment.Key, data, contentType); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store the file: " + err.Error()})
+		return
+	}
+	if thumbnail != nil {
+		if err := h.Blobs.Put(blobCtx, attachment.ThumbnailKey, thumbnail, thumbnailType(contentType)); err != nil {
+			h.deleteFiles(blobCtx, []model.Attachment{attachment})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store the thumbnail: " + err.Error()})
+			return
+		}
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Only record the attachment once the files are stored, so a listed attachment can always be downloaded
+	_, err = h.Store.AddAttachment(dbCtx, scope, task.ID, attachment)
+	if err != nil {
+		h.deleteFiles(blobCtx, []model.Attachment{attachment})
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save the attachment: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":    "Attachment uploaded successfully",
+		"task_id":    task.ID,
+		"attachment": presentAttachment(task.ID, attachment),
+	})
+}
+
+// ListAttachments returns the metadata of the task's files, oldest first
+func (h *Handler) ListAttachments(ctx *gin.Context) {
+	task, _, _, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	attachments := make([]model.Attachment, 0, len(task.Attachments))
+	for _, attachment := range task.Attachments {
+		attachments = append(attachments, presentAttachment(task.ID, attachment))
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "Attachments retrieved successfully",
+		"task_id":     task.ID,
+		"attachments": attachments,
+	})
+}
+
+// DownloadAttachment streams the file back with the content type it was stored with
+func (h *Handler) DownloadAttachment(ctx *gin.Context) {
+	h.serveAttachment(ctx, false)
+}
+
+// DownloadThumbnail streams the attachment's thumbnail; attachments without one answer 404
+func (h *Handler) DownloadThumbnail(ctx *gin.Context) {
+	h.serveAttachment(ctx, true)
+}
+
+// DeleteAttachment removes the attachment from the task, then its files from the blob store
+func (h *Handler) DeleteAttachment(ctx *gin.Context) {
+	task, scope, _, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+	attachment, ok := findAttachment(ctx, task)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	_, err := h.Store.RemoveAttachment(dbCtx, scope, task.ID, attachment.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if errors.Is(err, store.ErrAttachmentNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove the attachment: " + err.Error()})
+		return
+	}
+
+	blobCtx, cancelBlob := blobContext(ctx)
+	defer cancelBlob()
+	h.deleteFiles(blobCtx, []model.Attachment{attachment})
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       "Attachment deleted successfully",
+		"task_id":       task.ID,
+		"attachment_id": attachment.ID,
+	})
+}
+
+// serveAttachment writes the file (or its thumbnail) named by :attachment_id
+func (h *Handler) serveAttachment(ctx *gin.Context, thumbnail bool) {
+	task, _, _, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+	attachment, ok := findAttachment(ctx, task)
+	if !ok {
+		return
+	}
+
+	key, contentType, name := attachment.Key, attachment.ContentType, attachment.FileName
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Attachment has no thumbnail"})
+			return
+		}
+		key, contentType = attachment.ThumbnailKey, thumbnailType(attachment.ContentType)
+		name = "thumbnail-" + name
+	}
+
+	blobCtx, cancel := blobContext(ctx)
+	defer cancel()
+
+	content, err := h.Blobs.Open(blobCtx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read the file: " + err.Error()})
+		return
+	}
+	defer content.Close()
+
+	// The size of a thumbnail isn't recorded; -1 lets gin stream it without a Content-Length
+	size := int64(-1)
+	if !thumbnail {
+		size = attachment.Size
+	}
+	ctx.DataFromReader(http.StatusOK, size, contentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": name}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=86400", // Files never change: a new upload gets a new ID
+	})
+}
+
+// deleteFiles removes the stored files of the attachments
+// The metadata is already gone by then, so a failure only leaves garbage behind and is just logged
+func (h *Handler) deleteFiles(ctx context.Context, attachments []model.Attachment) {
+	for _, attachment := range attachments {
+		for _, key := range []string{attachment.Key, attachment.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			if err := h.Blobs.Delete(ctx, key); err != nil {
+				fmt.Printf("Warning: failed to delete file %s: %v\n", key, err)
+			}
+		}
+	}
+}
+
+// doomedTasks collects a task about to be deleted, and its whole subtree when the subtasks go too,
+// so their attachments and comments can be cleaned up after the delete
+// A missing task has nothing to collect; the delete itself reports it
+func (h *Handler) doomedTasks(ctx context.Context, scope store.Scope, id primitive.ObjectID, withSubtasks bool) ([]model.Task, error) {
+	task, err := h.Store.Get(ctx, scope, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []model.Task{task}
+	level := []primitive.ObjectID{id}
+	for depth := 1; withSubtasks && len(level) > 0 && depth <= maxDepth; depth++ {
+		var next []primitive.ObjectID
+		for _, parentID := range level {
+			children, err := h.Store.ListChildren(ctx, scope, parentID)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				tasks = append(tasks, child)
+				next = append(next, child.ID)
+			}
+		}
+		level = next
+	}
+	return tasks, nil
+}
+
+// findAttachment looks up :attachment_id among the task's attachments
+// It writes the error response itself and returns false when the request must stop
+func findAttachment(ctx *gin.Context, task model.Task) (model.Attachment, bool) {
+	id, err := primitive.ObjectIDFromHex(ctx.Param("attachment_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID format"})
+		return model.Attachment{}, false
+	}
+	for _, attachment := range task.Attachments {
+		if attachment.ID == id {
+			return attachment, true
+		}
+	}
+	ctx.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+	return model.Attachment{}, false
+}
+
+// presentAttachment fills in the download links of an attachment
+func presentAttachment(taskID primitive.ObjectID, attachment model.Attachment) model.Attachment {
+	attachment.URL = fmt.Sprintf(attachmentPath, taskID.Hex(), attachment.ID.Hex())
+	if attachment.ThumbnailKey != "" {
+		attachment.ThumbnailURL = attachment.URL + "/thumbnail"
+	}
+	return attachment
+}
+
+// allowedType reports whether contentType is one of allowedUploadTypes
+func allowedType(contentType string) bool {
+	for _, allowed := range allowedUploadTypes {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFileName keeps only the base name of what the client sent, without control characters,
+// so it is safe to show and to put back into a Content-Disposition header
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if len(name) > 255 {
+		name = strings.ToValidUTF8(name[:255], "") // Don't leave half a character at the end
+	}
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
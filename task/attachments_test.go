@@ -0,0 +1,171 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshua-takyi/todo/blob"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// upload sends data as the multipart field "file" and decodes the JSON response
+func (api *testAPI) upload(taskID, field, name string, data []byte) (int, map[string]interface{}) {
+	api.t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(field, name)
+	if err != nil {
+		api.t.Fatal(err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/tasks/"+taskID+"/attachments", &body)
+	req.Header.Set("Authorization", "Bearer "+api.token)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	api.engine.ServeHTTP(rec, req)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		api.t.Fatalf("upload: response is not JSON: %s", rec.Body.String())
+	}
+	return rec.Code, decoded
+}
+
+// download fetches a file as the test user, keeping the raw response
+func (api *testAPI) download(path string) *httptest.ResponseRecorder {
+	api.t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+api.token)
+	rec := httptest.NewRecorder()
+	api.engine.ServeHTTP(rec, req)
+	return rec
+}
+
+// testPNG encodes a plain w x h PNG image
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var out bytes.Buffer
+	if err := png.Encode(&out, img); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestAttachments(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("with files")
+	picture := testPNG(t, 600, 300)
+
+	tests := []struct {
+		name  string
+		field string
+		data  []byte
+		want  int
+	}{
+		{"wrong field", "image", picture, http.StatusBadRequest},
+		{"empty file", "file", nil, http.StatusBadRequest},
+		{"text file", "file", []byte("just some notes"), http.StatusUnsupportedMediaType},
+		{"broken image", "file", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if code, body := api.upload(id, tt.field, "x.png", tt.data); code != tt.want {
+			t.Errorf("%s: got %d %v, want %d", tt.name, code, body, tt.want)
+		}
+	}
+
+	// The name is cut down to something safe to put in a header, whatever the client sent
+	code, body := api.upload(id, "file", `../"shot".png`, picture)
+	if code != http.StatusCreated {
+		t.Fatalf("upload: got %d %v", code, body)
+	}
+	attachment := body["attachment"].(map[string]interface{})
+	if attachment["file_name"] != "shot.png" || attachment["content_type"] != "image/png" ||
+		attachment["width"] != float64(600) || attachment["height"] != float64(300) {
+		t.Errorf("attachment = %v", attachment)
+	}
+	url := "/tasks/" + id + "/attachments/" + attachment["id"].(string)
+
+	rec := api.download(url)
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), picture) {
+		t.Fatalf("download: got %d with %d bytes, want the %d bytes uploaded", rec.Code, rec.Body.Len(), len(picture))
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("download Content-Type = %s", got)
+	}
+	if got := rec.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("download X-Content-Type-Options = %s", got)
+	}
+
+	// The thumbnail fits in thumbnailSize and keeps the aspect ratio
+	rec = api.download(url + "/thumbnail")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("thumbnail: got %d", rec.Code)
+	}
+	if config, err := png.DecodeConfig(rec.Body); err != nil || config.Width != thumbnailSize || config.Height != thumbnailSize/2 {
+		t.Errorf("thumbnail is %dx%d (%v), want %dx%d", config.Width, config.Height, err, thumbnailSize, thumbnailSize/2)
+	}
+
+	// Other users can't see the task, so they can't see its files either
+	stranger := api.newUser("stranger")
+	if code, _ := api.doAs(stranger, http.MethodGet, "/tasks/"+id+"/attachments", nil); code != http.StatusNotFound {
+		t.Errorf("stranger lists the files: got %d, want 404", code)
+	}
+
+	_, body = api.do(http.MethodGet, "/tasks/"+id+"/attachments", nil)
+	if files := body["attachments"].([]interface{}); len(files) != 1 || files[0].(map[string]interface{})["url"] != "/api/v1"+url {
+		t.Errorf("attachments = %v", files)
+	}
+
+	// Deleting the attachment, or its task, removes the stored files
+	_, body = api.do(http.MethodGet, "/tasks/"+id, nil)
+	ownerID, _ := primitive.ObjectIDFromHex(body["task"].(map[string]interface{})["owner_id"].(string))
+	taskID, _ := primitive.ObjectIDFromHex(id)
+	files := func(attachment map[string]interface{}) []string {
+		attachmentID, _ := primitive.ObjectIDFromHex(attachment["id"].(string))
+		return []string{blob.AttachmentKey(ownerID, taskID, attachmentID), blob.ThumbnailKey(ownerID, taskID, attachmentID)}
+	}
+	if code, _ := api.do(http.MethodDelete, url, nil); code != http.StatusOK {
+		t.Fatalf("delete: got %d", code)
+	}
+	if code, _ := api.do(http.MethodDelete, url, nil); code != http.StatusNotFound {
+		t.Errorf("delete again: got %d, want 404", code)
+	}
+	assertGone(t, api.blobs, files(attachment))
+
+	_, body = api.upload(id, "file", "again.png", picture)
+	again := files(body["attachment"].(map[string]interface{}))
+	if code, _ := api.do(http.MethodDelete, "/tasks/"+id, nil); code != http.StatusNoContent {
+		t.Fatalf("delete task: got %d", code)
+	}
+	assertGone(t, api.blobs, again)
+}
+
+// assertGone fails the test when any of the files is still stored
+func assertGone(t *testing.T, blobs blob.Store, keys []string) {
+	t.Helper()
+	for _, key := range keys {
+		if file, err := blobs.Open(context.Background(), key); !errors.Is(err, blob.ErrNotFound) {
+			if err == nil {
+				file.Close()
+			}
+			t.Errorf("file %s is still stored (%v)", key, err)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/rank"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxBoardTasks caps how many tasks one board request loads
+// Larger boards should be narrowed down with the list filters (like ?project_id=)
+const maxBoardTasks = 1000
+
+// noProjectColumn is the key of the project column holding tasks outside any project
+const noProjectColumn = "none"
+
+// boardFields are the task fields a board can be grouped by
+var boardFields = map[string]bool{"status": true, "priority": true, "project": true}
+
+// boardColumn is one column of the board with its tasks in board order
+type boardColumn struct {
+	Key      string       `json:"key"`                // Status, priority or project ID ("none" for tasks outside any project)
+	Name     string       `json:"name"`               // What to show as the column title
+	Archived bool         `json:"archived,omitempty"` // Set on the columns of archived projects
+	Count    int          `json:"count"`
+	Tasks    []model.Task `json:"tasks"`
+}
+
+// moveRequest is the JSON body accepted by MoveOnBoard
+type moveRequest struct {
+	GroupBy string              `json:"group_by"` // Defaults to status
+	Column  string              `json:"column"`   // Defaults to the task's current column
+	AfterID *primitive.ObjectID `json:"after_id"` // Task to place it after; null or missing puts it first
+}
+
+// GetBoard returns the caller's tasks grouped into columns, each in its manual board order
+// Query parameters:
+// - group_by: status (default), priority or project
+// - the filters of the task list read by parseTaskFilter (dates, overdue, project_id, status, tags, priority, completed, title)
+// - tz: IANA time zone used to show dates and read date-only filters (default: UTC)
+func (h *Handler) GetBoard(ctx *gin.Context) {
+	groupBy := ctx.DefaultQuery("group_by", "status")
+	if !boardFields[groupBy] {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group_by: use status, priority or project"})
+		return
+	}
+
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return
+	}
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+	filter, ok := parseTaskFilter(ctx, loc)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	tasks, total, err := h.Store.List(dbCtx, scope, store.ListOptions{Page: 1, Limit: maxBoardTasks, Filter: filter})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks: " + err.Error()})
+		return
+	}
+	tasks, err = h.presentTasks(dbCtx, scope, tasks, loc)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks: " + err.Error()})
+		return
+	}
+
+	columns, err := h.boardColumns(dbCtx, scope, groupBy)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve projects: " + err.Error()})
+		return
+	}
+
+	// Put every task in its column; keys without a predefined column (like an
+	// unknown priority) get one of their own after the others
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		index[column.Key] = i
+	}
+	for _, task := range sortByRank(tasks) {
+		key := columnKey(task, groupBy)
+		i, found := index[key]
+		if !found {
+			i = len(columns)
+			index[key] = i
+			columns = append(columns, boardColumn{Key: key, Name: key, Tasks: []model.Task{}})
+		}
+		columns[i].Tasks = append(columns[i].Tasks, task)
+		columns[i].Count++
+	}
+
+	// Archived projects only show up while they still hold tasks
+	if groupBy == "project" {
+		shown := columns[:0]
+		for _, column := range columns {
+			if column.Count > 0 || !column.Archived {
+				shown = append(shown, column)
+			}
+		}
+		columns = shown
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Board retrieved successfully",
+		"group_by":  groupBy,
+		"columns":   columns,
+		"total":     total,
+		"truncated": total > int64(len(tasks)),
+	})
+}
+
+// MoveOnBoard puts a task at a new position on the board, in the same or another column
+// Only the moved task is written: it gets a rank between its new neighbours
+// Changing column changes the grouped field in the same write; a status change
+// goes through the workflow exactly like POST /tasks/:id/transitions
+// Query parameters:
+// - force: true to move the task to done even though some of its blockers are still open
+func (h *Handler) MoveOnBoard(ctx *gin.Context) {
+	var req moveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.GroupBy == "" {
+		req.GroupBy = "status"
+	}
+	if !boardFields[req.GroupBy] {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group_by: use status, priority or project"})
+		return
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+	if req.AfterID != nil && *req.AfterID == task.ID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A task cannot be placed after itself"})
+		return
+	}
+
+	from := columnKey(task, req.GroupBy)
+	if req.Column == "" {
+		req.Column = from
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Work out the field change first, so a bad column is reported before anything is read
+	var update store.TaskUpdate
+	if req.Column != from {
+		if err := h.columnChange(dbCtx, scope, req.GroupBy, req.Column, &update); err != nil {
+			ctx.JSON(err.Status, gin.H{"error": err.Message})
+			return
+		}
+	}
+
+	// Find the neighbours in the target column, without the task itself
+	column, err := h.columnTasks(dbCtx, scope, req.GroupBy, req.Column, task.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks: " + err.Error()})
+		return
+	}
+	prev, next := "", ""
+	position := 0
+	if req.AfterID != nil {
+		position = -1
+		for i, other := range column {
+			if other.ID == *req.AfterID {
+				position = i + 1
+				break
+			}
+		}
+		if position < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("after_id is not a task in column '%s'", req.Column)})
+			return
+		}
+		prev = column[position-1].EffectiveRank()
+	}
+	if position < len(column) {
+		next = column[position].EffectiveRank()
+	}
+
+	// Keep the current rank when it already falls between the neighbours,
+	// like a task dropped into an empty column
+	current := task.EffectiveRank()
+	if current <= prev || (next != "" && current >= next) {
+		newRank, err := rank.Between(prev, next)
+		if err != nil {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "The column was reordered by another request, reload the board and try again"})
+			return
+		}
+		update.Rank = &newRank
+	}
+
+	var nextTask *model.Task
+	switch {
+	case req.GroupBy == "status" && req.Column != from:
+		task, nextTask, ok = h.moveTask(ctx, dbCtx, scope, task, model.Status(req.Column), update)
+		if !ok {
+			return
+		}
+	case update.Rank != nil || update.Priority != nil || update.ProjectID.Set: // The only fields a move sets
+		task, err = h.Store.Update(dbCtx, scope, task.ID, update)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move task: " + err.Error()})
+			return
+		}
+	}
+
+	task, err = h.presentTask(dbCtx, scope, task, loc)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve task: " + err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"message":  "Task moved successfully",
+		"group_by": req.GroupBy,
+		"column":   req.Column,
+		"position": position,
+		"task":     task,
+	}
+	if nextTask != nil {
+		response["next_task"] = present(*nextTask, loc, time.Now())
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// boardColumns returns the columns every board grouped by groupBy starts with, in display order
+func (h *Handler) boardColumns(ctx context.Context, scope store.Scope, groupBy string) ([]boardColumn, error) {
+	columns := []boardColumn{}
+	switch groupBy {
+	case "status":
+		for _, status := range model.Statuses {
+			columns = append(columns, boardColumn{Key: string(status), Name: string(status), Tasks: []model.Task{}})
+		}
+	case "priority":
+		for _, priority := range []model.Priority{model.PriorityHigh, model.PriorityMedium, model.PriorityLow} {
+			columns = append(columns, boardColumn{Key: string(priority), Name: string(priority), Tasks: []model.Task{}})
+		}
+	case "project":
+		columns = append(columns, boardColumn{Key: noProjectColumn, Name: "No project", Tasks: []model.Task{}})
+		projects, err := h.Projects.ListProjects(ctx, scope, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range projects {
+			columns = append(columns, boardColumn{
+				Key:      project.ID.Hex(),
+				Name:     project.Name,
+				Archived: project.Archived,
+				Tasks:    []model.Task{},
+			})
+		}
+	}
+	return columns, nil
+}
+
+// columnChange sets the field change that puts a task into another column on update
+// It returns a 400 error for a column that doesn't exist for groupBy
+func (h *Handler) columnChange(ctx context.Context, scope store.Scope, groupBy, column string, update *store.TaskUpdate) *helpers.Error {
+	switch groupBy {
+	case "status":
+		if !model.Status(column).Valid() {
+			return &helpers.Error{Message: fmt.Sprintf("Unknown status '%s'", column), Status: http.StatusBadRequest}
+		}
+		// moveTask sets the transition itself after checking the workflow
+	case "priority":
+		priority := model.Priority(column)
+		if !priority.Valid() {
+			return &helpers.Error{Message: "Invalid column: use low, medium or high", Status: http.StatusBadRequest}
+		}
+		update.Priority = &priority
+	case "project":
+		update.ProjectID.Set = true
+		if column == noProjectColumn {
+			return nil
+		}
+		projectID, err := primitive.ObjectIDFromHex(column)
+		if err != nil {
+			return &helpers.Error{Message: "Invalid column: use a project ID or none", Status: http.StatusBadRequest}
+		}
+		if err := h.checkProject(ctx, scope, projectID); err != nil {
+			return err
+		}
+		update.ProjectID.Value = &projectID
+	}
+	return nil
+}
+
+// columnTasks loads the tasks of one column in board order, leaving out the task being moved
+// Only the column's own field narrows the query: ranks are shared by every view of the
+// column, so a task placed after another stays right after it whatever the board filters
+func (h *Handler) columnTasks(ctx context.Context, scope store.Scope, groupBy, column string, moving primitive.ObjectID) ([]model.Task, error) {
+	filter := store.TaskFilter{Now: time.Now()}
+	switch groupBy {
+	case "status":
+		status := model.Status(column)
+		filter.Status = &status
+	case "project":
+		if column == noProjectColumn {
+			filter.NoProject = true
+		} else if projectID, err := primitive.ObjectIDFromHex(column); err == nil {
+			filter.ProjectID = &projectID
+		}
+	}
+
+	tasks, _, err := h.Store.List(ctx, scope, store.ListOptions{Page: 1, Limit: maxBoardTasks, Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	inColumn := []model.Task{}
+	for _, task := range tasks {
+		if task.ID != moving && columnKey(task, groupBy) == column {
+			inColumn = append(inColumn, task)
+		}
+	}
+	return sortByRank(inColumn), nil
+}
+
+// columnKey returns the key of the column a task belongs to when the board is grouped by groupBy
+func columnKey(task model.Task, groupBy string) string {
+	switch groupBy {
+	case "priority":
+		return string(task.Priority)
+	case "project":
+		if task.ProjectID == nil {
+			return noProjectColumn
+		}
+		return task.ProjectID.Hex()
+	default:
+		return string(task.EffectiveStatus())
+	}
+}
+
+// sortByRank orders tasks by board rank; ranks are unique, the ID only breaks ties
+// left by two requests that placed tasks at the same spot at the same time
+func sortByRank(tasks []model.Task) []model.Task {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		ri, rj := tasks[i].EffectiveRank(), tasks[j].EffectiveRank()
+		if ri != rj {
+			return ri < rj
+		}
+		return tasks[i].ID.Hex() < tasks[j].ID.Hex()
+	})
+	return tasks
+}
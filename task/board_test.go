@@ -0,0 +1,111 @@
+package task
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBoard(t *testing.T) {
+	api := newTestAPI(t)
+	a, b, c := api.create("a"), api.create("b"), api.create("c")
+
+	// board returns "key:titles" for every column, like "todo:a,b,c"
+	board := func(query string) []string {
+		t.Helper()
+		code, body := api.do(http.MethodGet, "/board?"+query, nil)
+		if code != http.StatusOK {
+			t.Fatalf("board ?%s: got %d %v", query, code, body)
+		}
+		var out []string
+		for _, column := range body["columns"].([]interface{}) {
+			column := column.(map[string]interface{})
+			out = append(out, column["key"].(string)+":"+strings.Join(titles(column), ","))
+		}
+		return out
+	}
+	move := func(id string, body gin.H) (int, map[string]interface{}) {
+		t.Helper()
+		return api.do(http.MethodPost, "/tasks/"+id+"/move", body)
+	}
+	column := func(query, key string) string {
+		t.Helper()
+		for _, got := range board(query) {
+			if strings.HasPrefix(got, key+":") {
+				return strings.TrimPrefix(got, key+":")
+			}
+		}
+		t.Fatalf("board ?%s has no column %s", query, key)
+		return ""
+	}
+
+	// New tasks go to the bottom, and every status has a column even when empty
+	if got, want := strings.Join(board(""), " "), "todo:a,b,c in_progress: in_review: done: cancelled:"; got != want {
+		t.Errorf("board = %s, want %s", got, want)
+	}
+
+	steps := []struct {
+		task string
+		body gin.H
+		want string
+	}{
+		{c, gin.H{}, "c,a,b"},              // No after_id puts the task first
+		{a, gin.H{"after_id": c}, "c,a,b"}, // Already there
+		{c, gin.H{"after_id": b}, "a,b,c"},
+		{b, gin.H{"after_id": c}, "a,c,b"},
+		{b, gin.H{"after_id": a}, "a,b,c"},
+	}
+	for _, step := range steps {
+		if code, body := move(step.task, step.body); code != http.StatusOK {
+			t.Fatalf("move %v: got %d %v", step.body, code, body)
+		}
+		if got := column("", "todo"); got != step.want {
+			t.Errorf("after moving %v: todo = %s, want %s", step.body, got, step.want)
+		}
+	}
+
+	// Changing column goes through the workflow
+	if code, body := move(b, gin.H{"column": "in_progress"}); code != http.StatusOK || body["task"].(map[string]interface{})["status"] != "in_progress" {
+		t.Fatalf("b to in_progress: got %d %v", code, body)
+	}
+	if code, _ := move(a, gin.H{"column": "in_review"}); code != http.StatusConflict {
+		t.Errorf("a skips to in_review: got %d, want 409", code)
+	}
+	if got, want := strings.Join(board(""), " "), "todo:a,c in_progress:b in_review: done: cancelled:"; got != want {
+		t.Errorf("board = %s, want %s", got, want)
+	}
+
+	// Grouped by priority, a move sets the priority
+	if code, body := move(c, gin.H{"group_by": "priority", "column": "high"}); code != http.StatusOK || body["task"].(map[string]interface{})["priority"] != "high" {
+		t.Fatalf("c to high: got %d %v", code, body)
+	}
+	if got, want := strings.Join(board("group_by=priority"), " "), "high:c medium:a,b low:"; got != want {
+		t.Errorf("board by priority = %s, want %s", got, want)
+	}
+	// The list filters narrow the board
+	if got := column("group_by=priority&status=todo", "medium"); got != "a" {
+		t.Errorf("medium todo tasks = %s, want a", got)
+	}
+
+	tests := []struct {
+		name string
+		task string
+		body gin.H
+	}{
+		{"after itself", a, gin.H{"after_id": a}},
+		{"after a task in another column", a, gin.H{"after_id": b}},
+		{"unknown status column", a, gin.H{"column": "started"}},
+		{"unknown priority column", a, gin.H{"group_by": "priority", "column": "urgent"}},
+		{"unknown grouping", a, gin.H{"group_by": "owner"}},
+	}
+	for _, tt := range tests {
+		if code, body := move(tt.task, tt.body); code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %v, want 400", tt.name, code, body)
+		}
+	}
+	if code, _ := api.do(http.MethodGet, "/board?group_by=owner", nil); code != http.StatusBadRequest {
+		t.Errorf("board by owner: got %d, want 400", code)
+	}
+}
@@ -0,0 +1,293 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxChecklistItems caps how many items one task's checklist can hold
+const maxChecklistItems = 100
+
+// maxChecklistText is the longest item text accepted, in characters
+const maxChecklistText = 200
+
+// checklistItemRequest is the JSON body accepted by AddChecklistItem
+type checklistItemRequest struct {
+	Text     string `json:"text"`
+	Position *int   `json:"position"` // 0-based; missing or past the end appends the item
+}
+
+// checklistOrderRequest is the JSON body accepted by ReorderChecklist
+type checklistOrderRequest struct {
+	ItemIDs []primitive.ObjectID `json:"item_ids" binding:"required"`
+}
+
+// ListChecklist returns the task's checklist in order, with how much of it is done
+func (h *Handler) ListChecklist(ctx *gin.Context) {
+	task, _, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	task = present(task, loc, time.Now())
+	checklist := task.Checklist
+	if checklist == nil {
+		checklist = []model.ChecklistItem{}
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Checklist retrieved successfully",
+		"task_id":   task.ID,
+		"checklist": checklist,
+		"progress":  task.Progress,
+	})
+}
+
+// AddChecklistItem adds an unchecked item to the task's checklist, at the end unless a position is given
+func (h *Handler) AddChecklistItem(ctx *gin.Context) {
+	var req checklistItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	text, textErr := checklistText(req.Text)
+	if textErr != nil {
+		ctx.JSON(textErr.GetStatus(), gin.H{"error": textErr.Error()})
+		return
+	}
+	position := -1
+	if req.Position != nil {
+		if *req.Position < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "position must be 0 or more"})
+			return
+		}
+		position = *req.Position
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+	if len(task.Checklist) >= maxChecklistItems {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("Checklist already has %d items, remove one first", maxChecklistItems),
+		})
+		return
+	}
+
+	item := model.ChecklistItem{ID: primitive.NewObjectID(), Text: text, CreatedAt: time.Now()}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	updated, err := h.Store.AddChecklistItem(dbCtx, scope, task.ID, item, position)
+	if err == nil {
+		updated, err = h.presentTask(dbCtx, scope, updated, loc)
+	}
+	if !checklistWritten(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Checklist item added successfully",
+		"item_id": item.ID,
+		"task":    updated,
+	})
+}
+
+// UpdateChecklistItem renames an item and/or checks or unchecks it
+func (h *Handler) UpdateChecklistItem(ctx *gin.Context) {
+	var update store.ChecklistItemUpdate
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if update.Text == nil && update.Done == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Send text and/or done"})
+		return
+	}
+	if update.Text != nil {
+		text, err := checklistText(*update.Text)
+		if err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+		update.Text = &text
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+	item, ok := findChecklistItem(ctx, task)
+	if !ok {
+		return
+	}
+	if update.Done != nil && *update.Done == item.Done {
+		update.Done = nil // Checking a checked item again must not move its done_at
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Only write when something changes
+	updated := task
+	var err error
+	if update.Text != nil || update.Done != nil {
+		updated, err = h.Store.UpdateChecklistItem(dbCtx, scope, task.ID, item.ID, update)
+	}
+	if err == nil {
+		updated, err = h.presentTask(dbCtx, scope, updated, loc)
+	}
+	if !checklistWritten(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Checklist item updated successfully",
+		"task":    updated,
+	})
+}
+
+// RemoveChecklistItem deletes an item from the task's checklist
+func (h *Handler) RemoveChecklistItem(ctx *gin.Context) {
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+	item, ok := findChecklistItem(ctx, task)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	updated, err := h.Store.RemoveChecklistItem(dbCtx, scope, task.ID, item.ID)
+	if err == nil {
+		updated, err = h.presentTask(dbCtx, scope, updated, loc)
+	}
+	if !checklistWritten(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Checklist item removed successfully",
+		"task":    updated,
+	})
+}
+
+// ReorderChecklist puts the checklist in the order of item_ids, which must list every item exactly once
+// If items were added or removed since the client loaded the task, nothing changes and the answer is a 409
+func (h *Handler) ReorderChecklist(ctx *gin.Context) {
+	var req checklistOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(req.ItemIDs))
+	for _, itemID := range req.ItemIDs {
+		if seen[itemID] {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("item_ids lists %s twice", itemID.Hex())})
+			return
+		}
+		seen[itemID] = true
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	updated, err := h.Store.ReorderChecklist(dbCtx, scope, task.ID, req.ItemIDs)
+	if err == nil {
+		updated, err = h.presentTask(dbCtx, scope, updated, loc)
+	}
+	if !checklistWritten(ctx, err) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Checklist reordered successfully",
+		"task":    updated,
+	})
+}
+
+// checklistWritten writes the error response for a failed checklist change and returns false,
+// or returns true when err is nil
+func checklistWritten(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, store.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, store.ErrChecklistItemNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+	case errors.Is(err, store.ErrChecklistMismatch):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "item_ids must list every checklist item exactly once; reload the task and try again",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change the checklist: " + err.Error()})
+	}
+	return false
+}
+
+// findChecklistItem looks up :item_id in the task's checklist
+// It writes the error response itself and returns false when the request must stop
+func findChecklistItem(ctx *gin.Context, task model.Task) (model.ChecklistItem, bool) {
+	id, err := primitive.ObjectIDFromHex(ctx.Param("item_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid checklist item ID format"})
+		return model.ChecklistItem{}, false
+	}
+	for _, item := range task.Checklist {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	ctx.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+	return model.ChecklistItem{}, false
+}
+
+// checklistText trims an item's text and checks it is neither blank nor longer than maxChecklistText
+func checklistText(text string) (string, *helpers.Error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", &helpers.Error{Message: "Checklist item text is required", Status: http.StatusBadRequest}
+	}
+	if utf8.RuneCountInString(text) > maxChecklistText {
+		return "", &helpers.Error{
+			Message: fmt.Sprintf("Checklist item text must be at most %d characters", maxChecklistText),
+			Status:  http.StatusBadRequest,
+		}
+	}
+	return text, nil
+}
+
+// freshChecklist copies a checklist for the next occurrence of a recurring task:
+// the same items under new IDs, all unchecked
+func freshChecklist(checklist []model.ChecklistItem) []model.ChecklistItem {
+	if len(checklist) == 0 {
+		return nil
+	}
+	fresh := make([]model.ChecklistItem, len(checklist))
+	now := time.Now()
+	for i, item := range checklist {
+		fresh[i] = model.ChecklistItem{ID: primitive.NewObjectID(), Text: item.Text, CreatedAt: now}
+	}
+	return fresh
+}
@@ -0,0 +1,48 @@
+package task
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReorderChecklist(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("with a checklist")
+	var a, b, c string
+	for _, item := range []*string{&a, &b, &c} {
+		code, body := api.do(http.MethodPost, "/tasks/"+id+"/checklist", gin.H{"text": "step"})
+		if code != http.StatusCreated {
+			t.Fatalf("add item: got %d %v", code, body)
+		}
+		*item = body["item_id"].(string)
+	}
+
+	tests := []struct {
+		name    string
+		itemIDs []string
+		want    int
+	}{
+		{"repeated item in place of a missing one", []string{a, a, b}, http.StatusBadRequest},
+		{"missing item", []string{a, b}, http.StatusConflict},
+		{"every item once", []string{c, a, b}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := api.do(http.MethodPost, "/tasks/"+id+"/checklist/reorder", gin.H{"item_ids": tt.itemIDs})
+			if code != tt.want {
+				t.Fatalf("got %d %v, want %d", code, body, tt.want)
+			}
+		})
+	}
+
+	_, body := api.do(http.MethodGet, "/tasks/"+id, nil)
+	var got []string
+	for _, item := range body["task"].(map[string]interface{})["checklist"].([]interface{}) {
+		got = append(got, item.(map[string]interface{})["id"].(string))
+	}
+	if len(got) != 3 || got[0] != c || got[1] != a || got[2] != b {
+		t.Errorf("checklist = %v, want c, a, b", got)
+	}
+}
@@ -0,0 +1,317 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxCommentLength is the longest comment body accepted, in characters
+const maxCommentLength = 10000
+
+// commentRequest is the JSON body accepted by AddComment and EditComment
+type commentRequest struct {
+	Body string `json:"body"` // Markdown
+}
+
+// ListComments returns the task's comments, oldest first, with the same pagination as the task list
+// Deleted comments stay in the thread as placeholders without a body
+// Query parameters:
+// - page: current page number (default: 1)
+// - limit: number of comments per page (default: 20, max 100)
+func (h *Handler) ListComments(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	comments, total, err := h.Comments.ListComments(dbCtx, scope, task.ID, store.ListOptions{Page: page, Limit: limit})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comments: " + err.Error()})
+		return
+	}
+	for i := range comments {
+		comments[i] = presentComment(comments[i], loc)
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Comments retrieved successfully",
+		"task_id":  task.ID,
+		"comments": comments,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": totalPages,
+			"hasMore":    page < totalPages,
+		},
+	})
+}
+
+// AddComment posts a comment on the task as the authenticated user
+func (h *Handler) AddComment(ctx *gin.Context) {
+	body, ok := bindCommentBody(ctx)
+	if !ok {
+		return
+	}
+
+	user, ok := auth.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	task, _, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	comment := model.Comment{
+		ID:       primitive.NewObjectID(),
+		TaskID:   task.ID,
+		OwnerID:  task.OwnerID, // The task's owner, not the author: the thread is reachable wherever the task is
+		AuthorID: user.ID,
+		Body:     body,
+		Metadata: model.Metadata{CreatedAt: now, UpdatedAt: now},
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	if err := h.Comments.CreateComment(dbCtx, &comment); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save the comment: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Comment added successfully",
+		"comment": presentComment(comment, loc),
+	})
+}
+
+// EditComment replaces the body of one of the caller's comments
+// The previous body is kept and can be read through CommentHistory
+func (h *Handler) EditComment(ctx *gin.Context) {
+	body, ok := bindCommentBody(ctx)
+	if !ok {
+		return
+	}
+
+	comment, scope, loc, ok := h.loadOwnComment(ctx)
+	if !ok {
+		return
+	}
+	if comment.Body == body {
+		// Nothing changed: don't record a revision identical to the current body
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": "Comment updated successfully",
+			"comment": presentComment(comment, loc),
+		})
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	updated, err := h.Comments.EditComment(dbCtx, scope, comment.TaskID, comment.ID, body, time.Now())
+	if !commentWritten(ctx, err, "update") {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Comment updated successfully",
+		"comment": presentComment(updated, loc),
+	})
+}
+
+// DeleteComment soft-deletes one of the caller's comments: it stays in the thread without its body
+func (h *Handler) DeleteComment(ctx *gin.Context) {
+	comment, scope, _, ok := h.loadOwnComment(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	_, err := h.Comments.DeleteComment(dbCtx, scope, comment.TaskID, comment.ID, time.Now())
+	if !commentWritten(ctx, err, "delete") {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    "Comment deleted successfully",
+		"task_id":    comment.TaskID,
+		"comment_id": comment.ID,
+	})
+}
+
+// CommentHistory returns every body the comment has had, oldest first, ending with the current one
+// The history of a deleted comment is hidden together with its body
+func (h *Handler) CommentHistory(ctx *gin.Context) {
+	comment, _, loc, ok := h.loadComment(ctx)
+	if !ok {
+		return
+	}
+	if comment.IsDeleted() {
+		ctx.JSON(http.StatusGone, gin.H{"error": "Comment was deleted"})
+		return
+	}
+
+	revisions := make([]model.CommentRevision, 0, len(comment.History)+1)
+	for _, revision := range append(comment.History, model.CommentRevision{Body: comment.Body, WrittenAt: commentWrittenAt(comment)}) {
+		revision.WrittenAt = revision.WrittenAt.In(loc)
+		revisions = append(revisions, revision)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    "Comment history retrieved successfully",
+		"task_id":    comment.TaskID,
+		"comment_id": comment.ID,
+		"revisions":  revisions,
+	})
+}
+
+// loadComment loads the task named by :id, then its comment named by :comment_id
+// It writes the error response itself and returns false when the request must stop
+func (h *Handler) loadComment(ctx *gin.Context) (model.Comment, store.Scope, *time.Location, bool) {
+	commentID, err := primitive.ObjectIDFromHex(ctx.Param("comment_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID format"})
+		return model.Comment{}, store.Scope{}, nil, false
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return model.Comment{}, store.Scope{}, nil, false
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	comment, err := h.Comments.GetComment(dbCtx, scope, task.ID, commentID)
+	if errors.Is(err, store.ErrCommentNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return model.Comment{}, store.Scope{}, nil, false
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comment: " + err.Error()})
+		return model.Comment{}, store.Scope{}, nil, false
+	}
+	return comment, scope, loc, true
+}
+
+// loadOwnComment is loadComment for changes: only the author may edit or delete a comment
+func (h *Handler) loadOwnComment(ctx *gin.Context) (model.Comment, store.Scope, *time.Location, bool) {
+	user, ok := auth.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return model.Comment{}, store.Scope{}, nil, false
+	}
+
+	comment, scope, loc, ok := h.loadComment(ctx)
+	if !ok {
+		return model.Comment{}, store.Scope{}, nil, false
+	}
+	if comment.AuthorID != user.ID {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the author can change a comment"})
+		return model.Comment{}, store.Scope{}, nil, false
+	}
+	if comment.IsDeleted() {
+		ctx.JSON(http.StatusGone, gin.H{"error": "Comment was deleted"})
+		return model.Comment{}, store.Scope{}, nil, false
+	}
+	return comment, scope, loc, true
+}
+
+// commentWritten writes the error response for a failed comment change and returns false,
+// or returns true when err is nil
+func commentWritten(ctx *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, store.ErrCommentNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+	case errors.Is(err, store.ErrCommentDeleted):
+		// Deleted by another request between loading the comment and changing it
+		ctx.JSON(http.StatusGone, gin.H{"error": "Comment was deleted"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s comment: %v", action, err)})
+	}
+	return false
+}
+
+// bindCommentBody reads and checks the body of a comment: not blank and at most maxCommentLength characters
+// Surrounding whitespace is trimmed, the Markdown itself is stored as sent
+func bindCommentBody(ctx *gin.Context) (string, bool) {
+	var req commentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Comment body is required"})
+		return "", false
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Comment body must be at most %d characters", maxCommentLength),
+		})
+		return "", false
+	}
+	return body, true
+}
+
+// presentComment hides the body of a deleted comment and shows the times in the request's zone
+func presentComment(comment model.Comment, loc *time.Location) model.Comment {
+	if comment.IsDeleted() {
+		comment.Body = ""
+		deleted := comment.DeletedAt.In(loc)
+		comment.DeletedAt = &deleted
+	}
+	if comment.EditedAt != nil {
+		edited := comment.EditedAt.In(loc)
+		comment.EditedAt = &edited
+	}
+	comment.Metadata.CreatedAt = comment.Metadata.CreatedAt.In(loc)
+	comment.Metadata.UpdatedAt = comment.Metadata.UpdatedAt.In(loc)
+	return comment
+}
+
+// commentWrittenAt is when the comment got its current body: its last edit, or its creation
+func commentWrittenAt(comment model.Comment) time.Time {
+	if comment.EditedAt != nil {
+		return *comment.EditedAt
+	}
+	return comment.Metadata.CreatedAt
+}
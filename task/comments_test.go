@@ -0,0 +1,154 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// comment posts a comment on the task and returns its path
+func (api *testAPI) comment(taskID, body string) string {
+	api.t.Helper()
+	code, res := api.do(http.MethodPost, "/tasks/"+taskID+"/comments", gin.H{"body": body})
+	if code != http.StatusCreated {
+		api.t.Fatalf("comment: got %d %v", code, res)
+	}
+	return "/tasks/" + taskID + "/comments/" + res["comment"].(map[string]interface{})["id"].(string)
+}
+
+func TestAddCommentValidation(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("Discuss")
+
+	tests := []struct {
+		name string
+		body interface{}
+		want int
+	}{
+		{"markdown", gin.H{"body": "  **Looks good**  "}, http.StatusCreated},
+		{"blank", gin.H{"body": " \n "}, http.StatusBadRequest},
+		{"too long", gin.H{"body": strings.Repeat("é", maxCommentLength+1)}, http.StatusBadRequest},
+		{"longest allowed", gin.H{"body": strings.Repeat("é", maxCommentLength)}, http.StatusCreated},
+		{"malformed JSON", `{"body":`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if code, body := api.do(http.MethodPost, "/tasks/"+id+"/comments", tt.body); code != tt.want {
+			t.Errorf("%s: got %d %v, want %d", tt.name, code, body, tt.want)
+		}
+	}
+
+	_, body := api.do(http.MethodGet, "/tasks/"+id+"/comments", nil)
+	comments := body["comments"].([]interface{})
+	if len(comments) != 2 || comments[0].(map[string]interface{})["body"] != "**Looks good**" {
+		t.Errorf("comments = %v, want the trimmed Markdown first", comments)
+	}
+}
+
+func TestCommentEditHistory(t *testing.T) {
+	api := newTestAPI(t)
+	comment := api.comment(api.create("Discuss"), "first")
+
+	for _, body := range []string{"second", "second", "third"} {
+		if code, res := api.do(http.MethodPatch, comment, gin.H{"body": body}); code != http.StatusOK {
+			t.Fatalf("edit to %q: got %d %v", body, code, res)
+		}
+	}
+
+	code, body := api.do(http.MethodGet, comment+"/history", nil)
+	if code != http.StatusOK {
+		t.Fatalf("history: got %d %v", code, body)
+	}
+	var bodies []string
+	for _, revision := range body["revisions"].([]interface{}) {
+		bodies = append(bodies, revision.(map[string]interface{})["body"].(string))
+	}
+	// An edit that changes nothing isn't a revision
+	if strings.Join(bodies, ",") != "first,second,third" {
+		t.Errorf("revisions = %v", bodies)
+	}
+}
+
+func TestDeletedCommentStaysAsPlaceholder(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("Discuss")
+	comment := api.comment(id, "oops")
+
+	if code, body := api.do(http.MethodDelete, comment, nil); code != http.StatusOK {
+		t.Fatalf("delete: got %d %v", code, body)
+	}
+
+	_, body := api.do(http.MethodGet, "/tasks/"+id+"/comments", nil)
+	comments := body["comments"].([]interface{})
+	if len(comments) != 1 {
+		t.Fatalf("comments = %v, want the placeholder", comments)
+	}
+	if placeholder := comments[0].(map[string]interface{}); placeholder["body"] != "" || placeholder["deleted_at"] == nil {
+		t.Errorf("placeholder = %v", placeholder)
+	}
+
+	for _, req := range []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodPatch, comment, gin.H{"body": "again"}},
+		{http.MethodDelete, comment, nil},
+		{http.MethodGet, comment + "/history", nil},
+	} {
+		if code, _ := api.do(req.method, req.path, req.body); code != http.StatusGone {
+			t.Errorf("%s %s on a deleted comment: got %d, want 410", req.method, req.path, code)
+		}
+	}
+}
+
+func TestCommentPermissions(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("Discuss")
+	mine := api.comment(id, "mine")
+
+	// Someone else's comment on the caller's task, like one an admin left
+	_, body := api.do(http.MethodGet, "/tasks/"+id, nil)
+	ownerID, _ := primitive.ObjectIDFromHex(body["task"].(map[string]interface{})["owner_id"].(string))
+	taskID, _ := primitive.ObjectIDFromHex(id)
+	other := model.Comment{
+		ID: primitive.NewObjectID(), TaskID: taskID, OwnerID: ownerID, AuthorID: primitive.NewObjectID(),
+		Body: "theirs", Metadata: model.Metadata{CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+	if err := api.stores.Comments.CreateComment(context.Background(), &other); err != nil {
+		t.Fatal(err)
+	}
+	theirs := "/tasks/" + id + "/comments/" + other.ID.Hex()
+
+	if code, _ := api.do(http.MethodPatch, theirs, gin.H{"body": "edited"}); code != http.StatusForbidden {
+		t.Errorf("edit another author's comment: got %d, want 403", code)
+	}
+	if code, _ := api.do(http.MethodDelete, theirs, nil); code != http.StatusForbidden {
+		t.Errorf("delete another author's comment: got %d, want 403", code)
+	}
+	if code, _ := api.do(http.MethodGet, theirs+"/history", nil); code != http.StatusOK {
+		t.Errorf("read another author's history: got %d, want 200", code)
+	}
+
+	// The thread is as private as its task
+	stranger := api.newUser("stranger")
+	requests := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodGet, "/tasks/" + id + "/comments", nil},
+		{http.MethodPost, "/tasks/" + id + "/comments", gin.H{"body": "hi"}},
+		{http.MethodPatch, mine, gin.H{"body": "hijacked"}},
+		{http.MethodDelete, mine, nil},
+		{http.MethodGet, mine + "/history", nil},
+	}
+	for _, req := range requests {
+		if code, _ := api.doAs(stranger, req.method, req.path, req.body); code != http.StatusNotFound {
+			t.Errorf("%s %s by another user: got %d, want 404", req.method, req.path, code)
+		}
+	}
+}
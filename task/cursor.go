@@ -0,0 +1,82 @@
+package task
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cursorToken is what a signed cursor carries: the position of a task in the list and the sort it was taken in
+type cursorToken struct {
+	Sort   string             `json:"s"`
+	Values []interface{}      `json:"v"`
+	ID     primitive.ObjectID `json:"id"`
+	Before bool               `json:"b,omitempty"`
+}
+
+// timeSortFields are the sort fields whose values are times; JSON turns them into strings
+var timeSortFields = map[string]bool{"created_at": true, "updated_at": true, "start_at": true, "due_at": true}
+
+// sortString writes keys back the way the sort parameter spells them
+func sortString(keys store.TaskSort) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		name := key.Field
+		if key.Custom {
+			name = fieldParamPrefix + name
+		}
+		if key.Desc {
+			name = "-" + name
+		}
+		parts[i] = name
+	}
+	return strings.Join(parts, ",")
+}
+
+// readCursor checks a cursor and returns the position it points at
+// It writes a 400 and returns false for a cursor that was tampered with or taken in another sort
+func (h *Handler) readCursor(ctx *gin.Context, raw string, keys store.TaskSort) (store.Position, bool) {
+	invalid := func() (store.Position, bool) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor: use the next and prev links as they are"})
+		return store.Position{}, false
+	}
+
+	var token cursorToken
+	if err := h.Cursors.Decode(raw, &token); err != nil || len(token.Values) != len(keys) {
+		return invalid()
+	}
+	if token.Sort != sortString(keys) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "The cursor belongs to sort=" + token.Sort + ": keep the sort while paging"})
+		return store.Position{}, false
+	}
+
+	for i, key := range keys {
+		text, isText := token.Values[i].(string)
+		if key.Custom || !timeSortFields[key.Field] || !isText {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, text)
+		if err != nil {
+			return invalid()
+		}
+		token.Values[i] = t
+	}
+	return store.Position{Values: token.Values, ID: token.ID, Before: token.Before}, true
+}
+
+// pageLink returns the URL of the page next to pos, keeping every other query parameter of the request
+// A link always uses a cursor, even from a page asked for by number
+func (h *Handler) pageLink(ctx *gin.Context, keys store.TaskSort, pos store.Position) (string, error) {
+	token, err := h.Cursors.Encode(cursorToken{Sort: sortString(keys), Values: pos.Values, ID: pos.ID, Before: pos.Before})
+	if err != nil {
+		return "", err
+	}
+	query := ctx.Request.URL.Query()
+	query.Del("page")
+	query.Set("cursor", token)
+	return ctx.Request.URL.Path + "?" + query.Encode(), nil
+}
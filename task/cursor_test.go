@@ -0,0 +1,119 @@
+package task
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/cursor"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cursorContext is a request to GET /tasks with the given query
+func cursorContext(query string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/tasks?"+query, nil)
+	return ctx, rec
+}
+
+// cursorFromLink pulls the cursor parameter out of a next or prev link
+func cursorFromLink(t *testing.T, link string) string {
+	t.Helper()
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Query().Get("cursor")
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	h := &Handler{Cursors: cursor.New([]byte("secret"))}
+	keys := store.TaskSort{{Field: "priority", Desc: true}, {Field: "due_at"}, {Field: "sev", Custom: true}}
+	due := time.Date(2026, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	task := model.Task{ID: primitive.NewObjectID(), Priority: model.PriorityHigh, DueAt: &due, Fields: model.FieldValues{"sev": 2.0}}
+	pos := store.PositionOf(keys, task)
+	pos.Before = true
+
+	ctx, _ := cursorContext("sort=-priority,due_at,field.sev&page=3&limit=5")
+	link, err := h.pageLink(ctx, keys, pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(link, "page=") || !strings.Contains(link, "limit=5") {
+		t.Errorf("link %q should drop page and keep the other parameters", link)
+	}
+
+	got, ok := h.readCursor(ctx, cursorFromLink(t, link), keys)
+	if !ok {
+		t.Fatal("readCursor rejected its own cursor")
+	}
+	if got.ID != task.ID || !got.Before {
+		t.Errorf("position = %+v", got)
+	}
+	// Times come back as times, to the nanosecond, so the keyset condition compares like with like
+	if at, isTime := got.Values[1].(time.Time); !isTime || !at.Equal(due) {
+		t.Errorf("due_at value = %#v, want %v", got.Values[1], due)
+	}
+	if got.Values[0] != float64(2) || got.Values[2] != 2.0 {
+		t.Errorf("values = %#v", got.Values)
+	}
+}
+
+func TestReadCursorRejects(t *testing.T) {
+	h := &Handler{Cursors: cursor.New([]byte("secret"))}
+	keys := store.TaskSort{{Field: "title"}}
+	task := model.Task{ID: primitive.NewObjectID(), Title: "a"}
+
+	ctx, _ := cursorContext("sort=title")
+	link, err := h.pageLink(ctx, keys, store.PositionOf(keys, task))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := cursorFromLink(t, link)
+	foreign, err := (&Handler{Cursors: cursor.New([]byte("other"))}).pageLink(ctx, keys, store.PositionOf(keys, task))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		keys  store.TaskSort
+		want  string
+	}{
+		{"different sort", valid, store.TaskSort{{Field: "title", Desc: true}}, "keep the sort"},
+		{"more keys than the cursor", valid, store.TaskSort{{Field: "title"}, {Field: "due_at"}}, "Invalid cursor"},
+		{"tampered signature", tampered(valid), keys, "Invalid cursor"},
+		{"other secret", cursorFromLink(t, foreign), keys, "Invalid cursor"},
+		{"malformed", "not-a-cursor", keys, "Invalid cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, rec := cursorContext("")
+			if _, ok := h.readCursor(ctx, tt.token, tt.keys); ok {
+				t.Fatal("readCursor accepted the cursor")
+			}
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("got %d %s, want 400 mentioning %q", rec.Code, rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+// tampered changes one character in the middle of the signature
+func tampered(token string) string {
+	i := strings.IndexByte(token, '.') + 10
+	c := byte('A')
+	if token[i] == 'A' {
+		c = 'B'
+	}
+	return token[:i] + string(c) + token[i+1:]
+}
@@ -0,0 +1,136 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fieldParamPrefix starts the query parameters that filter on custom fields, like ?field.severity=high
+const fieldParamPrefix = "field."
+
+// fieldOps lists the comparisons a custom field filter may use after its key, like ?field.estimate.gte=3
+var fieldOps = map[string]store.FieldOp{
+	"eq":  store.FieldEq,
+	"gt":  store.FieldGt,
+	"gte": store.FieldGte,
+	"lt":  store.FieldLt,
+	"lte": store.FieldLte,
+}
+
+// checkFieldValues validates the custom field values sent for a task of ownerID and returns them normalized
+// Cleared values stay in the map as nil, so a patch knows which keys to remove
+func (h *Handler) checkFieldValues(ctx context.Context, ownerID primitive.ObjectID, values model.FieldValues) (model.FieldValues, []model.CustomField, *helpers.Error) {
+	defs, err := h.CustomFields.ListCustomFields(ctx, store.OwnedBy(ownerID))
+	if err != nil {
+		return nil, nil, &helpers.Error{Message: "Failed to load custom fields: " + err.Error(), Status: http.StatusInternalServerError}
+	}
+	normalized, verr := helpers.ValidateFieldValues(defs, values)
+	if verr != nil {
+		return nil, nil, verr
+	}
+	return normalized, defs, nil
+}
+
+// parseFieldQuery reads the custom field filters from the query string, then the sort order with parseSort
+// - field.<key>=value: tasks whose value equals value; on a multi_select field, tasks that picked it
+// - field.<key>.<op>=value: compare with gt, gte, lt or lte, for text, number and date fields
+// Values are read by the field's type; checkbox=false also matches tasks where the box was never touched
+// The definitions are only loaded when the query mentions a custom field
+// It writes a 400 and returns false for an unknown key or a value the field can't hold
+func (h *Handler) parseFieldQuery(ctx *gin.Context, dbCtx context.Context, scope store.Scope, filter *store.TaskFilter) (store.TaskSort, bool) {
+	query := ctx.Request.URL.Query()
+
+	var params []string
+	for param := range query {
+		if strings.HasPrefix(param, fieldParamPrefix) {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 && !strings.Contains(ctx.Query("sort"), fieldParamPrefix) {
+		return parseSort(ctx, nil)
+	}
+	sort.Strings(params) // Report errors in a stable order
+
+	// Admins listing every owner see all definitions; if two owners use a key differently, the oldest wins
+	defs, err := h.CustomFields.ListCustomFields(dbCtx, scope)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load custom fields: " + err.Error()})
+		return nil, false
+	}
+	byKey := make(map[string]model.CustomField, len(defs))
+	for _, def := range defs {
+		if _, seen := byKey[def.Key]; !seen {
+			byKey[def.Key] = def
+		}
+	}
+
+	for _, param := range params {
+		key, opName, _ := strings.Cut(strings.TrimPrefix(param, fieldParamPrefix), ".")
+		def, ok := byKey[key]
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown custom field %q", key)})
+			return nil, false
+		}
+		op := store.FieldEq
+		if opName != "" {
+			if op, ok = fieldOps[opName]; !ok {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown comparison %q: use eq, gt, gte, lt or lte", opName)})
+				return nil, false
+			}
+		}
+		if op != store.FieldEq && (def.Type.HasOptions() || def.Type == model.FieldCheckbox) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Custom field %q can only be compared with eq", key)})
+			return nil, false
+		}
+
+		for _, raw := range query[param] {
+			condition, err := fieldCondition(def, op, raw)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s: %s", param, err.Error())})
+				return nil, false
+			}
+			filter.Fields = append(filter.Fields, condition)
+		}
+	}
+
+	return parseSort(ctx, byKey)
+}
+
+// fieldCondition reads a filter value as the type of the field
+func fieldCondition(def model.CustomField, op store.FieldOp, raw string) (store.FieldCondition, error) {
+	condition := store.FieldCondition{Key: def.Key, Op: op}
+	switch def.Type {
+	case model.FieldNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return condition, fmt.Errorf("use a number")
+		}
+		condition.Value = number
+	case model.FieldDate:
+		if _, err := time.Parse(dateOnly, raw); err != nil {
+			return condition, fmt.Errorf("use a YYYY-MM-DD date")
+		}
+		condition.Value = raw
+	case model.FieldCheckbox:
+		checked, err := strconv.ParseBool(raw)
+		if err != nil {
+			return condition, fmt.Errorf("use true or false")
+		}
+		condition.Value = checked
+		condition.Missing = !checked // A box nobody touched is unchecked
+	default:
+		condition.Value = raw
+	}
+	return condition, nil
+}
@@ -1,18 +1,38 @@
 package task
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joshua-takyi/todo/connection"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func DeleteTask(ctx *gin.Context) {
+// Delete modes decide what happens to the subtasks of a deleted task
+const (
+	deleteBlock   = "block"   // Refuse to delete a task that still has subtasks (default)
+	deleteOrphan  = "orphan"  // Keep the subtasks as top-level tasks
+	deleteCascade = "cascade" // Delete the subtasks too, at every depth
+)
+
+// DeleteTask deletes a task
+// Query parameters:
+// - mode: block (default), orphan or cascade, see the delete modes above
+func (h *Handler) DeleteTask(ctx *gin.Context) {
+	h.deleteTask(ctx, deleteBlock)
+}
+
+// ForceDeleteTask deletes a task for an admin: its subtasks go with it unless ?mode= says otherwise
+func (h *Handler) ForceDeleteTask(ctx *gin.Context) {
+	h.deleteTask(ctx, deleteCascade)
+}
 
+// deleteTask deletes the task in the URL, treating its subtasks as defaultMode says when ?mode= is missing
+func (h *Handler) deleteTask(ctx *gin.Context, defaultMode string) {
 	paramId := ctx.Param("id")
 
 	if paramId == "" {
@@ -20,6 +40,7 @@ func DeleteTask(ctx *gin.Context) {
 			"message": "Task not found",
 			"error":   fmt.Sprintf("Task with ID %s not found", paramId),
 		})
+		return
 	}
 
 	id, err := primitive.ObjectIDFromHex(paramId)
@@ -32,11 +53,67 @@ func DeleteTask(ctx *gin.Context) {
 		return
 	}
 
-	filter := bson.M{"_id": id}
+	mode := ctx.DefaultQuery("mode", defaultMode)
+	if mode != deleteBlock && mode != deleteOrphan && mode != deleteCascade {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid delete mode",
+			"error":   "mode must be 'block', 'orphan' or 'cascade'",
+		})
+		return
+	}
+
+	// Work out which tasks this request may reach (normally only the caller's own)
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Note the files and comments to remove before the tasks pointing at them are gone
+	doomed, err := h.doomedTasks(dbCtx, scope, id, mode == deleteCascade)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to delete task",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	switch mode {
+	case deleteCascade:
+		_, err = h.Store.DeleteTree(dbCtx, scope, id)
 
-	collection := connection.Client.Database("Go").Collection("tasks")
+	case deleteOrphan:
+		// Detach first: if the delete then fails, the subtasks are still there as top-level tasks
+		if _, err = h.Store.DetachChildren(dbCtx, scope, id); err == nil {
+			err = h.Store.Delete(dbCtx, scope, id)
+		}
 
-	_, err = collection.DeleteOne(context.Background(), filter)
+	default:
+		var children []model.Task
+		children, err = h.Store.ListChildren(dbCtx, scope, id)
+		if err == nil && len(children) > 0 {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"message": "Task has subtasks",
+				"error": fmt.Sprintf("Task %s has %d subtask(s), delete with ?mode=orphan or ?mode=cascade",
+					paramId, len(children)),
+			})
+			return
+		}
+		if err == nil {
+			err = h.Store.Delete(dbCtx, scope, id)
+		}
+	}
+
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"message": "Task not found",
+			"error":   fmt.Sprintf("Task with ID %s not found", paramId),
+		})
+		return
+	}
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -46,6 +123,26 @@ func DeleteTask(ctx *gin.Context) {
 		return
 	}
 
+	// The tasks are gone, so leftovers can't be reached anymore: failing to remove them is only logged
+	var (
+		taskIDs     []primitive.ObjectID
+		attachments []model.Attachment
+	)
+	for _, task := range doomed {
+		taskIDs = append(taskIDs, task.ID)
+		attachments = append(attachments, task.Attachments...)
+	}
+	if _, err := h.Comments.DeleteCommentsByTasks(dbCtx, taskIDs); err != nil {
+		fmt.Printf("Warning: failed to delete the comments of task %s: %v\n", paramId, err)
+	}
+	if _, err := h.TimeEntries.DeleteTimeEntriesByTasks(dbCtx, taskIDs); err != nil {
+		fmt.Printf("Warning: failed to delete the time entries of task %s: %v\n", paramId, err)
+	}
+
+	blobCtx, cancelBlob := blobContext(ctx)
+	defer cancelBlob()
+	h.deleteFiles(blobCtx, attachments)
+
 	ctx.JSON(http.StatusNoContent, gin.H{
 		"message": "Task deleted successfully",
 	})
@@ -0,0 +1,216 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dependencyRequest is the JSON body accepted by AddDependency
+type dependencyRequest struct {
+	BlockerID primitive.ObjectID `json:"blocker_id" binding:"required"`
+}
+
+// openBlockers returns the IDs of the task's blockers that are not completed yet
+// Blockers that were deleted don't block anymore
+func (h *Handler) openBlockers(ctx context.Context, scope store.Scope, task model.Task) ([]primitive.ObjectID, error) {
+	open := []primitive.ObjectID{}
+	if len(task.BlockedBy) == 0 {
+		return open, nil
+	}
+
+	blockers, err := h.Store.GetMany(ctx, scope, task.BlockedBy)
+	if err != nil {
+		return nil, err
+	}
+	for _, blocker := range blockers {
+		if !blocker.Completed {
+			open = append(open, blocker.ID)
+		}
+	}
+	return open, nil
+}
+
+// markBlocked computes the blocked flag for a list of tasks
+// All blockers of the whole list are loaded with one GetMany call
+func (h *Handler) markBlocked(ctx context.Context, scope store.Scope, tasks []model.Task) error {
+	var ids []primitive.ObjectID
+	for _, task := range tasks {
+		ids = append(ids, task.BlockedBy...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	blockers, err := h.Store.GetMany(ctx, scope, ids)
+	if err != nil {
+		return err
+	}
+	open := make(map[primitive.ObjectID]bool, len(blockers))
+	for _, blocker := range blockers {
+		open[blocker.ID] = !blocker.Completed
+	}
+
+	for i := range tasks {
+		tasks[i].Blocked = false
+		for _, blockerID := range tasks[i].BlockedBy {
+			if open[blockerID] {
+				tasks[i].Blocked = true
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// presentTasks is presentAll plus the blocked flag, which needs the blockers from the store
+func (h *Handler) presentTasks(ctx context.Context, scope store.Scope, tasks []model.Task, loc *time.Location) ([]model.Task, error) {
+	if err := h.markBlocked(ctx, scope, tasks); err != nil {
+		return nil, err
+	}
+	return presentAll(tasks, loc), nil
+}
+
+// presentTask is presentTasks for a single task
+func (h *Handler) presentTask(ctx context.Context, scope store.Scope, task model.Task, loc *time.Location) (model.Task, error) {
+	tasks, err := h.presentTasks(ctx, scope, []model.Task{task}, loc)
+	if err != nil {
+		return model.Task{}, err
+	}
+	return tasks[0], nil
+}
+
+// ListDependencies returns the tasks that block a task
+func (h *Handler) ListDependencies(ctx *gin.Context) {
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	blockers, err := h.Store.GetMany(dbCtx, scope, task.BlockedBy)
+	if err == nil {
+		blockers, err = h.presentTasks(dbCtx, scope, blockers, loc)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dependencies: " + err.Error()})
+		return
+	}
+
+	blocked := false
+	for _, blocker := range blockers {
+		blocked = blocked || !blocker.Completed
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    "Dependencies retrieved successfully",
+		"task_id":    task.ID,
+		"blocked":    blocked,
+		"blocked_by": blockers,
+	})
+}
+
+// AddDependency records that the task in the body blocks the task in the URL
+// Edges that would create a cycle (A blocks B blocks A) are rejected with a 409
+func (h *Handler) AddDependency(ctx *gin.Context) {
+	var req dependencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// The blocker must be one of the tasks this request may reach
+	if _, err := h.Store.Get(dbCtx, scope, req.BlockerID); errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Blocker task not found"})
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load blocker task: " + err.Error()})
+		return
+	}
+
+	// The store checks for a cycle in the same step as the write, so concurrent requests can't sneak one in
+	updated, err := h.Store.AddDependency(dbCtx, scope, task.ID, req.BlockerID)
+	if err == nil {
+		updated, err = h.presentTask(dbCtx, scope, updated, loc)
+	}
+	if errors.Is(err, store.ErrDependencyCycle) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "This dependency would create a cycle"})
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add dependency: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Dependency added successfully",
+		"task":    updated,
+	})
+}
+
+// RemoveDependency deletes the "blocker blocks task" edge
+func (h *Handler) RemoveDependency(ctx *gin.Context) {
+	blockerID, err := primitive.ObjectIDFromHex(ctx.Param("blocker_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid blocker ID format"})
+		return
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	updated, err := h.Store.RemoveDependency(dbCtx, scope, task.ID, blockerID)
+	if err == nil {
+		updated, err = h.presentTask(dbCtx, scope, updated, loc)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if errors.Is(err, store.ErrDependencyNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove dependency: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Dependency removed successfully",
+		"task":    updated,
+	})
+}
+
+// forceRequested reads ?force=true, which lets MarkAsComplete ignore open blockers
+func forceRequested(ctx *gin.Context) bool {
+	force, err := strconv.ParseBool(ctx.DefaultQuery("force", "false"))
+	return err == nil && force
+}
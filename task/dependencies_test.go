@@ -0,0 +1,62 @@
+package task
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDependencies(t *testing.T) {
+	api := newTestAPI(t)
+	a, b, c := api.create("a"), api.create("b"), api.create("c")
+	block := func(task, blocker string) (int, map[string]interface{}) {
+		t.Helper()
+		return api.do(http.MethodPost, "/tasks/"+task+"/dependencies", gin.H{"blocker_id": blocker})
+	}
+
+	// a blocks b blocks c
+	if code, body := block(b, a); code != http.StatusCreated || body["task"].(map[string]interface{})["blocked"] != true {
+		t.Fatalf("a blocks b: got %d %v", code, body)
+	}
+	if code, body := block(c, b); code != http.StatusCreated {
+		t.Fatalf("b blocks c: got %d %v", code, body)
+	}
+
+	tests := []struct {
+		name          string
+		task, blocker string
+		want          int
+	}{
+		{"itself", a, a, http.StatusConflict},
+		{"direct cycle", a, b, http.StatusConflict},
+		{"cycle through another task", a, c, http.StatusConflict},
+		{"unknown blocker", a, primitive.NewObjectID().Hex(), http.StatusBadRequest},
+		{"malformed blocker", a, "nope", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, body := block(tt.task, tt.blocker); code != tt.want {
+				t.Errorf("got %d %v, want %d", code, body, tt.want)
+			}
+		})
+	}
+
+	_, body := api.do(http.MethodGet, "/tasks/"+c+"/dependencies", nil)
+	if blockers := body["blocked_by"].([]interface{}); len(blockers) != 1 || body["blocked"] != true {
+		t.Errorf("dependencies of c = %v", body)
+	}
+
+	// Completing the blocker unblocks; removing the edge twice is a 404 the second time
+	api.do(http.MethodPatch, "/tasks/"+a+"/complete", nil)
+	if _, body := api.do(http.MethodGet, "/tasks/"+b, nil); body["task"].(map[string]interface{})["blocked"] == true {
+		t.Errorf("b is still blocked after a was completed")
+	}
+	if code, _ := api.do(http.MethodDelete, "/tasks/"+c+"/dependencies/"+b, nil); code != http.StatusOK {
+		t.Errorf("remove edge: got %d, want 200", code)
+	}
+	if code, _ := api.do(http.MethodDelete, "/tasks/"+c+"/dependencies/"+b, nil); code != http.StatusNotFound {
+		t.Errorf("remove missing edge: got %d, want 404", code)
+	}
+}
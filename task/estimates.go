@@ -0,0 +1,182 @@
+package task
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxEstimateTasks caps how many tasks one estimates request adds up
+const maxEstimateTasks = 1000
+
+// defaultVelocityWeeks is how many weeks, the current one included, the report covers without ?from=
+const defaultVelocityWeeks = 8
+
+// maxVelocityWeeks caps how many weeks one report may list
+const maxVelocityWeeks = 104
+
+// effort adds up the estimates of a set of tasks next to the time actually tracked on them
+type effort struct {
+	Tasks           int   `json:"tasks"`
+	Estimated       int   `json:"estimated"` // Tasks with points or a time estimate
+	Points          int   `json:"points"`
+	EstimateMinutes int   `json:"estimate_minutes"`
+	ActualMinutes   int64 `json:"actual_minutes"` // Stopped time entries, rounded to the minute
+}
+
+// velocityWeek is the effort of the tasks completed in one week
+type velocityWeek struct {
+	WeekStart string `json:"week_start"` // Monday of the week, YYYY-MM-DD in the request's zone
+	effort
+}
+
+// add counts one task with the seconds tracked on it
+func (e *effort) add(task model.Task, seconds int64) {
+	e.Tasks++
+	if task.Points != nil || task.Estimate != nil {
+		e.Estimated++
+	}
+	if task.Points != nil {
+		e.Points += *task.Points
+	}
+	if task.Estimate != nil {
+		e.EstimateMinutes += *task.Estimate
+	}
+	e.ActualMinutes += seconds
+}
+
+// inMinutes turns the seconds gathered in ActualMinutes into whole minutes
+func (e effort) inMinutes() effort {
+	e.ActualMinutes = (e.ActualMinutes + 30) / 60
+	return e
+}
+
+// GetEstimates sums up estimates against tracked time for the tasks matching the list filters,
+// and the velocity: the estimates of the tasks completed each week
+// Query parameters:
+// - the filters of the task list read by parseTaskFilter (dates, overdue, project_id, status, tags, priority, completed, title)
+// - from / to: weeks of completion to report, as RFC 3339 times or YYYY-MM-DD dates (default: the last 8 weeks)
+// - tz: IANA time zone weeks start in, on Monday (default: UTC)
+func (h *Handler) GetEstimates(ctx *gin.Context) {
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return
+	}
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+	filter, ok := parseTaskFilter(ctx, loc)
+	if !ok {
+		return
+	}
+
+	var fromParam, toParam *time.Time
+	if !parseTimeBounds(ctx, loc, timeBound{"from", &fromParam}, timeBound{"to", &toParam}) {
+		return
+	}
+	now := time.Now()
+	from := weekStart(now, loc).AddDate(0, 0, -7*(defaultVelocityWeeks-1))
+	if fromParam != nil {
+		from = *fromParam
+	}
+	to := now
+	if toParam != nil {
+		to = *toParam
+	}
+	if !from.Before(to) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	// Every week touched by [from, to) gets a line, even when nothing was completed in it
+	var weeks []velocityWeek
+	index := map[time.Time]int{}
+	for week := weekStart(from, loc); week.Before(to); week = week.AddDate(0, 0, 7) {
+		if len(weeks) == maxVelocityWeeks {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("from and to may span at most %d weeks", maxVelocityWeeks),
+			})
+			return
+		}
+		index[week] = len(weeks)
+		weeks = append(weeks, velocityWeek{WeekStart: week.Format(dateOnly)})
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	tasks, total, err := h.Store.List(dbCtx, scope, store.ListOptions{Page: 1, Limit: maxEstimateTasks, Filter: filter})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks: " + err.Error()})
+		return
+	}
+
+	// Tracked time comes from the stopped time entries of the same tasks
+	ids := make([]primitive.ObjectID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	sums, err := h.TimeEntries.SumTimeByTask(dbCtx, scope, store.TimeFilter{TaskIDs: ids})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tracked time: " + err.Error()})
+		return
+	}
+	tracked := make(map[primitive.ObjectID]int64, len(sums))
+	for _, sum := range sums {
+		tracked[sum.TaskID] = sum.Seconds
+	}
+
+	var all, done effort
+	for _, task := range tasks {
+		seconds := tracked[task.ID]
+		all.add(task, seconds)
+
+		completedAt, ok := doneAt(task)
+		if !ok || completedAt.Before(from) || !completedAt.Before(to) {
+			continue
+		}
+		done.add(task, seconds)
+		weeks[index[weekStart(completedAt, loc)]].effort.add(task, seconds)
+	}
+
+	for i := range weeks {
+		weeks[i].effort = weeks[i].effort.inMinutes()
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Estimates retrieved successfully",
+		"from":      from.In(loc),
+		"to":        to.In(loc),
+		"total":     all.inMinutes(),  // Every matching task, open or not
+		"completed": done.inMinutes(), // The matching tasks done between from and to
+		"weeks":     weeks,
+		"velocity":  float64(done.Points) / float64(len(weeks)), // Points completed per week on average
+		"truncated": total > int64(len(tasks)),
+	})
+}
+
+// doneAt returns when a task was completed; cancelled tasks were never done, so they don't count
+// Tasks done before statuses had timestamps fall back to their last update
+func doneAt(task model.Task) (time.Time, bool) {
+	if task.EffectiveStatus() != model.StatusDone {
+		return time.Time{}, false
+	}
+	if at, ok := task.StatusSince[model.StatusDone]; ok {
+		return at, true
+	}
+	return task.Metadata.UpdatedAt, true
+}
+
+// weekStart returns midnight on the Monday starting the week of t in loc
+func weekStart(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	return midnight.AddDate(0, 0, -(int(midnight.Weekday())+6)%7)
+}
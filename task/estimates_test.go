@@ -0,0 +1,122 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshua-takyi/todo/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEstimatesRange(t *testing.T) {
+	api := newTestAPI(t)
+	tests := []struct {
+		query string
+		want  int
+		error string
+	}{
+		{"", http.StatusOK, ""},
+		{"from=2026-01-05&to=2026-02-02", http.StatusOK, ""},
+		{"from=yesterday", http.StatusBadRequest, "Invalid from"},
+		// With both wrong, the answer must not depend on map iteration order
+		{"from=yesterday&to=soon", http.StatusBadRequest, "Invalid from"},
+		{"from=2026-02-02&to=2026-01-05", http.StatusBadRequest, "from must be before to"},
+		{"from=2020-01-06&to=2026-01-05", http.StatusBadRequest, "from and to may span at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			for range 20 {
+				code, body := api.do(http.MethodGet, "/estimates?"+tt.query, nil)
+				if code != tt.want {
+					t.Fatalf("got %d %v, want %d", code, body, tt.want)
+				}
+				if msg, _ := body["error"].(string); !strings.HasPrefix(msg, tt.error) {
+					t.Fatalf("error = %q, want it to start with %q", msg, tt.error)
+				}
+			}
+		})
+	}
+
+	// Without a range the last weeks are reported, ending with the current one
+	_, body := api.do(http.MethodGet, "/estimates", nil)
+	if weeks, _ := body["weeks"].([]interface{}); len(weeks) != defaultVelocityWeeks {
+		t.Errorf("got %d weeks, want %d", len(weeks), defaultVelocityWeeks)
+	}
+}
+
+func TestVelocity(t *testing.T) {
+	api := newTestAPI(t)
+	ctx := context.Background()
+	_, body := api.do(http.MethodGet, "/tasks/"+api.create("open, unestimated"), nil)
+	owner, _ := primitive.ObjectIDFromHex(body["task"].(map[string]interface{})["owner_id"].(string))
+
+	number := func(n int) *int { return &n }
+	// add stores a task that reached status at the given time, the way no request could backdate it
+	add := func(status model.Status, at time.Time, points, estimate *int) model.Task {
+		t.Helper()
+		task := model.Task{
+			ID: primitive.NewObjectID(), OwnerID: owner, Title: "t", Description: "d", Priority: model.PriorityLow,
+			Tags: []string{"a"}, Status: status, Completed: status == model.StatusDone,
+			StatusSince: model.StatusTimes{status: at}, Points: points, Estimate: estimate,
+			Metadata: model.Metadata{CreatedAt: at, UpdatedAt: at},
+		}
+		if err := api.stores.Tasks.Create(ctx, &task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	day := func(d, hour, minute int) time.Time { return time.Date(2026, 1, d, hour, minute, 0, 0, time.UTC) }
+
+	first := add(model.StatusDone, day(6, 10, 0), number(3), number(60))
+	add(model.StatusDone, day(20, 9, 0), number(5), nil)
+	add(model.StatusDone, day(26, 0, 0), number(2), nil)     // The end of the range is exclusive
+	add(model.StatusCancelled, day(7, 0, 0), number(8), nil) // Cancelled tasks were never done
+	add(model.StatusTodo, day(7, 0, 0), nil, number(30))     // Open tasks only count in the total
+	add(model.StatusDone, day(4, 23, 30), number(1), nil)    // Sunday in UTC, Monday in Berlin
+
+	ended := day(6, 9, 0)
+	entry := model.TimeEntry{
+		ID: primitive.NewObjectID(), TaskID: first.ID, OwnerID: owner, UserID: owner,
+		StartedAt: ended.Add(-50 * time.Minute), EndedAt: &ended, Seconds: 50 * 60,
+	}
+	if err := api.stores.TimeEntries.CreateTimeEntry(ctx, &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	field := func(v interface{}, key string) float64 {
+		return v.(map[string]interface{})[key].(float64)
+	}
+
+	code, body := api.do(http.MethodGet, "/estimates?from=2026-01-05&to=2026-01-26", nil)
+	if code != http.StatusOK {
+		t.Fatalf("estimates: got %d %v", code, body)
+	}
+	total := body["total"]
+	if field(total, "tasks") != 7 || field(total, "estimated") != 6 || field(total, "points") != 19 ||
+		field(total, "estimate_minutes") != 90 || field(total, "actual_minutes") != 50 {
+		t.Errorf("total = %v", total)
+	}
+	completed := body["completed"]
+	if field(completed, "tasks") != 2 || field(completed, "points") != 8 || field(completed, "actual_minutes") != 50 {
+		t.Errorf("completed = %v", completed)
+	}
+	weeks := body["weeks"].([]interface{})
+	if len(weeks) != 3 || field(weeks[0], "points") != 3 || field(weeks[1], "tasks") != 0 || field(weeks[2], "points") != 5 {
+		t.Errorf("weeks = %v", weeks)
+	}
+	if weeks[0].(map[string]interface{})["week_start"] != "2026-01-05" {
+		t.Errorf("first week starts %v, want Monday 2026-01-05", weeks[0])
+	}
+	if body["velocity"] != 8.0/3 {
+		t.Errorf("velocity = %v, want 8 points over 3 weeks", body["velocity"])
+	}
+
+	// Weeks start on Monday in the requested zone
+	_, body = api.do(http.MethodGet, "/estimates?from=2026-01-05&to=2026-01-26&tz=Europe/Berlin", nil)
+	if weeks := body["weeks"].([]interface{}); field(weeks[0], "points") != 4 || field(weeks[0], "tasks") != 2 {
+		t.Errorf("first Berlin week = %v, want the Sunday-night task in it", weeks[0])
+	}
+}
@@ -1,24 +1,36 @@
 package task
 
 import (
-	"context"
+	"errors"
 	"math"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joshua-takyi/todo/connection"
-	"github.com/joshua-takyi/todo/model"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // GetTask retrieves tasks with pagination support
 // Query parameters:
-// - page: current page number (default: 1)
+// - cursor: a token from the next or prev link of an earlier response; the page starts right next to it
+// - page: current page number (default: 1), used while no cursor is given
 // - limit: number of tasks per page (default: 10)
-func GetTask(ctx *gin.Context) {
+// - due_before / due_after: only tasks due before / after a time or YYYY-MM-DD date
+// - overdue: true for open tasks past their due date, false for the rest
+// - project_id: only tasks in this project, or "none" for tasks outside any project
+// - status: only tasks in this workflow status (todo, in_progress, in_review, done, cancelled)
+// - tags (with tags_match=any|all), priority: only tasks with these tags / priorities, comma separated
+// - completed: true for completed tasks, false for open ones
+// - created_before / created_after / updated_before / updated_after: like due_before / due_after
+// - title: only tasks whose title contains this text, ignoring case
+// - field.<key>, field.<key>.<op>: only tasks whose custom field value matches, see parseFieldQuery
+// - sort: keys to order by, like -priority,due_at or field.<key>, see parseSort (default: -created_at)
+// - tz: IANA time zone used to show dates and read date-only filters (default: UTC)
+// Pages read through a cursor don't skip over earlier tasks, so they stay fast on big lists and
+// tasks added meanwhile don't push a task onto two pages; a cursor only works with the sort it came from
+func (h *Handler) GetTask(ctx *gin.Context) {
 	start := time.Now()
 
 	// Parse pagination parameters
@@ -35,73 +47,119 @@ func GetTask(ctx *gin.Context) {
 		limit = 10 // Default limit with a reasonable maximum
 	}
 
-	// Calculate skip value for pagination
-	skip := (page - 1) * limit
+	// Work out which tasks this request may reach (normally only the caller's own)
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return
+	}
 
-	// Prepare options for MongoDB query
-	findOptions := options.Find()
-	findOptions.SetLimit(int64(limit))
-	findOptions.SetSkip(int64(skip))
-	findOptions.SetSort(bson.M{"createdAt": -1}) // Sort by creation date, newest first
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+	filter, ok := parseTaskFilter(ctx, loc)
+	if !ok {
+		return
+	}
 
-	// Initialize an empty slice to store the tasks
-	var tasks []primitive.M
-	collection := connection.Client.Database("Go").Collection("tasks")
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
 
-	// Count total documents for pagination info
-	total, err := collection.CountDocuments(context.Background(), bson.M{})
-	if err != nil {
-		ctx.JSON(500, gin.H{"error": "Failed to count tasks: " + err.Error()})
+	sort, ok := h.parseFieldQuery(ctx, dbCtx, scope, &filter)
+	if !ok {
 		return
 	}
+	keys := sort.OrDefault()
+
+	opts := store.ListOptions{Page: page, Limit: limit, Filter: filter, Sort: sort}
+	if raw := ctx.Query("cursor"); raw != "" {
+		from, ok := h.readCursor(ctx, raw, keys)
+		if !ok {
+			return
+		}
+		opts.From = &from
+		opts.Limit = limit + 1 // The extra task tells whether the list goes on past this page
+	}
 
-	// Execute the find query with options
-	cursor, err := collection.Find(context.Background(), bson.M{}, findOptions)
+	// Ask the store for one page of matching tasks plus the total count
+	tasks, total, err := h.Store.List(dbCtx, scope, opts)
 	if err != nil {
 		ctx.JSON(500, gin.H{"error": "Failed to retrieve tasks: " + err.Error()})
 		return // Important: return after error response
 	}
-	defer cursor.Close(context.Background())
 
-	// Iterate through the cursor to decode documents
-	for cursor.Next(context.Background()) {
-		var task primitive.M
-		if err := cursor.Decode(&task); err != nil {
-			ctx.JSON(500, gin.H{"error": "Failed to decode task: " + err.Error()})
+	// Work out which way the list goes on; a page read from a cursor can always go back the way it came
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	hasNext, hasPrev := page < totalPages, page > 1
+	if from := opts.From; from != nil {
+		more := len(tasks) > limit
+		if more && from.Before {
+			tasks = tasks[len(tasks)-limit:]
+		} else if more {
+			tasks = tasks[:limit]
+		}
+		hasNext, hasPrev = more || from.Before, more || !from.Before
+	}
+
+	// The links start next to the first and last task; an empty page turns around where it stands
+	var next, prev store.Position
+	switch {
+	case len(tasks) > 0:
+		next, prev = store.PositionOf(keys, tasks[len(tasks)-1]), store.PositionOf(keys, tasks[0])
+	case opts.From != nil:
+		next, prev = *opts.From, *opts.From
+	default:
+		hasNext, hasPrev = false, false
+	}
+	prev.Before, next.Before = true, false
+	var nextLink, prevLink interface{} // null in the response when the list ends that way
+	if hasNext {
+		if nextLink, err = h.pageLink(ctx, keys, next); err != nil {
+			ctx.JSON(500, gin.H{"error": "Failed to create cursor: " + err.Error()})
+			return
+		}
+	}
+	if hasPrev {
+		if prevLink, err = h.pageLink(ctx, keys, prev); err != nil {
+			ctx.JSON(500, gin.H{"error": "Failed to create cursor: " + err.Error()})
 			return
 		}
-		tasks = append(tasks, task)
 	}
 
-	// Check for cursor iteration errors
-	if err := cursor.Err(); err != nil {
-		ctx.JSON(500, gin.H{"error": "Cursor error: " + err.Error()})
+	// Work out the computed fields (blocked, overdue) and show dates in the request's zone
+	tasks, err = h.presentTasks(dbCtx, scope, tasks, loc)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": "Failed to retrieve tasks: " + err.Error()})
 		return
 	}
 
-	// Calculate pagination metadata
-	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	// Calculate pagination metadata; page numbers only mean something without a cursor
+	pagination := gin.H{
+		"total":   total,
+		"limit":   limit,
+		"hasMore": hasNext,
+		"next":    nextLink,
+		"prev":    prevLink,
+	}
+	if opts.From == nil {
+		pagination["page"] = page
+		pagination["totalPages"] = totalPages
+	}
 
 	// Calculate execution time
 	ended := time.Since(start).Seconds()
 
 	// Return the paginated results with metadata
 	ctx.JSON(200, gin.H{
-		"message":  "Tasks retrieved successfully",
-		"duration": ended,
-		"tasks":    tasks,
-		"pagination": gin.H{
-			"total":      total,
-			"page":       page,
-			"limit":      limit,
-			"totalPages": totalPages,
-			"hasMore":    page < totalPages,
-		},
+		"message":    "Tasks retrieved successfully",
+		"duration":   ended,
+		"tasks":      tasks,
+		"pagination": pagination,
 	})
 }
 
 // GetById retrieves a specific task by its ID
-func GetById(ctx *gin.Context) {
+func (h *Handler) GetById(ctx *gin.Context) {
 	id := ctx.Param("id")
 	parsedId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -109,19 +167,55 @@ func GetById(ctx *gin.Context) {
 		return
 	}
 
-	filter := bson.M{"_id": parsedId}
-	collection := connection.Client.Database("Go").Collection("tasks")
+	// Work out which tasks this request may reach (normally only the caller's own)
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return
+	}
 
-	// Attempt to find a single task in the "tasks" collection that matches the provided filter.
-	var task model.Task
-	err = collection.FindOne(context.Background(), filter).Decode(&task)
-	if err != nil {
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Attempt to find the task with the provided ID
+	task, err := h.Store.Get(dbCtx, scope, parsedId)
+	if errors.Is(err, store.ErrNotFound) {
 		ctx.JSON(404, gin.H{"error": "Task not found: " + err.Error()})
 		return // Important: return after error response
 	}
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": "Failed to retrieve task: " + err.Error()})
+		return
+	}
+
+	// Roll up how far along the direct subtasks are
+	children, err := h.Store.ListChildren(dbCtx, scope, task.ID)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": "Failed to retrieve subtasks: " + err.Error()})
+		return
+	}
+
+	task, err = h.presentTask(dbCtx, scope, task, loc)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": "Failed to retrieve task: " + err.Error()})
+		return
+	}
+
+	// Total of the time tracked on the task, running timers excluded
+	tracked, err := h.taskTime(dbCtx, scope, task.ID)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": "Failed to retrieve tracked time: " + err.Error()})
+		return
+	}
 
 	ctx.JSON(200, gin.H{
-		"message": "Task retrieved successfully",
-		"task":    task,
+		"message":  "Task retrieved successfully",
+		"task":     task,
+		"progress": progress(children),
+		"time":     tracked,
 	})
 }
@@ -0,0 +1,64 @@
+package task
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/blob"
+	"github.com/joshua-takyi/todo/cursor"
+	"github.com/joshua-takyi/todo/store"
+	"github.com/joshua-takyi/todo/workflow"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Handler groups the task HTTP handlers together with the store they use
+// The store is injected (passed in) instead of read from a global, so the
+// same handlers can run against MongoDB or any other TaskStore
+type Handler struct {
+	Store        store.TaskStore
+	Projects     store.ProjectStore     // Checks the project a task is put into
+	Comments     store.CommentStore     // The discussion thread of each task
+	TimeEntries  store.TimeEntryStore   // The time tracked on each task
+	CustomFields store.CustomFieldStore // The definitions custom field values are checked against
+	Workflow     workflow.Workflow      // The status transitions tasks may go through
+	Blobs        blob.Store             // Where uploaded attachments are kept
+	Cursors      *cursor.Signer         // Signs the cursors task lists are paged with
+	// Scope decides which tasks a request may reach
+	// Regular routes only see the caller's tasks, admin routes see everyone's
+	Scope func(ctx *gin.Context) (store.Scope, bool)
+}
+
+// NewHandler creates a Handler whose requests only reach the caller's own tasks
+func NewHandler(stores store.Stores, flow workflow.Workflow, blobs blob.Store, cursors *cursor.Signer) *Handler {
+	return &Handler{
+		Store: stores.Tasks, Projects: stores.Projects, Comments: stores.Comments,
+		TimeEntries: stores.TimeEntries, CustomFields: stores.CustomFields, Workflow: flow, Blobs: blobs,
+		Cursors: cursors, Scope: auth.OwnerScope,
+	}
+}
+
+// NewAdminHandler creates a Handler whose requests reach every user's tasks
+// Mount it only behind a role check for admins
+func NewAdminHandler(stores store.Stores, flow workflow.Workflow, blobs blob.Store, cursors *cursor.Signer) *Handler {
+	return &Handler{
+		Store: stores.Tasks, Projects: stores.Projects, Comments: stores.Comments,
+		TimeEntries: stores.TimeEntries, CustomFields: stores.CustomFields, Workflow: flow, Blobs: blobs,
+		Cursors: cursors, Scope: adminScope,
+	}
+}
+
+// adminScope reaches every task, or one user's tasks when ?owner_id= is given
+func adminScope(ctx *gin.Context) (store.Scope, bool) {
+	ownerParam := ctx.Query("owner_id")
+	if ownerParam == "" {
+		return store.AnyOwner(), true
+	}
+
+	ownerID, err := primitive.ObjectIDFromHex(ownerParam)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid owner_id format"})
+		return store.Scope{}, false
+	}
+	return store.OwnedBy(ownerID), true
+}
@@ -0,0 +1,386 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/blob"
+	"github.com/joshua-takyi/todo/cursor"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"github.com/joshua-takyi/todo/workflow"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testAPI serves the task handlers on in-memory stores, behind the real auth middleware
+type testAPI struct {
+	t      *testing.T
+	engine *gin.Engine
+	stores store.Stores
+	blobs  blob.Store
+	tokens *auth.TokenIssuer
+	token  string
+}
+
+// newTestAPI wires the handlers to the memory backend, so no database is needed
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	stores := store.OpenMemory()
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_SECRET", "test-secret")
+	tokens, err := auth.NewTokenIssuerFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(stores, workflow.Default(), blobs, cursor.New([]byte("test-cursor-secret")))
+	engine := gin.New()
+	protected := engine.Group("", auth.Middleware(tokens, stores.Users, stores.APIKeys))
+	protected.POST("/tasks", h.CreateTask)
+	protected.GET("/tasks", h.GetTask)
+	protected.GET("/tasks/:id", h.GetById)
+	protected.PATCH("/tasks/:id", h.PatchTask)
+	protected.DELETE("/tasks/:id", h.DeleteTask)
+	protected.PATCH("/tasks/:id/complete", h.MarkAsComplete)
+	protected.POST("/tasks/:id/attachments", h.UploadAttachment)
+	protected.GET("/tasks/:id/attachments", h.ListAttachments)
+	protected.GET("/tasks/:id/attachments/:attachment_id", h.DownloadAttachment)
+	protected.GET("/tasks/:id/attachments/:attachment_id/thumbnail", h.DownloadThumbnail)
+	protected.DELETE("/tasks/:id/attachments/:attachment_id", h.DeleteAttachment)
+	protected.GET("/board", h.GetBoard)
+	protected.POST("/tasks/:id/move", h.MoveOnBoard)
+	protected.GET("/workflow", h.GetWorkflow)
+	protected.GET("/tasks/:id/transitions", h.ListTransitions)
+	protected.POST("/tasks/:id/transitions", h.Transition)
+	protected.GET("/tasks/:id/children", h.Children)
+	protected.GET("/tasks/:id/ancestors", h.Ancestors)
+	protected.GET("/tasks/:id/dependencies", h.ListDependencies)
+	protected.POST("/tasks/:id/dependencies", h.AddDependency)
+	protected.DELETE("/tasks/:id/dependencies/:blocker_id", h.RemoveDependency)
+	protected.POST("/tasks/:id/checklist", h.AddChecklistItem)
+	protected.POST("/tasks/:id/checklist/reorder", h.ReorderChecklist)
+	protected.GET("/tasks/:id/comments", h.ListComments)
+	protected.POST("/tasks/:id/comments", h.AddComment)
+	protected.PATCH("/tasks/:id/comments/:comment_id", h.EditComment)
+	protected.DELETE("/tasks/:id/comments/:comment_id", h.DeleteComment)
+	protected.GET("/tasks/:id/comments/:comment_id/history", h.CommentHistory)
+	protected.POST("/tasks/:id/timer/start", h.StartTimer)
+	protected.POST("/timer/stop", h.StopTimer)
+	protected.GET("/timer", h.CurrentTimer)
+	protected.POST("/tasks/:id/time", h.LogTime)
+	protected.GET("/tasks/:id/time", h.ListTimeEntries)
+	protected.DELETE("/tasks/:id/time/:entry_id", h.DeleteTimeEntry)
+	protected.GET("/time/report", h.TimeReport)
+	protected.GET("/estimates", h.GetEstimates)
+
+	api := &testAPI{t: t, engine: engine, stores: stores, blobs: blobs, tokens: tokens}
+	api.token = api.newUser("test")
+	return api
+}
+
+// newUser creates a member account and returns a token for it
+func (api *testAPI) newUser(name string) string {
+	api.t.Helper()
+	user := model.User{ID: primitive.NewObjectID(), Name: name, Email: name + "@example.com", Role: model.RoleMember}
+	if err := api.stores.Users.CreateUser(context.Background(), &user); err != nil {
+		api.t.Fatal(err)
+	}
+	token, _, err := api.tokens.Issue(user)
+	if err != nil {
+		api.t.Fatal(err)
+	}
+	return token
+}
+
+// do sends a request with the test user's token and decodes the JSON response, if any
+func (api *testAPI) do(method, path string, body interface{}) (int, map[string]interface{}) {
+	api.t.Helper()
+	return api.doAs(api.token, method, path, body)
+}
+
+// doAs sends a request with another user's token
+func (api *testAPI) doAs(token, method, path string, body interface{}) (int, map[string]interface{}) {
+	api.t.Helper()
+	var reader *bytes.Reader
+	switch body := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(body))
+	default:
+		raw, err := json.Marshal(body)
+		if err != nil {
+			api.t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	api.engine.ServeHTTP(rec, req)
+
+	var decoded map[string]interface{}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+			api.t.Fatalf("%s %s: response is not JSON: %s", method, path, rec.Body.String())
+		}
+	}
+	return rec.Code, decoded
+}
+
+// create adds a task with the required fields and returns its ID
+func (api *testAPI) create(title string) string {
+	api.t.Helper()
+	code, body := api.do(http.MethodPost, "/tasks", gin.H{
+		"title": title, "description": "d", "priority": "medium", "tags": []string{"test"},
+	})
+	if code != http.StatusCreated {
+		api.t.Fatalf("create %q: got %d %v", title, code, body)
+	}
+	return body["id"].(string)
+}
+
+func TestCreateTask(t *testing.T) {
+	valid := func() gin.H {
+		return gin.H{"title": "Write tests", "description": "d", "priority": "high", "tags": []string{"a"}}
+	}
+	with := func(pairs ...interface{}) gin.H {
+		body := valid()
+		for i := 0; i < len(pairs); i += 2 {
+			body[pairs[i].(string)] = pairs[i+1]
+		}
+		return body
+	}
+
+	tests := []struct {
+		name string
+		body interface{}
+		want int
+	}{
+		{"valid", valid(), http.StatusCreated},
+		{"malformed JSON", `{"title":`, http.StatusBadRequest},
+		{"missing title", with("title", ""), http.StatusBadRequest},
+		{"missing tags", with("tags", []string{}), http.StatusBadRequest},
+		{"unknown priority", with("priority", "urgent"), http.StatusBadRequest},
+		{"unknown status", with("status", "someday"), http.StatusBadRequest},
+		{"starts finished", with("status", "done"), http.StatusBadRequest},
+		{"due before start", with("start_at", "2026-05-02T00:00:00Z", "due_at", "2026-05-01T00:00:00Z"), http.StatusBadRequest},
+	}
+
+	api := newTestAPI(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := api.do(http.MethodPost, "/tasks", tt.body)
+			if code != tt.want {
+				t.Fatalf("got %d %v, want %d", code, body, tt.want)
+			}
+		})
+	}
+
+	code, body := api.do(http.MethodPost, "/tasks", valid())
+	if code != http.StatusCreated {
+		t.Fatalf("got %d %v", code, body)
+	}
+	task := body["task"].(map[string]interface{})
+	if task["status"] != string(model.StatusTodo) || task["completed"] != false || task["owner_id"] == "" {
+		t.Errorf("unexpected defaults: %v", task)
+	}
+}
+
+func TestGetTaskList(t *testing.T) {
+	api := newTestAPI(t)
+	for _, title := range []string{"one", "two", "three"} {
+		api.create(title)
+	}
+
+	code, body := api.do(http.MethodGet, "/tasks?limit=2", nil)
+	if code != http.StatusOK {
+		t.Fatalf("got %d %v", code, body)
+	}
+	if got := titles(body); len(got) != 2 || got[0] != "three" || got[1] != "two" {
+		t.Errorf("first page = %v, want newest first [three two]", got)
+	}
+	pagination := body["pagination"].(map[string]interface{})
+	if pagination["total"] != float64(3) || pagination["hasMore"] != true || pagination["prev"] != nil {
+		t.Errorf("unexpected pagination: %v", pagination)
+	}
+
+	code, body = api.do(http.MethodGet, pagination["next"].(string), nil)
+	if code != http.StatusOK {
+		t.Fatalf("next page: got %d %v", code, body)
+	}
+	if got := titles(body); len(got) != 1 || got[0] != "one" {
+		t.Errorf("next page = %v, want [one]", got)
+	}
+
+	for _, query := range []string{
+		"sort=bogus", "priority=urgent", "due_before=yesterday", "cursor=garbage",
+		"tags_match=some", "completed=maybe", "status=started", "title=" + strings.Repeat("x", 101),
+	} {
+		if code, body := api.do(http.MethodGet, "/tasks?"+query, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %v, want 400", query, code, body)
+		}
+	}
+}
+
+func TestGetById(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("find me")
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"existing", "/tasks/" + id, http.StatusOK},
+		{"unknown", "/tasks/" + primitive.NewObjectID().Hex(), http.StatusNotFound},
+		{"malformed ID", "/tasks/not-an-id", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := api.do(http.MethodGet, tt.path, nil)
+			if code != tt.want {
+				t.Fatalf("got %d %v, want %d", code, body, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("before")
+
+	tests := []struct {
+		name string
+		path string
+		body interface{}
+		want int
+	}{
+		{"title", "/tasks/" + id, gin.H{"title": "after"}, http.StatusOK},
+		{"empty payload", "/tasks/" + id, gin.H{}, http.StatusBadRequest},
+		{"protected field", "/tasks/" + id, gin.H{"owner_id": primitive.NewObjectID().Hex()}, http.StatusBadRequest},
+		{"status", "/tasks/" + id, gin.H{"status": "done"}, http.StatusBadRequest},
+		{"unknown field", "/tasks/" + id, gin.H{"colour": "red"}, http.StatusBadRequest},
+		{"unknown priority", "/tasks/" + id, gin.H{"priority": "urgent"}, http.StatusBadRequest},
+		{"completed not a boolean", "/tasks/" + id, gin.H{"completed": "yes"}, http.StatusBadRequest},
+		{"unknown task", "/tasks/" + primitive.NewObjectID().Hex(), gin.H{"title": "x"}, http.StatusNotFound},
+		{"malformed ID", "/tasks/not-an-id", gin.H{"title": "x"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := api.do(http.MethodPatch, tt.path, tt.body)
+			if code != tt.want {
+				t.Fatalf("got %d %v, want %d", code, body, tt.want)
+			}
+		})
+	}
+
+	_, body := api.do(http.MethodGet, "/tasks/"+id, nil)
+	if task := body["task"].(map[string]interface{}); task["title"] != "after" || task["priority"] != "medium" {
+		t.Errorf("only the title should have changed: %v", task)
+	}
+}
+
+func TestPatchCompletedChecksBlockers(t *testing.T) {
+	api := newTestAPI(t)
+	blocker, blocked := api.create("blocker"), api.create("blocked")
+	if code, body := api.do(http.MethodPost, "/tasks/"+blocked+"/dependencies", gin.H{"blocker_id": blocker}); code != http.StatusCreated {
+		t.Fatalf("add dependency: got %d %v", code, body)
+	}
+
+	if code, body := api.do(http.MethodPatch, "/tasks/"+blocked, gin.H{"completed": true}); code != http.StatusConflict {
+		t.Fatalf("completing a blocked task: got %d %v, want 409", code, body)
+	}
+	code, body := api.do(http.MethodPatch, "/tasks/"+blocked+"?force=true", gin.H{"completed": true})
+	if code != http.StatusOK || body["task"].(map[string]interface{})["status"] != string(model.StatusDone) {
+		t.Fatalf("forced: got %d %v", code, body)
+	}
+}
+
+func TestDeleteTask(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("doomed")
+
+	if code, body := api.do(http.MethodDelete, "/tasks/not-an-id", nil); code != http.StatusBadRequest {
+		t.Errorf("malformed ID: got %d %v, want 400", code, body)
+	}
+	if code, body := api.do(http.MethodDelete, "/tasks/"+id+"?mode=shred", nil); code != http.StatusBadRequest {
+		t.Errorf("unknown mode: got %d %v, want 400", code, body)
+	}
+	if code, body := api.do(http.MethodDelete, "/tasks/"+id, nil); code != http.StatusNoContent {
+		t.Fatalf("delete: got %d %v, want 204", code, body)
+	}
+	if code, body := api.do(http.MethodGet, "/tasks/"+id, nil); code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d %v, want 404", code, body)
+	}
+	if code, body := api.do(http.MethodDelete, "/tasks/"+id, nil); code != http.StatusNotFound {
+		t.Errorf("second delete: got %d %v, want 404", code, body)
+	}
+}
+
+func TestMarkAsCompleteToggles(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("toggle")
+
+	for _, want := range []bool{true, false, true} {
+		code, body := api.do(http.MethodPatch, "/tasks/"+id+"/complete", nil)
+		if code != http.StatusOK || body["completed"] != want {
+			t.Fatalf("toggle: got %d %v, want completed=%v", code, body, want)
+		}
+	}
+
+	if code, body := api.do(http.MethodPatch, "/tasks/"+primitive.NewObjectID().Hex()+"/complete", nil); code != http.StatusNotFound {
+		t.Errorf("unknown task: got %d %v, want 404", code, body)
+	}
+	if code, body := api.do(http.MethodPatch, "/tasks/not-an-id/complete", nil); code != http.StatusBadRequest {
+		t.Errorf("malformed ID: got %d %v, want 400", code, body)
+	}
+}
+
+func TestCompletingRecurringTaskSpawnsNext(t *testing.T) {
+	api := newTestAPI(t)
+	code, body := api.do(http.MethodPost, "/tasks", gin.H{
+		"title": "water plants", "description": "d", "priority": "low", "tags": []string{"home"},
+		"due_at": "2026-10-19T09:00:00Z", "recurrence": gin.H{"rule": "FREQ=WEEKLY"},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("create: got %d %v", code, body)
+	}
+
+	code, body = api.do(http.MethodPatch, "/tasks/"+body["id"].(string), gin.H{"completed": true})
+	if code != http.StatusOK {
+		t.Fatalf("complete: got %d %v", code, body)
+	}
+	next, ok := body["next_task"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("no next_task in %v", body)
+	}
+	due, err := time.Parse(time.RFC3339, next["due_at"].(string))
+	if err != nil || !due.Equal(time.Date(2026, 10, 26, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("next due_at = %v, want a week later", next["due_at"])
+	}
+}
+
+// titles lists the titles of the tasks in a list response, in order
+func titles(body map[string]interface{}) []string {
+	var out []string
+	for _, task := range body["tasks"].([]interface{}) {
+		out = append(out, task.(map[string]interface{})["title"].(string))
+	}
+	return out
+}
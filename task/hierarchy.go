@@ -0,0 +1,208 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxDepth is the number of levels a task tree may have, counting the top-level task
+// A limit keeps ancestor walks and cascading deletes cheap and the UI readable
+const maxDepth = 5
+
+// ancestors returns the path from the top-level task down to the task's parent
+// A parent that no longer exists (deleted concurrently) simply ends the path
+func (h *Handler) ancestors(ctx context.Context, scope store.Scope, task model.Task) ([]model.Task, error) {
+	path := []model.Task{}
+	seen := map[primitive.ObjectID]bool{task.ID: true}
+
+	for parentID := task.ParentID; parentID != nil; {
+		// The seen set guards against a cycle in damaged data looping forever
+		if seen[*parentID] {
+			break
+		}
+		seen[*parentID] = true
+
+		parent, err := h.Store.Get(ctx, scope, *parentID)
+		if errors.Is(err, store.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		path = append([]model.Task{parent}, path...) // Prepend so the top-level task comes first
+		parentID = parent.ParentID
+	}
+	return path, nil
+}
+
+// subtreeHeight counts the levels of a task's tree, the task itself included
+// A task without subtasks has height 1
+func (h *Handler) subtreeHeight(ctx context.Context, scope store.Scope, id primitive.ObjectID) (int, error) {
+	height := 0
+	level := []primitive.ObjectID{id}
+	for len(level) > 0 && height <= maxDepth {
+		height++
+
+		var next []primitive.ObjectID
+		for _, parentID := range level {
+			children, err := h.Store.ListChildren(ctx, scope, parentID)
+			if err != nil {
+				return 0, err
+			}
+			for _, child := range children {
+				next = append(next, child.ID)
+			}
+		}
+		level = next
+	}
+	return height, nil
+}
+
+// checkParent makes sure task id (with a tree of the given height) may be placed under parentID
+// - the parent must be one of the caller's tasks
+// - the task can't become its own ancestor
+// - the resulting tree can't be deeper than maxDepth
+func (h *Handler) checkParent(ctx context.Context, scope store.Scope, id, parentID primitive.ObjectID, height int) *helpers.Error {
+	if parentID == id {
+		return &helpers.Error{Message: "A task cannot be its own parent", Status: http.StatusBadRequest}
+	}
+
+	parent, err := h.Store.Get(ctx, scope, parentID)
+	if errors.Is(err, store.ErrNotFound) {
+		return &helpers.Error{Message: "Parent task not found", Status: http.StatusBadRequest}
+	}
+	if err != nil {
+		return &helpers.Error{Message: "Failed to load parent task: " + err.Error(), Status: http.StatusInternalServerError}
+	}
+
+	path, err := h.ancestors(ctx, scope, parent)
+	if err != nil {
+		return &helpers.Error{Message: "Failed to load parent task: " + err.Error(), Status: http.StatusInternalServerError}
+	}
+	for _, ancestor := range path {
+		if ancestor.ID == id {
+			return &helpers.Error{Message: "A task cannot be moved under one of its own subtasks", Status: http.StatusBadRequest}
+		}
+	}
+
+	// Levels above the parent + the parent + the moved tree
+	if len(path)+1+height > maxDepth {
+		return &helpers.Error{
+			Message: fmt.Sprintf("Tasks can be nested at most %d levels deep", maxDepth),
+			Status:  http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+// progress summarizes how many of the direct subtasks are done
+func progress(children []model.Task) gin.H {
+	completed := 0
+	for _, child := range children {
+		if child.Completed {
+			completed++
+		}
+	}
+
+	percent := 0
+	if len(children) > 0 {
+		percent = completed * 100 / len(children)
+	}
+	return gin.H{"completed": completed, "total": len(children), "percent": percent}
+}
+
+// Children lists the direct subtasks of a task
+func (h *Handler) Children(ctx *gin.Context) {
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	children, err := h.Store.ListChildren(dbCtx, scope, task.ID)
+	if err == nil {
+		children, err = h.presentTasks(dbCtx, scope, children, loc)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve subtasks: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Subtasks retrieved successfully",
+		"task_id":  task.ID,
+		"children": children,
+		"progress": progress(children),
+	})
+}
+
+// Ancestors returns the path from the top-level task down to the task's parent
+func (h *Handler) Ancestors(ctx *gin.Context) {
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	path, err := h.ancestors(dbCtx, scope, task)
+	if err == nil {
+		path, err = h.presentTasks(dbCtx, scope, path, loc)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ancestors: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Ancestors retrieved successfully",
+		"task_id":   task.ID,
+		"ancestors": path,
+	})
+}
+
+// loadTask reads the :id parameter, the scope and ?tz=, then loads the task
+// It writes the error response itself and returns false when the request must stop
+func (h *Handler) loadTask(ctx *gin.Context) (model.Task, store.Scope, *time.Location, bool) {
+	id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return model.Task{}, store.Scope{}, nil, false
+	}
+
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return model.Task{}, store.Scope{}, nil, false
+	}
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return model.Task{}, store.Scope{}, nil, false
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	task, err := h.Store.Get(dbCtx, scope, id)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return model.Task{}, store.Scope{}, nil, false
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve task: " + err.Error()})
+		return model.Task{}, store.Scope{}, nil, false
+	}
+	return task, scope, loc, true
+}
@@ -0,0 +1,124 @@
+package task
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createUnder adds a subtask of parentID and returns its ID
+func (api *testAPI) createUnder(title, parentID string) string {
+	api.t.Helper()
+	code, body := api.do(http.MethodPost, "/tasks", gin.H{
+		"title": title, "description": "d", "priority": "medium", "tags": []string{"test"}, "parent_id": parentID,
+	})
+	if code != http.StatusCreated {
+		api.t.Fatalf("create %q under %s: got %d %v", title, parentID, code, body)
+	}
+	return body["id"].(string)
+}
+
+// chain creates n tasks, each a subtask of the one before, and returns their IDs from the top down
+func (api *testAPI) chain(n int) []string {
+	api.t.Helper()
+	ids := []string{api.create("level 1")}
+	for len(ids) < n {
+		ids = append(ids, api.createUnder("deeper", ids[len(ids)-1]))
+	}
+	return ids
+}
+
+func TestDeleteModes(t *testing.T) {
+	api := newTestAPI(t)
+	tree := api.chain(3)
+	root, child, grandchild := tree[0], tree[1], tree[2]
+
+	if code, _ := api.do(http.MethodDelete, "/tasks/"+root, nil); code != http.StatusConflict {
+		t.Errorf("delete with subtasks: got %d, want 409", code)
+	}
+	if code, _ := api.do(http.MethodDelete, "/tasks/"+root+"?mode=recursive", nil); code != http.StatusBadRequest {
+		t.Errorf("unknown mode: got %d, want 400", code)
+	}
+
+	// Orphan only detaches the direct subtasks; deeper ones stay where they are
+	if code, body := api.do(http.MethodDelete, "/tasks/"+root+"?mode=orphan", nil); code != http.StatusNoContent {
+		t.Fatalf("orphan delete: got %d %v", code, body)
+	}
+	if _, body := api.do(http.MethodGet, "/tasks/"+child, nil); body["task"].(map[string]interface{})["parent_id"] != nil {
+		t.Errorf("orphaned child still has a parent: %v", body)
+	}
+	if _, body := api.do(http.MethodGet, "/tasks/"+grandchild, nil); body["task"].(map[string]interface{})["parent_id"] != child {
+		t.Errorf("grandchild moved: %v", body)
+	}
+
+	if code, body := api.do(http.MethodDelete, "/tasks/"+child+"?mode=cascade", nil); code != http.StatusNoContent {
+		t.Fatalf("cascade delete: got %d %v", code, body)
+	}
+	if code, _ := api.do(http.MethodGet, "/tasks/"+grandchild, nil); code != http.StatusNotFound {
+		t.Errorf("grandchild after cascade: got %d, want 404", code)
+	}
+
+	// A task without subtasks is deleted in the default mode
+	leaf := api.create("leaf")
+	if code, _ := api.do(http.MethodDelete, "/tasks/"+leaf, nil); code != http.StatusNoContent {
+		t.Errorf("delete a leaf: got %d, want 204", code)
+	}
+}
+
+func TestSubtaskDepthLimit(t *testing.T) {
+	api := newTestAPI(t)
+	deepest := api.chain(maxDepth)
+
+	code, _ := api.do(http.MethodPost, "/tasks", gin.H{
+		"title": "too deep", "description": "d", "priority": "low", "tags": []string{"a"}, "parent_id": deepest[maxDepth-1],
+	})
+	if code != http.StatusBadRequest {
+		t.Errorf("subtask below level %d: got %d, want 400", maxDepth, code)
+	}
+
+	// Moving a two-level tree counts both of its levels
+	pair := api.chain(2)
+	if code, _ := api.do(http.MethodPatch, "/tasks/"+pair[0], gin.H{"parent_id": deepest[maxDepth-2]}); code != http.StatusBadRequest {
+		t.Errorf("move a tree too deep: got %d, want 400", code)
+	}
+	if code, body := api.do(http.MethodPatch, "/tasks/"+pair[0], gin.H{"parent_id": deepest[maxDepth-3]}); code != http.StatusOK {
+		t.Errorf("move a tree to the deepest level it fits: got %d %v", code, body)
+	}
+}
+
+func TestParentCycles(t *testing.T) {
+	api := newTestAPI(t)
+	tree := api.chain(3)
+
+	if code, _ := api.do(http.MethodPatch, "/tasks/"+tree[0], gin.H{"parent_id": tree[0]}); code != http.StatusBadRequest {
+		t.Errorf("own parent: got %d, want 400", code)
+	}
+	if code, _ := api.do(http.MethodPatch, "/tasks/"+tree[0], gin.H{"parent_id": tree[2]}); code != http.StatusBadRequest {
+		t.Errorf("under its own subtask: got %d, want 400", code)
+	}
+	if code, _ := api.do(http.MethodPatch, "/tasks/"+tree[1], gin.H{"parent_id": nil}); code != http.StatusOK {
+		t.Errorf("detach with a null parent: got %d, want 200", code)
+	}
+}
+
+func TestChildrenAndAncestors(t *testing.T) {
+	api := newTestAPI(t)
+	tree := api.chain(3)
+	sibling := api.createUnder("sibling", tree[0])
+	api.do(http.MethodPatch, "/tasks/"+sibling+"/complete", nil)
+
+	code, body := api.do(http.MethodGet, "/tasks/"+tree[0]+"/children", nil)
+	if code != http.StatusOK || len(body["children"].([]interface{})) != 2 {
+		t.Fatalf("children: got %d %v", code, body)
+	}
+	if got := body["progress"].(map[string]interface{}); got["completed"] != float64(1) || got["percent"] != float64(50) {
+		t.Errorf("progress = %v", got)
+	}
+
+	_, body = api.do(http.MethodGet, "/tasks/"+tree[2]+"/ancestors", nil)
+	path := body["ancestors"].([]interface{})
+	if len(path) != 2 || path[0].(map[string]interface{})["id"] != tree[0] || path[1].(map[string]interface{})["id"] != tree[1] {
+		t.Errorf("ancestors = %v, want the root then the child", path)
+	}
+}
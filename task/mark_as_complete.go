@@ -1,74 +1,58 @@
 package task
 
 import (
-	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joshua-takyi/todo/connection"
+	"github.com/joshua-takyi/todo/helpers"
 	"github.com/joshua-takyi/todo/model"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/joshua-takyi/todo/store"
 )
 
-func MarkAsComplete(ctx *gin.Context) {
-	// Get task ID from URL parameter
-	id := ctx.Param("id")
-	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+// MarkAsComplete toggles a task between complete and incomplete
+// It is a shortcut for the status workflow: an open task moves to done,
+// a finished (done or cancelled) one moves back to todo
+// Query parameters:
+// - force: true to complete the task even though some of its blockers are still open
+func (h *Handler) MarkAsComplete(ctx *gin.Context) {
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
 		return
 	}
 
-	// Convert string ID to MongoDB ObjectID
-	parsedId, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
-		return
-	}
-
-	// Initialize database collection
-	collection := connection.Client.Database("Go").Collection("tasks")
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
 
-	// First, find the current task to check its completion status
-	var task model.Task
-	filter := bson.M{"_id": parsedId}
-	err = collection.FindOne(context.Background(), filter).Decode(&task)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error":   "Task not found",
-			"details": err.Error(),
-		})
-		return
+	to := model.StatusDone
+	if task.Completed {
+		to = model.StatusTodo
 	}
 
-	// Toggle the completion status (true becomes false, false becomes true)
-	newCompletionStatus := !task.Completed
-
-	// Prepare update operation with the new status
-	update := bson.M{"$set": bson.M{"completed": newCompletionStatus}}
-
-	// Update the task with the new completion status
-	result := collection.FindOneAndUpdate(context.Background(), filter, update)
-	if err := result.Err(); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to update task completion status",
-			"details": err.Error(),
-		})
+	// Completing an occurrence of a recurring task creates the next one
+	task, next, ok := h.moveTask(ctx, dbCtx, scope, task, to, store.TaskUpdate{})
+	if !ok {
 		return
 	}
 
 	// Prepare response message based on the new status
 	var message string
-	if newCompletionStatus {
+	if task.Completed {
 		message = "Task marked as complete"
 	} else {
 		message = "Task marked as incomplete"
 	}
 
-	// Return success response with appropriate message
-	ctx.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"message":   message,
-		"task_id":   id,
-		"completed": newCompletionStatus,
-	})
+		"task_id":   task.ID.Hex(),
+		"completed": task.Completed,
+		"status":    task.Status,
+	}
+	if next != nil {
+		response["next_task"] = present(*next, loc, time.Now())
+	}
+
+	// Return success response with appropriate message
+	ctx.JSON(http.StatusOK, response)
 }
@@ -1,20 +1,23 @@
 package task
 
 import (
-	"context"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joshua-takyi/todo/connection"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // PatchTask handles partial updates to a task document identified by ID
 // It validates the input, processes the update, and returns appropriate responses
-func PatchTask(ctx *gin.Context) {
+func (h *Handler) PatchTask(ctx *gin.Context) {
 	// Extract and validate the task ID from URL parameters
 	paramsId := ctx.Param("id")
 	id, err := primitive.ObjectIDFromHex(paramsId)
@@ -46,7 +49,7 @@ func PatchTask(ctx *gin.Context) {
 	}
 
 	// Validate that protected fields are not being modified
-	protectedFields := []string{"id", "_id", "created_at"}
+	protectedFields := []string{"id", "_id", "created_at", "owner_id", "status_entered_at"}
 	for _, field := range protectedFields {
 		if _, exists := updateFields[field]; exists {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -57,6 +60,15 @@ func PatchTask(ctx *gin.Context) {
 		}
 	}
 
+	// Statuses only change through the workflow, which checks every move
+	if _, exists := updateFields["status"]; exists {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Protected field modification attempt",
+			"details": "Use POST /api/v1/tasks/:id/transitions to change the status",
+		})
+		return
+	}
+
 	// Type-specific validation for common fields
 	if completed, exists := updateFields["completed"]; exists {
 		// Ensure completed is a boolean if present
@@ -69,53 +81,195 @@ func PatchTask(ctx *gin.Context) {
 		}
 	}
 
-	// Add update timestamp
-	updateFields["updated_at"] = time.Now()
+	// Convert the loose map into a typed TaskUpdate
+	// Re-encoding to JSON and decoding with DisallowUnknownFields rejects
+	// fields the task doesn't have and values of the wrong type
+	update, err := decodeTaskUpdate(updateFields)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid update payload",
+			"details": err.Error(),
+		})
+		return
+	}
 
-	// Prepare the MongoDB filter and update operation
-	filter := bson.M{"_id": id}
-	update := bson.M{
-		"$set": updateFields,
+	// Work out which tasks this request may reach (normally only the caller's own)
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return
+	}
+
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
 	}
 
 	// Create a context with timeout for database operations
-	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	dbCtx, cancel := helpers.DBContext(ctx)
 	defer cancel() // Ensure resources are freed
 
-	// Get database collection
-	collection := connection.Client.Database("Go").Collection("tasks")
+	// Some checks below need the task as it is before the update
+	var current model.Task
+	if update.StartAt.Set || update.DueAt.Set || update.Recurrence.Set || update.Completed != nil || update.Fields != nil {
+		current, err = h.Store.Get(dbCtx, scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":   "Task not found",
+				"details": fmt.Sprintf("No task exists with ID: %s", paramsId),
+			})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database operation failed",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
 
-	// Execute the update operation
-	result, err := collection.UpdateOne(dbCtx, filter, update)
-	if err != nil {
-		// Handle database operation errors
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Database operation failed",
-			"details": err.Error(),
-		})
+	// completed is still accepted for older clients: true moves the task to done and false
+	// back to todo, through moveTask like any other transition, so blockers and recurrence apply
+	var moveTo *model.Status
+	if update.Completed != nil {
+		to := model.StatusTodo
+		if *update.Completed {
+			to = model.StatusDone
+		}
+		update.Completed = nil // The transition sets completed together with the status
+		if current.EffectiveStatus().IsTerminal() != to.IsTerminal() {
+			moveTo = &to
+		}
+	}
+
+	// A patch may change only one of the two dates, so check the order
+	// against the dates the task will have after the update
+	// Recurrence depends on the due date too, so it is checked the same way
+	if update.StartAt.Set || update.DueAt.Set || update.Recurrence.Set {
+		after := current
+		existing := after.Recurrence
+		update.Apply(&after)
+		if err := helpers.ValidateSchedule(after.StartAt, after.DueAt); err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+
+		// A new rule restarts the series at the task's due date; sending null stops repeating
+		if update.Recurrence.Value != nil {
+			if err := prepareRecurrence(update.Recurrence.Value, after, existing, loc); err != nil {
+				ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+				return
+			}
+		} else if after.Recurrence != nil && after.DueAt == nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Recurring tasks need a due_at, clear recurrence first"})
+			return
+		}
+	}
+
+	if update.Priority != nil {
+		if err := helpers.ValidatePriority(*update.Priority); err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Sending null clears an estimate, so only values that are sent need checking
+	if err := helpers.ValidateEstimate(update.Points.Value, update.Estimate.Value); err != nil {
+		ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Custom field values are checked against the definitions of the task's owner
+	// Only the keys that are sent change, and a required field can't be cleared
+	if update.Fields != nil {
+		fields, _, err := h.checkFieldValues(dbCtx, current.OwnerID, update.Fields)
+		if err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+		update.Fields = fields
+	}
+
+	// Moving a task moves its whole subtree, so the subtree's height counts towards the depth limit
+	// Sending "parent_id": null turns the task into a top-level task
+	if update.ParentID.Value != nil {
+		height, err := h.subtreeHeight(dbCtx, scope, id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database operation failed",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err := h.checkParent(dbCtx, scope, id, *update.ParentID.Value, height); err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Sending "project_id": null takes the task out of its project
+	if update.ProjectID.Value != nil {
+		if err := h.checkProject(dbCtx, scope, *update.ProjectID.Value); err != nil {
+			ctx.JSON(err.GetStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// A status change goes through the workflow checks and may start the next occurrence
+	if moveTo != nil {
+		moved, next, ok := h.moveTask(ctx, dbCtx, scope, current, *moveTo, update)
+		if !ok {
+			return
+		}
+		moved, err = h.presentTask(dbCtx, scope, moved, loc)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database operation failed",
+				"details": err.Error(),
+			})
+			return
+		}
+		response := gin.H{
+			"message": "Task updated successfully",
+			"task":    moved,
+		}
+		if next != nil {
+			response["next_task"] = present(*next, loc, time.Now())
+		}
+		ctx.JSON(http.StatusOK, response)
 		return
 	}
 
-	// Check if the task was found
-	if result.MatchedCount == 0 {
+	// Execute the update operation through the store
+	updatedTask, err := h.Store.Update(dbCtx, scope, id, update)
+	if errors.Is(err, store.ErrNotFound) {
+		// Check if the task was found
 		ctx.JSON(http.StatusNotFound, gin.H{
 			"error":   "Task not found",
 			"details": fmt.Sprintf("No task exists with ID: %s", paramsId),
 		})
 		return
 	}
+	if errors.Is(err, store.ErrStatusConflict) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "Task status was changed by another request, reload it and try again",
+		})
+		return
+	}
+	if err != nil {
+		// Handle database operation errors
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database operation failed",
+			"details": err.Error(),
+		})
+		return
+	}
 
-	// Fetch the updated task to return complete data
-	var updatedTask bson.M
-	err = collection.FindOne(dbCtx, filter).Decode(&updatedTask)
+	updatedTask, err = h.presentTask(dbCtx, scope, updatedTask, loc)
 	if err != nil {
-		// Log the error but still return success since the update worked
-		fmt.Printf("Warning: Update succeeded but failed to fetch updated task: %v\n", err)
-		// Return partial success response
-		ctx.JSON(http.StatusOK, gin.H{
-			"message":  "Task updated successfully",
-			"modified": updateFields,
-			"warning":  "Could not retrieve the complete updated task",
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database operation failed",
+			"details": err.Error(),
 		})
 		return
 	}
@@ -126,3 +280,21 @@ func PatchTask(ctx *gin.Context) {
 		"task":    updatedTask,
 	})
 }
+
+// decodeTaskUpdate turns the raw PATCH body into a store.TaskUpdate
+func decodeTaskUpdate(fields map[string]interface{}) (store.TaskUpdate, error) {
+	var update store.TaskUpdate
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return update, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&update); err != nil {
+		return update, err
+	}
+
+	return update, nil
+}
@@ -0,0 +1,27 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// checkProject makes sure a task may be put into the project:
+// it must be one of the caller's projects and must not be archived
+func (h *Handler) checkProject(ctx context.Context, scope store.Scope, projectID primitive.ObjectID) *helpers.Error {
+	project, err := h.Projects.GetProject(ctx, scope, projectID)
+	if errors.Is(err, store.ErrProjectNotFound) {
+		return &helpers.Error{Message: "Project not found", Status: http.StatusBadRequest}
+	}
+	if err != nil {
+		return &helpers.Error{Message: "Failed to load project: " + err.Error(), Status: http.StatusInternalServerError}
+	}
+	if project.Archived {
+		return &helpers.Error{Message: "Project is archived, unarchive it before adding tasks", Status: http.StatusBadRequest}
+	}
+	return nil
+}
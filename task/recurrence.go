@@ -0,0 +1,171 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/recurrence"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// prepareRecurrence validates the rule a client sent and fills in the fields the server owns
+// - the series starts (DTSTART) at the task's due date
+// - the zone is the one in the body, else the request's ?tz=, else UTC
+// - a task that already repeats keeps its series and the link to its next occurrence
+func prepareRecurrence(r *model.Recurrence, task model.Task, existing *model.Recurrence, loc *time.Location) *helpers.Error {
+	if task.DueAt == nil {
+		return &helpers.Error{Message: "Recurring tasks need a due_at", Status: http.StatusBadRequest}
+	}
+
+	rule, err := recurrence.Normalize(r.Rule)
+	if err != nil {
+		return &helpers.Error{Message: "Invalid recurrence rule: " + err.Error(), Status: http.StatusBadRequest}
+	}
+	r.Rule = rule
+
+	if r.TimeZone == "" {
+		r.TimeZone = loc.String()
+	}
+	if _, err := time.LoadLocation(r.TimeZone); err != nil {
+		return &helpers.Error{Message: fmt.Sprintf("Unknown time zone '%s'", r.TimeZone), Status: http.StatusBadRequest}
+	}
+
+	r.Anchor = task.DueAt.UTC()
+	r.SeriesID = task.ID
+	r.NextID = nil
+	if existing != nil {
+		r.SeriesID = existing.SeriesID
+		r.NextID = existing.NextID
+	}
+	return nil
+}
+
+// upcomingDueDates returns up to n due dates that follow the task's own due date
+func upcomingDueDates(task model.Task, n int) ([]time.Time, error) {
+	r := task.Recurrence
+	if r == nil || task.DueAt == nil {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	return recurrence.Upcoming(r.Rule, r.Anchor, loc, *task.DueAt, n)
+}
+
+// nextOccurrence builds the task that follows a completed occurrence
+// It returns nil when the series has ended (COUNT or UNTIL reached)
+// The next due date is the one after the completed task's due date, even when
+// the task was completed late, so no occurrence of the series is skipped
+func nextOccurrence(done model.Task) (*model.Task, error) {
+	dueDates, err := upcomingDueDates(done, 1)
+	if err != nil || len(dueDates) == 0 {
+		return nil, err
+	}
+	due := dueDates[0].UTC()
+
+	next := done
+	next.ID = primitive.NewObjectID()
+	next.Completed = false
+	next.Status = model.StatusTodo
+	next.StatusSince = model.StatusTimes{model.StatusTodo: time.Now()}
+	next.BlockedBy = nil   // The blockers of this occurrence were about this occurrence only
+	next.Rank = ""         // A new occurrence joins the bottom of its column like any new task
+	next.Attachments = nil // Uploaded files stay with the occurrence they were attached to
+	next.Tags = append([]string(nil), done.Tags...)
+	next.Image = append([]string(nil), done.Image...)
+	next.Checklist = freshChecklist(done.Checklist) // The same steps, all unchecked again
+	next.DueAt = &due
+	if done.StartAt != nil {
+		// Keep the same gap between start and due date as the completed occurrence
+		start := done.StartAt.Add(due.Sub(*done.DueAt))
+		next.StartAt = &start
+	}
+	next.Recurrence = &model.Recurrence{
+		Rule:     done.Recurrence.Rule,
+		TimeZone: done.Recurrence.TimeZone,
+		Anchor:   done.Recurrence.Anchor,
+		SeriesID: done.Recurrence.SeriesID,
+	}
+	next.Metadata.CreatedAt = time.Now()
+	next.Metadata.UpdatedAt = time.Now()
+	return &next, nil
+}
+
+// spawnNextOccurrence creates the next task of a series once an occurrence is completed
+// The completed task remembers the new one in next_id, so completing it again
+// (after marking it incomplete) doesn't create a second copy
+func (h *Handler) spawnNextOccurrence(ctx context.Context, scope store.Scope, done model.Task) (*model.Task, error) {
+	if !done.Completed || done.Recurrence == nil || done.Recurrence.NextID != nil {
+		return nil, nil
+	}
+
+	next, err := nextOccurrence(done)
+	if err != nil || next == nil {
+		return nil, err
+	}
+	if err := h.Store.Create(ctx, next); err != nil {
+		return nil, err
+	}
+
+	linked := *done.Recurrence
+	linked.NextID = &next.ID
+	update := store.TaskUpdate{Recurrence: store.Optional[model.Recurrence]{Set: true, Value: &linked}}
+	if _, err := h.Store.Update(ctx, scope, done.ID, update); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Occurrences previews the next due dates of a recurring task
+// Query parameters:
+// - count: how many occurrences to return (default: 5, max: 100)
+// - tz: IANA time zone used to show the dates (default: UTC)
+func (h *Handler) Occurrences(ctx *gin.Context) {
+	count, err := strconv.Atoi(ctx.DefaultQuery("count", "5"))
+	if err != nil || count < 1 || count > 100 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "count must be a number between 1 and 100"})
+		return
+	}
+
+	task, _, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+	if task.Recurrence == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Task does not repeat"})
+		return
+	}
+
+	dueDates, err := upcomingDueDates(task, count)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute occurrences: " + err.Error()})
+		return
+	}
+
+	// Each occurrence keeps the gap between start and due date of this task
+	occurrences := make([]gin.H, 0, len(dueDates))
+	for _, due := range dueDates {
+		occurrence := gin.H{"due_at": due.In(loc)}
+		if task.StartAt != nil {
+			occurrence["start_at"] = task.StartAt.Add(due.Sub(*task.DueAt)).In(loc)
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "Occurrences retrieved successfully",
+		"task_id":     task.ID,
+		"rule":        task.Recurrence.Rule,
+		"time_zone":   task.Recurrence.TimeZone,
+		"occurrences": occurrences,
+	})
+}
@@ -0,0 +1,90 @@
+package task
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecurringTaskSpawnsNextOccurrence(t *testing.T) {
+	api := newTestAPI(t)
+	due := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	code, body := api.do(http.MethodPost, "/tasks", gin.H{
+		"title": "Standup", "description": "d", "priority": "low", "tags": []string{"a"},
+		"due_at": due, "recurrence": gin.H{"rule": "freq=daily;count=2"},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("create: got %d %v", code, body)
+	}
+	first := body["id"].(string)
+
+	code, body = api.do(http.MethodPatch, "/tasks/"+first+"/complete", nil)
+	next, _ := body["next_task"].(map[string]interface{})
+	if code != http.StatusOK || next == nil {
+		t.Fatalf("complete the first occurrence: got %d %v", code, body)
+	}
+	if next["due_at"] != due.AddDate(0, 0, 1).Format(time.RFC3339) || next["completed"] != false {
+		t.Errorf("next occurrence: %v", next)
+	}
+	if rule := next["recurrence"].(map[string]interface{})["rule"]; rule != "FREQ=DAILY;COUNT=2" {
+		t.Errorf("next occurrence rule = %v", rule)
+	}
+
+	// Reopening and completing again must not create a second copy of the same occurrence
+	api.do(http.MethodPatch, "/tasks/"+first+"/complete", nil)
+	if _, body := api.do(http.MethodPatch, "/tasks/"+first+"/complete", nil); body["next_task"] != nil {
+		t.Errorf("completing twice spawned %v", body["next_task"])
+	}
+
+	// COUNT=2 ends the series with the second occurrence
+	code, body = api.do(http.MethodPatch, "/tasks/"+next["id"].(string)+"/complete", nil)
+	if code != http.StatusOK || body["next_task"] != nil {
+		t.Errorf("complete the last occurrence: got %d %v", code, body)
+	}
+
+	_, body = api.do(http.MethodGet, "/tasks", nil)
+	if total := body["pagination"].(map[string]interface{})["total"]; total != float64(2) {
+		t.Errorf("%v tasks, want the two occurrences", total)
+	}
+}
+
+func TestRecurringTaskEndsAtUntil(t *testing.T) {
+	api := newTestAPI(t)
+	due := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	_, body := api.do(http.MethodPost, "/tasks", gin.H{
+		"title": "Weekly", "description": "d", "priority": "low", "tags": []string{"a"},
+		"due_at": due, "recurrence": gin.H{"rule": "FREQ=WEEKLY;UNTIL=20260110T000000Z"},
+	})
+	code, body := api.do(http.MethodPatch, "/tasks/"+body["id"].(string)+"/complete", nil)
+	if code != http.StatusOK || body["next_task"] != nil {
+		t.Errorf("complete past UNTIL: got %d %v", code, body)
+	}
+}
+
+func TestCreateRecurringTaskValidation(t *testing.T) {
+	api := newTestAPI(t)
+	due := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		extra gin.H
+	}{
+		{"no due date", gin.H{"recurrence": gin.H{"rule": "FREQ=DAILY"}}},
+		{"invalid rule", gin.H{"due_at": due, "recurrence": gin.H{"rule": "FREQ=SOMETIMES"}}},
+		{"too frequent", gin.H{"due_at": due, "recurrence": gin.H{"rule": "FREQ=MINUTELY"}}},
+		{"unknown zone", gin.H{"due_at": due, "recurrence": gin.H{"rule": "FREQ=DAILY", "time_zone": "Mars/Olympus"}}},
+	}
+	for _, tt := range tests {
+		body := gin.H{"title": "t", "description": "d", "priority": "low", "tags": []string{"a"}}
+		for key, value := range tt.extra {
+			body[key] = value
+		}
+		if code, res := api.do(http.MethodPost, "/tasks", body); code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %v, want 400", tt.name, code, res)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package task
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dateOnly is the layout accepted for filters that only need a day, like ?due_before=2025-01-31
+const dateOnly = "2006-01-02"
+
+// requestLocation returns the time zone named by ?tz= (an IANA name like "Europe/Berlin")
+// Dates are always stored in UTC; the zone only changes how they are shown
+// and how date-only filter values are read. Without ?tz= everything is UTC
+// It writes a 400 and returns false for an unknown zone
+func requestLocation(ctx *gin.Context) (*time.Location, bool) {
+	name := ctx.Query("tz")
+	if name == "" {
+		return time.UTC, true
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown time zone '%s'", name)})
+		return nil, false
+	}
+	return loc, true
+}
+
+// parseTaskFilter reads the list filters from the query string
+// - due_before / due_after: RFC 3339 time, or a YYYY-MM-DD date meaning midnight in the request's zone
+// - overdue: true or false
+// - project_id: a project ID, or "none" for tasks outside any project
+// - status: one of the workflow statuses
+// - tags: tags separated by commas or repeated; tasks with any of them, or all with tags_match=all
+// - priority: priorities separated by commas or repeated; tasks with any of them
+// - completed: true or false
+// - created_before / created_after / updated_before / updated_after: like due_before / due_after
+// - title: text the title contains, ignoring case
+// It writes a 400 and returns false when a value can't be parsed
+func parseTaskFilter(ctx *gin.Context, loc *time.Location) (store.TaskFilter, bool) {
+	filter := store.TaskFilter{Now: time.Now()}
+
+	if !parseTimeBounds(ctx, loc,
+		timeBound{"due_before", &filter.DueBefore},
+		timeBound{"due_after", &filter.DueAfter},
+		timeBound{"created_before", &filter.CreatedBefore},
+		timeBound{"created_after", &filter.CreatedAfter},
+		timeBound{"updated_before", &filter.UpdatedBefore},
+		timeBound{"updated_after", &filter.UpdatedAfter},
+	) {
+		return store.TaskFilter{}, false
+	}
+
+	if value := ctx.Query("overdue"); value != "" {
+		overdue, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid overdue: use true or false"})
+			return store.TaskFilter{}, false
+		}
+		filter.Overdue = &overdue
+	}
+
+	if value := ctx.Query("project_id"); value == "none" {
+		filter.NoProject = true
+	} else if value != "" {
+		projectID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project_id: use a project ID or none"})
+			return store.TaskFilter{}, false
+		}
+		filter.ProjectID = &projectID
+	}
+
+	if value := ctx.Query("status"); value != "" {
+		status := model.Status(value)
+		if !status.Valid() {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown status '%s'", value)})
+			return store.TaskFilter{}, false
+		}
+		filter.Status = &status
+	}
+
+	filter.Tags = listParam(ctx, "tags")
+	if len(filter.Tags) > maxFilterValues {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Filter on at most %d tags", maxFilterValues)})
+		return store.TaskFilter{}, false
+	}
+	switch ctx.DefaultQuery("tags_match", "any") {
+	case "any":
+	case "all":
+		filter.AllTags = true
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tags_match: use any or all"})
+		return store.TaskFilter{}, false
+	}
+
+	for _, value := range listParam(ctx, "priority") {
+		priority := model.Priority(value)
+		if !priority.Valid() {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown priority '%s': use low, medium or high", value)})
+			return store.TaskFilter{}, false
+		}
+		filter.Priorities = append(filter.Priorities, priority)
+	}
+
+	if value := ctx.Query("completed"); value != "" {
+		completed, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid completed: use true or false"})
+			return store.TaskFilter{}, false
+		}
+		filter.Completed = &completed
+	}
+
+	filter.Title = strings.TrimSpace(ctx.Query("title"))
+	if len(filter.Title) > 100 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid title: use at most 100 characters"})
+		return store.TaskFilter{}, false
+	}
+
+	return filter, true
+}
+
+// maxFilterValues caps how many values a multi-value filter like ?tags= may list
+const maxFilterValues = 20
+
+// listParam reads a multi-value query parameter, given as ?p=a,b or ?p=a&p=b
+// Values are trimmed and each one is returned once, in the order given
+func listParam(ctx *gin.Context, name string) []string {
+	var values []string
+	seen := map[string]bool{}
+	for _, param := range ctx.QueryArray(name) {
+		for _, value := range strings.Split(param, ",") {
+			value = strings.TrimSpace(value)
+			if value != "" && !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// timeBound pairs a query parameter holding a time with where to store it
+type timeBound struct {
+	param  string
+	target **time.Time
+}
+
+// parseTimeBounds reads time parameters with parseTimeParam, leaving the target of a missing one alone
+// They are read in the order given, so the first invalid one is always the one reported;
+// it writes a 400 and returns false when a value can't be parsed
+func parseTimeBounds(ctx *gin.Context, loc *time.Location, bounds ...timeBound) bool {
+	for _, bound := range bounds {
+		value := ctx.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		t, err := parseTimeParam(value, loc)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Invalid %s: use an RFC 3339 time or a YYYY-MM-DD date", bound.param),
+			})
+			return false
+		}
+		*bound.target = &t
+	}
+	return true
+}
+
+// parseTimeParam accepts a full RFC 3339 time (which carries its own offset)
+// or a plain date, which is read as the start of that day in loc
+func parseTimeParam(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.ParseInLocation(dateOnly, value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// normalizeSchedule stores the planning dates in UTC whatever offset the client sent
+func normalizeSchedule(task *model.Task) {
+	if task.StartAt != nil {
+		start := task.StartAt.UTC()
+		task.StartAt = &start
+	}
+	if task.DueAt != nil {
+		due := task.DueAt.UTC()
+		task.DueAt = &due
+	}
+}
+
+// present prepares a task for a response: it computes the overdue flag,
+// fills in the status and board rank of tasks that predate them, links the attachments
+// and shows dates in the request's time zone
+func present(task model.Task, loc *time.Location, now time.Time) model.Task {
+	task.Overdue = task.IsOverdue(now)
+	task.Status = task.EffectiveStatus()
+	task.Rank = task.EffectiveRank()
+	task.Progress = task.ChecklistProgress()
+	if task.Checklist != nil {
+		checklist := make([]model.ChecklistItem, len(task.Checklist))
+		for i, item := range task.Checklist {
+			if item.DoneAt != nil {
+				doneAt := item.DoneAt.In(loc)
+				item.DoneAt = &doneAt
+			}
+			item.CreatedAt = item.CreatedAt.In(loc)
+			checklist[i] = item
+		}
+		task.Checklist = checklist
+	}
+	if task.Attachments != nil {
+		attachments := make([]model.Attachment, len(task.Attachments))
+		for i, attachment := range task.Attachments {
+			attachments[i] = presentAttachment(task.ID, attachment)
+		}
+		task.Attachments = attachments
+	}
+	if task.StatusSince != nil {
+		since := make(model.StatusTimes, len(task.StatusSince))
+		for status, at := range task.StatusSince {
+			since[status] = at.In(loc)
+		}
+		task.StatusSince = since
+	}
+	if task.StartAt != nil {
+		start := task.StartAt.In(loc)
+		task.StartAt = &start
+	}
+	if task.DueAt != nil {
+		due := task.DueAt.In(loc)
+		task.DueAt = &due
+	}
+	return task
+}
+
+// presentAll runs present on every task of a list
+func presentAll(tasks []model.Task, loc *time.Location) []model.Task {
+	now := time.Now()
+	for i := range tasks {
+		tasks[i] = present(tasks[i], loc, now)
+	}
+	return tasks
+}
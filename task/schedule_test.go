@@ -0,0 +1,92 @@
+package task
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTaskDates(t *testing.T) {
+	api := newTestAPI(t)
+	create := func(title string, dates gin.H) string {
+		t.Helper()
+		body := gin.H{"title": title, "description": "d", "priority": "low", "tags": []string{"a"}}
+		for key, value := range dates {
+			body[key] = value
+		}
+		code, res := api.do(http.MethodPost, "/tasks", body)
+		if code != http.StatusCreated {
+			t.Fatalf("create %s: got %d %v", title, code, res)
+		}
+		return res["id"].(string)
+	}
+	soon := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+
+	if code, _ := api.do(http.MethodPost, "/tasks", gin.H{
+		"title": "backwards", "description": "d", "priority": "low", "tags": []string{"a"},
+		"start_at": soon, "due_at": soon.Add(-time.Hour),
+	}); code != http.StatusBadRequest {
+		t.Errorf("start after due: got %d, want 400", code)
+	}
+
+	late := create("late", gin.H{"start_at": "2019-12-30T00:00:00Z", "due_at": "2020-01-01T00:00:00Z"})
+	doneLate := create("done late", gin.H{"due_at": "2020-01-02T00:00:00Z"})
+	api.do(http.MethodPatch, "/tasks/"+doneLate+"/complete", nil)
+	create("soon", gin.H{"due_at": soon})
+	create("someday", nil)
+
+	list := func(query string) string {
+		t.Helper()
+		code, body := api.do(http.MethodGet, "/tasks?"+query, nil)
+		if code != http.StatusOK {
+			t.Fatalf("list ?%s: got %d %v", query, code, body)
+		}
+		got := titles(body)
+		sort.Strings(got)
+		return strings.Join(got, ",")
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		// Only open tasks past their due date are overdue
+		{"overdue=true", "late"},
+		{"overdue=false", "done late,someday,soon"},
+		{"due_before=2021-01-01", "done late,late"},
+		{"due_after=2021-01-01", "soon"},
+		// Both bounds are exclusive, and date-only values are midnight in the request's zone
+		{"due_before=2020-01-01", ""},
+		{"due_before=2020-01-01&tz=America/New_York", "late"},
+		{"due_after=2020-01-01T00:00:00Z&due_before=2020-01-03", "done late"},
+	}
+	for _, tt := range tests {
+		if got := list(tt.query); got != tt.want {
+			t.Errorf("?%s = [%s], want [%s]", tt.query, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{"overdue=maybe", "due_after=soon", "tz=Mars/Olympus"} {
+		if code, _ := api.do(http.MethodGet, "/tasks?"+query, nil); code != http.StatusBadRequest {
+			t.Errorf("?%s: got %d, want 400", query, code)
+		}
+	}
+
+	// Dates are stored in UTC and shown in the request's zone
+	_, body := api.do(http.MethodGet, "/tasks/"+late+"?tz=Asia/Tokyo", nil)
+	task := body["task"].(map[string]interface{})
+	if task["due_at"] != "2020-01-01T09:00:00+09:00" || task["overdue"] != true {
+		t.Errorf("late task in Tokyo: due_at %v, overdue %v", task["due_at"], task["overdue"])
+	}
+
+	// Moving the due date before the start date is refused too
+	if code, _ := api.do(http.MethodPatch, "/tasks/"+late, gin.H{"due_at": "2019-12-29T00:00:00Z"}); code != http.StatusBadRequest {
+		t.Errorf("patch due before start: got %d, want 400", code)
+	}
+	if code, _ := api.do(http.MethodPatch, "/tasks/"+late, gin.H{"start_at": nil, "due_at": "2019-12-29T00:00:00Z"}); code != http.StatusOK {
+		t.Errorf("patch due after clearing start: got %d, want 200", code)
+	}
+}
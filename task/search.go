@@ -0,0 +1,240 @@
+package task
+
+import (
+	"html"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxSearchMatches caps how many tasks, and how many comments, one search ranks
+const maxSearchMatches = 500
+
+// maxSearchQuery is the longest search string accepted
+const maxSearchQuery = 200
+
+// snippetLength is roughly how many bytes of a description or comment a snippet shows
+const snippetLength = 160
+
+// snippetsPerTask caps how many matching comments are shown under one task
+const snippetsPerTask = 3
+
+// searchResult is one task found by a search, with the matching parts of it highlighted
+type searchResult struct {
+	Task       model.Task     `json:"task"`
+	Score      float64        `json:"score"`
+	Highlights taskHighlights `json:"highlights"`
+}
+
+// taskHighlights holds HTML-escaped text with every matching word wrapped in <mark></mark>
+// Fields without a match are left out
+type taskHighlights struct {
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"` // A snippet around the first match
+	Comments    []commentHighlight `json:"comments,omitempty"`
+}
+
+// commentHighlight is a snippet of one matching comment
+type commentHighlight struct {
+	ID      primitive.ObjectID `json:"id"`
+	Snippet string             `json:"snippet"`
+}
+
+// SearchTasks finds tasks by the words in their title, description and comments, best matches first
+// Query parameters:
+// - q: words to look for; "quoted phrases" must all appear and -word or -"phrase" excludes tasks
+// - page / limit: like GetTask
+// - tz: IANA time zone used to show dates (default: UTC)
+// A task found through one of its comments is still dropped when its own title or description has an excluded word
+func (h *Handler) SearchTasks(ctx *gin.Context) {
+	start := time.Now()
+
+	q := strings.TrimSpace(ctx.Query("q"))
+	if q == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if len(q) > maxSearchQuery {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "q must be at most " + strconv.Itoa(maxSearchQuery) + " characters"})
+		return
+	}
+	query := store.ParseTextQuery(q)
+	if query.Empty() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "q needs at least one word or phrase that isn't excluded"})
+		return
+	}
+
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return
+	}
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	taskMatches, err := h.Store.SearchTasks(dbCtx, scope, query, maxSearchMatches)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks: " + err.Error()})
+		return
+	}
+	commentMatches, err := h.Comments.SearchComments(dbCtx, scope, query, maxSearchMatches)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search comments: " + err.Error()})
+		return
+	}
+
+	// A task ranks by its own score plus that of its best comment
+	// Both lists are already best first, so the first comment seen for a task is its best
+	scores := map[primitive.ObjectID]float64{}
+	fromFields := map[primitive.ObjectID]bool{}
+	comments := map[primitive.ObjectID][]store.CommentMatch{}
+	for _, match := range taskMatches {
+		scores[match.TaskID] = match.Score
+		fromFields[match.TaskID] = true
+	}
+	for _, match := range commentMatches {
+		if len(comments[match.TaskID]) == 0 {
+			scores[match.TaskID] += match.Score
+		}
+		if len(comments[match.TaskID]) < snippetsPerTask {
+			comments[match.TaskID] = append(comments[match.TaskID], match)
+		}
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	tasks, err := h.Store.GetMany(dbCtx, scope, ids)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks: " + err.Error()})
+		return
+	}
+
+	// Tasks only found through a comment still have to pass the exclusions on their own fields
+	found := make([]model.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if !fromFields[task.ID] && query.Excludes(task.Title+"\n"+task.Description) {
+			continue
+		}
+		found = append(found, task)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		a, b := scores[found[i].ID], scores[found[j].ID]
+		if a != b {
+			return a > b
+		}
+		return found[i].ID.Hex() > found[j].ID.Hex() // ObjectIDs grow over time: newest first
+	})
+
+	total := int64(len(found))
+	from := min((page-1)*limit, len(found))
+	pageTasks, err := h.presentTasks(dbCtx, scope, found[from:min(from+limit, len(found))], loc)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks: " + err.Error()})
+		return
+	}
+
+	results := make([]searchResult, len(pageTasks))
+	for i, task := range pageTasks {
+		result := searchResult{Task: task, Score: scores[task.ID]}
+		if spans := query.Highlights(task.Title); len(spans) > 0 {
+			result.Highlights.Title = highlight(task.Title, spans, 0, len(task.Title))
+		}
+		result.Highlights.Description = snippet(task.Description, query.Highlights(task.Description))
+		for _, comment := range comments[task.ID] {
+			result.Highlights.Comments = append(result.Highlights.Comments, commentHighlight{
+				ID:      comment.CommentID,
+				Snippet: snippet(comment.Body, query.Highlights(comment.Body)),
+			})
+		}
+		results[i] = result
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Search completed successfully",
+		"duration": time.Since(start).Seconds(),
+		"results":  results,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": totalPages,
+			"hasMore":    page < totalPages,
+		},
+		// Only the best matches are ranked; a very broad search may leave some out
+		"truncated": len(taskMatches) == maxSearchMatches || len(commentMatches) == maxSearchMatches,
+	})
+}
+
+// snippet cuts about snippetLength bytes of text around the first match and highlights it
+// It returns "" when nothing in text matched
+func snippet(text string, spans [][2]int) string {
+	if len(spans) == 0 {
+		return ""
+	}
+
+	// Start a little before the first match, on a word boundary, and never inside a UTF-8 sequence
+	from := max(spans[0][0]-snippetLength/4, 0)
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	if from > 0 {
+		if space := strings.IndexByte(text[from:spans[0][0]], ' '); space >= 0 {
+			from += space + 1
+		}
+	}
+	to := min(from+snippetLength, len(text))
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+
+	out := highlight(text, spans, from, to)
+	if from > 0 {
+		out = "…" + out
+	}
+	if to < len(text) {
+		out += "…"
+	}
+	return out
+}
+
+// highlight escapes text[from:to] for HTML and wraps the spans inside it in <mark></mark>
+func highlight(text string, spans [][2]int, from, to int) string {
+	var b strings.Builder
+	at := from
+	for _, span := range spans {
+		if span[0] < at || span[1] > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[at:span[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[span[0]:span[1]]) + "</mark>")
+		at = span[1]
+	}
+	b.WriteString(html.EscapeString(text[at:to]))
+	return b.String()
+}
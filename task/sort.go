@@ -0,0 +1,67 @@
+package task
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// maxSortKeys caps how many keys one sort may list
+const maxSortKeys = 4
+
+// parseSort reads the sort parameter: a comma separated list of keys, most significant first
+// Each key is one of store.SortFields or field.<key>, with a leading - for descending order,
+// so sort=-priority,due_at lists high priority tasks first and, within a priority, the earliest due
+// defs holds the custom fields by key; it's only consulted for field.<key> entries
+// It writes a 400 and returns false for a key that isn't on the list
+func parseSort(ctx *gin.Context, defs map[string]model.CustomField) (store.TaskSort, bool) {
+	param := ctx.Query("sort")
+	if param == "" {
+		return nil, true
+	}
+
+	entries := strings.Split(param, ",")
+	if len(entries) > maxSortKeys {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid sort: use at most %d keys", maxSortKeys)})
+		return nil, false
+	}
+
+	var keys store.TaskSort
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		name, desc := strings.CutPrefix(entry, "-")
+		if seen[name] {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid sort: %q is listed twice", name)})
+			return nil, false
+		}
+		seen[name] = true
+
+		if key, custom := strings.CutPrefix(name, fieldParamPrefix); custom {
+			def, ok := defs[key]
+			if !ok {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown custom field %q", key)})
+				return nil, false
+			}
+			if def.Type == model.FieldMultiSelect {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "Tasks can't be sorted by a multi_select field"})
+				return nil, false
+			}
+			keys = append(keys, store.SortKey{Field: key, Custom: true, Desc: desc})
+			continue
+		}
+
+		if !slices.Contains(store.SortFields, name) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid sort %q: use %s or field.<key>, with - for descending",
+				entry, strings.Join(store.SortFields, ", "))})
+			return nil, false
+		}
+		keys = append(keys, store.SortKey{Field: name, Desc: desc})
+	}
+	return keys, true
+}
@@ -0,0 +1,163 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// transitionRequest is the JSON body accepted by Transition
+type transitionRequest struct {
+	Status model.Status `json:"status" binding:"required"`
+}
+
+// GetWorkflow describes the statuses and the moves allowed between them,
+// so clients can offer only the transitions that will succeed
+func (h *Handler) GetWorkflow(ctx *gin.Context) {
+	terminal := []model.Status{}
+	for _, status := range model.Statuses {
+		if status.IsTerminal() {
+			terminal = append(terminal, status)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "Workflow retrieved successfully",
+		"statuses":    model.Statuses,
+		"terminal":    terminal,
+		"transitions": h.Workflow.Transitions(),
+	})
+}
+
+// ListTransitions returns the task's status and the statuses it may move to next
+func (h *Handler) ListTransitions(ctx *gin.Context) {
+	task, _, _, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	status := task.EffectiveStatus()
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Transitions retrieved successfully",
+		"status":  status,
+		"next":    h.Workflow.Next(status),
+	})
+}
+
+// Transition moves a task to another status, if the workflow allows the move
+// Entering a terminal status (done, cancelled) completes the task
+// Query parameters:
+// - force: true to finish the task even though some of its blockers are still open
+func (h *Handler) Transition(ctx *gin.Context) {
+	var req transitionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Status.Valid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown status '%s'", req.Status)})
+		return
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	moved, next, ok := h.moveTask(ctx, dbCtx, scope, task, req.Status, store.TaskUpdate{})
+	if !ok {
+		return
+	}
+
+	moved, err := h.presentTask(dbCtx, scope, moved, loc)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve task: " + err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"message": fmt.Sprintf("Task moved to %s", moved.Status),
+		"task":    moved,
+	}
+	if next != nil {
+		response["next_task"] = present(*next, loc, time.Now())
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// moveTask checks a status change against the workflow and the task's blockers, then applies it
+// together with any other change already on update (like a new board position), in one write
+// Finishing an occurrence of a recurring task creates the next one, which is returned too
+// It writes the error response itself and returns false when the request must stop
+func (h *Handler) moveTask(ctx *gin.Context, dbCtx context.Context, scope store.Scope, task model.Task, to model.Status, update store.TaskUpdate) (model.Task, *model.Task, bool) {
+	from := task.EffectiveStatus()
+	if from == to {
+		ctx.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Task is already %s", to)})
+		return model.Task{}, nil, false
+	}
+	if !h.Workflow.Allows(from, to) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":   fmt.Sprintf("A task cannot move from %s to %s", from, to),
+			"allowed": h.Workflow.Next(from),
+		})
+		return model.Task{}, nil, false
+	}
+
+	// A task can't be done while tasks blocking it are still open, unless ?force=true
+	// Cancelling it is always allowed
+	if to == model.StatusDone && !forceRequested(ctx) {
+		open, err := h.openBlockers(dbCtx, scope, task)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to check task dependencies",
+				"details": err.Error(),
+			})
+			return model.Task{}, nil, false
+		}
+		if len(open) > 0 {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error":      "Task is blocked by open tasks, complete them first or use ?force=true",
+				"blocked_by": open,
+			})
+			return model.Task{}, nil, false
+		}
+	}
+
+	update.Transition = &store.StatusChange{From: from, To: to, At: time.Now()}
+	moved, err := h.Store.Update(dbCtx, scope, task.ID, update)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return model.Task{}, nil, false
+	}
+	if errors.Is(err, store.ErrStatusConflict) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Task status was changed by another request, reload it and try again"})
+		return model.Task{}, nil, false
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update task status",
+			"details": err.Error(),
+		})
+		return model.Task{}, nil, false
+	}
+
+	next, err := h.spawnNextOccurrence(dbCtx, scope, moved)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Task completed but the next occurrence could not be created",
+			"details": err.Error(),
+		})
+		return model.Task{}, nil, false
+	}
+	return moved, next, true
+}
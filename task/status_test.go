@@ -0,0 +1,64 @@
+package task
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTransitions(t *testing.T) {
+	api := newTestAPI(t)
+	task, blocker := api.create("task"), api.create("blocker")
+	move := func(id, status string) (int, map[string]interface{}) {
+		t.Helper()
+		return api.do(http.MethodPost, "/tasks/"+id+"/transitions", gin.H{"status": status})
+	}
+
+	_, body := api.do(http.MethodGet, "/tasks/"+task+"/transitions", nil)
+	if want := []interface{}{"in_progress", "done", "cancelled"}; body["status"] != "todo" || !reflect.DeepEqual(body["next"], want) {
+		t.Errorf("transitions of a new task = %v", body)
+	}
+
+	tests := []struct {
+		name   string
+		status string
+		want   int
+	}{
+		{"unknown status", "started", http.StatusBadRequest},
+		{"same status", "todo", http.StatusConflict},
+		{"skipping a step", "in_review", http.StatusConflict},
+		{"start", "in_progress", http.StatusOK},
+		{"review", "in_review", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code, body := move(task, tt.status); code != tt.want {
+			t.Errorf("%s: got %d %v, want %d", tt.name, code, body, tt.want)
+		}
+	}
+
+	// Refused moves list the ones that would succeed
+	code, body := move(task, "todo")
+	if want := []interface{}{"in_progress", "done", "cancelled"}; code != http.StatusConflict || !reflect.DeepEqual(body["allowed"], want) {
+		t.Errorf("in_review -> todo: got %d %v", code, body)
+	}
+
+	// Open blockers hold back done but not cancelled
+	api.do(http.MethodPost, "/tasks/"+task+"/dependencies", gin.H{"blocker_id": blocker})
+	if code, _ := move(task, "done"); code != http.StatusConflict {
+		t.Errorf("done while blocked: got %d, want 409", code)
+	}
+	code, body = api.do(http.MethodPost, "/tasks/"+task+"/transitions?force=true", gin.H{"status": "done"})
+	if code != http.StatusOK || body["task"].(map[string]interface{})["completed"] != true {
+		t.Errorf("forced done: got %d %v", code, body)
+	}
+	if code, body := move(blocker, "cancelled"); code != http.StatusOK || body["task"].(map[string]interface{})["completed"] != true {
+		t.Errorf("cancel: got %d %v", code, body)
+	}
+
+	_, body = api.do(http.MethodGet, "/workflow", nil)
+	if want := []interface{}{"done", "cancelled"}; !reflect.DeepEqual(body["terminal"], want) {
+		t.Errorf("terminal statuses = %v, want %v", body["terminal"], want)
+	}
+}
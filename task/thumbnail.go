@@ -0,0 +1,120 @@
+package task
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// thumbnailSize is the largest width and height of a thumbnail, in pixels
+const thumbnailSize = 256
+
+// maxImagePixels guards against "decompression bombs": small files that decode into huge images
+const maxImagePixels = 40_000_000
+
+// decodableTypes are the image formats the standard library can decode
+// Other accepted types (WebP) are stored without dimensions or a thumbnail
+var decodableTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/gif": true}
+
+// imageConfig reads an image's dimensions from its header without decoding the pixels
+func imageConfig(data []byte) (image.Config, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return config, err
+}
+
+// thumbnailType is the content type of the thumbnail made for an upload:
+// JPEGs stay JPEG, PNG and GIF become PNG so transparency survives
+func thumbnailType(contentType string) string {
+	if contentType == "image/jpeg" {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// makeThumbnail scales the image down to fit in thumbnailSize x thumbnailSize, keeping its aspect ratio
+// Smaller images keep their size and are only re-encoded
+func makeThumbnail(data []byte, contentType string) ([]byte, error) {
+	var (
+		src image.Image
+		err error
+	)
+	switch contentType {
+	case "image/jpeg":
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		src, err = png.Decode(bytes.NewReader(data))
+	default:
+		src, err = gif.Decode(bytes.NewReader(data)) // The first frame of an animation
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	thumb := scaleDown(src, thumbnailSize)
+
+	var out bytes.Buffer
+	if thumbnailType(contentType) == "image/jpeg" {
+		err = jpeg.Encode(&out, thumb, &jpeg.Options{Quality: 80})
+	} else {
+		err = png.Encode(&out, thumb)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// scaleDown shrinks src to fit in a max x max square with a box filter:
+// every thumbnail pixel is the average of the source pixels it covers
+func scaleDown(src image.Image, max int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	tw, th := w, h
+	if w > max || h > max {
+		if w >= h {
+			tw, th = max, h*max/w
+		} else {
+			tw, th = w*max/h, max
+		}
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := y*h/th, (y+1)*h/th
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < tw; x++ {
+			x0, x1 := x*w/tw, (x+1)*w/tw
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			// RGBA() returns premultiplied 16-bit values, so averaging keeps transparent edges clean
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
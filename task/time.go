@@ -0,0 +1,473 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxLoggedDuration is the longest stretch one manual entry may cover
+const maxLoggedDuration = 24 * time.Hour
+
+// maxTimeNote is the longest note accepted on a time entry, in characters
+const maxTimeNote = 500
+
+// timeEntryRequest is the JSON body accepted by LogTime
+type timeEntryRequest struct {
+	Duration  string     `json:"duration" binding:"required"` // Go duration such as "45m" or "1h30m"
+	StartedAt *time.Time `json:"started_at"`                  // Defaults to now minus the duration
+	Note      string     `json:"note"`
+}
+
+// tagTime adds up the time of every task carrying one tag
+type tagTime struct {
+	Tag     string `json:"tag"`
+	Seconds int64  `json:"seconds"`
+	Entries int64  `json:"entries"`
+}
+
+// reportedTask is one line of the time report: a task's total with what identifies it
+type reportedTask struct {
+	TaskID  primitive.ObjectID `json:"task_id"`
+	Title   string             `json:"title"`
+	Tags    []string           `json:"tags"`
+	Seconds int64              `json:"seconds"`
+	Entries int64              `json:"entries"`
+}
+
+// StartTimer starts a timer on the task for the authenticated user
+// Each user has at most one running timer: while one runs the answer is a 409 carrying it
+func (h *Handler) StartTimer(ctx *gin.Context) {
+	user, ok := auth.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	task, _, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	entry := model.TimeEntry{
+		ID:        primitive.NewObjectID(),
+		TaskID:    task.ID,
+		OwnerID:   task.OwnerID,
+		UserID:    user.ID,
+		StartedAt: now,
+		Running:   true,
+		Metadata:  model.Metadata{CreatedAt: now, UpdatedAt: now},
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	err := h.TimeEntries.CreateTimeEntry(dbCtx, &entry)
+	if errors.Is(err, store.ErrTimerRunning) {
+		response := gin.H{"error": "A timer is already running, stop it first"}
+		if running, err := h.TimeEntries.RunningTimer(dbCtx, user.ID); err == nil {
+			response["timer"] = presentTimeEntry(running, loc, now)
+		}
+		ctx.JSON(http.StatusConflict, response)
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start the timer: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Timer started successfully",
+		"timer":   presentTimeEntry(entry, loc, now),
+	})
+}
+
+// StopTimer stops the authenticated user's running timer, whatever task it runs on
+func (h *Handler) StopTimer(ctx *gin.Context) {
+	user, ok := auth.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	now := time.Now()
+	entry, err := h.TimeEntries.StopTimer(dbCtx, user.ID, now)
+	if errors.Is(err, store.ErrTimeEntryNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No timer is running"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop the timer: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Timer stopped successfully",
+		"entry":   presentTimeEntry(entry, loc, now),
+	})
+}
+
+// CurrentTimer returns the authenticated user's running timer, or null when none runs
+func (h *Handler) CurrentTimer(ctx *gin.Context) {
+	user, ok := auth.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	entry, err := h.TimeEntries.RunningTimer(dbCtx, user.ID)
+	if errors.Is(err, store.ErrTimeEntryNotFound) {
+		ctx.JSON(http.StatusOK, gin.H{"message": "No timer is running", "timer": nil})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the timer: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Timer retrieved successfully",
+		"timer":   presentTimeEntry(entry, loc, time.Now()),
+	})
+}
+
+// LogTime records time spent on the task without a timer: a duration, when it started and an optional note
+func (h *Handler) LogTime(ctx *gin.Context) {
+	var req timeEntryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration < time.Second {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration: use a positive duration such as 45m or 1h30m"})
+		return
+	}
+	if duration > maxLoggedDuration {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("duration must be at most %s", maxLoggedDuration)})
+		return
+	}
+	note := strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(note) > maxTimeNote {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("note must be at most %d characters", maxTimeNote)})
+		return
+	}
+
+	now := time.Now()
+	startedAt := now.Add(-duration)
+	if req.StartedAt != nil {
+		startedAt = req.StartedAt.UTC()
+	}
+	endedAt := startedAt.Add(duration)
+	if endedAt.After(now) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Logged time can't end in the future"})
+		return
+	}
+
+	user, ok := auth.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	task, _, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	entry := model.TimeEntry{
+		ID:        primitive.NewObjectID(),
+		TaskID:    task.ID,
+		OwnerID:   task.OwnerID,
+		UserID:    user.ID,
+		StartedAt: startedAt,
+		EndedAt:   &endedAt,
+		Seconds:   int64(duration / time.Second),
+		Manual:    true,
+		Note:      note,
+		Metadata:  model.Metadata{CreatedAt: now, UpdatedAt: now},
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	if err := h.TimeEntries.CreateTimeEntry(dbCtx, &entry); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log the time: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Time logged successfully",
+		"entry":   presentTimeEntry(entry, loc, now),
+	})
+}
+
+// ListTimeEntries returns the task's time entries, most recent first, with the task's total
+// Query parameters:
+// - page: current page number (default: 1)
+// - limit: number of entries per page (default: 20, max 100)
+func (h *Handler) ListTimeEntries(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	task, scope, loc, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	entries, total, err := h.TimeEntries.ListTimeEntries(dbCtx, scope, task.ID, store.ListOptions{Page: page, Limit: limit})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve time entries: " + err.Error()})
+		return
+	}
+	tracked, err := h.taskTime(dbCtx, scope, task.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve time entries: " + err.Error()})
+		return
+	}
+
+	now := time.Now()
+	for i := range entries {
+		entries[i] = presentTimeEntry(entries[i], loc, now)
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Time entries retrieved successfully",
+		"task_id": task.ID,
+		"time":    tracked,
+		"entries": entries,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": totalPages,
+			"hasMore":    page < totalPages,
+		},
+	})
+}
+
+// DeleteTimeEntry removes one of the task's time entries; deleting a running timer cancels it
+func (h *Handler) DeleteTimeEntry(ctx *gin.Context) {
+	entryID, err := primitive.ObjectIDFromHex(ctx.Param("entry_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time entry ID format"})
+		return
+	}
+
+	task, scope, _, ok := h.loadTask(ctx)
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	err = h.TimeEntries.DeleteTimeEntry(dbCtx, scope, task.ID, entryID)
+	if errors.Is(err, store.ErrTimeEntryNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Time entry not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete time entry: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Time entry deleted successfully",
+		"task_id":  task.ID,
+		"entry_id": entryID,
+	})
+}
+
+// TimeReport sums up the time tracked on the caller's tasks, per task and per tag, largest first
+// Running timers are left out until they are stopped
+// Query parameters:
+// - from / to: only entries started in [from, to), as RFC 3339 times or YYYY-MM-DD dates in the request's zone
+// - tag: only tasks carrying this tag
+// - tz: IANA time zone used to read date-only values (default: UTC)
+func (h *Handler) TimeReport(ctx *gin.Context) {
+	scope, ok := h.Scope(ctx)
+	if !ok {
+		return
+	}
+	loc, ok := requestLocation(ctx)
+	if !ok {
+		return
+	}
+
+	var filter store.TimeFilter
+	if !parseTimeBounds(ctx, loc, timeBound{"from", &filter.From}, timeBound{"to", &filter.To}) {
+		return
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	tag := strings.TrimSpace(ctx.Query("tag"))
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	sums, err := h.TimeEntries.SumTimeByTask(dbCtx, scope, filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the time report: " + err.Error()})
+		return
+	}
+
+	// Titles and tags come from the tasks themselves
+	ids := make([]primitive.ObjectID, len(sums))
+	for i, sum := range sums {
+		ids[i] = sum.TaskID
+	}
+	tasks, err := h.Store.GetMany(dbCtx, scope, ids)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the time report: " + err.Error()})
+		return
+	}
+	byID := make(map[primitive.ObjectID]model.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	var (
+		total  int64
+		byTask = []reportedTask{}
+		byTag  = map[string]*tagTime{}
+	)
+	for _, sum := range sums {
+		task, ok := byID[sum.TaskID]
+		if !ok {
+			continue // Deleted while the report was built
+		}
+		if tag != "" && !hasTag(task, tag) {
+			continue
+		}
+
+		total += sum.Seconds
+		byTask = append(byTask, reportedTask{
+			TaskID: task.ID, Title: task.Title, Tags: task.Tags, Seconds: sum.Seconds, Entries: sum.Entries,
+		})
+		for _, t := range task.Tags {
+			line, ok := byTag[t]
+			if !ok {
+				line = &tagTime{Tag: t}
+				byTag[t] = line
+			}
+			line.Seconds += sum.Seconds
+			line.Entries += sum.Entries
+		}
+	}
+
+	sort.Slice(byTask, func(i, j int) bool {
+		if byTask[i].Seconds != byTask[j].Seconds {
+			return byTask[i].Seconds > byTask[j].Seconds
+		}
+		return byTask[i].TaskID.Hex() < byTask[j].TaskID.Hex()
+	})
+	tags := make([]tagTime, 0, len(byTag))
+	for _, line := range byTag {
+		tags = append(tags, *line)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Seconds != tags[j].Seconds {
+			return tags[i].Seconds > tags[j].Seconds
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	report := gin.H{
+		"message":       "Time report retrieved successfully",
+		"total_seconds": total,
+		"by_task":       byTask,
+		"by_tag":        tags, // A task with several tags counts under each of them
+	}
+	if filter.From != nil {
+		report["from"] = filter.From.In(loc)
+	}
+	if filter.To != nil {
+		report["to"] = filter.To.In(loc)
+	}
+	if tag != "" {
+		report["tag"] = tag
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// taskTime adds up the stopped time entries of one task
+func (h *Handler) taskTime(ctx context.Context, scope store.Scope, id primitive.ObjectID) (model.TaskTime, error) {
+	sums, err := h.TimeEntries.SumTimeByTask(ctx, scope, store.TimeFilter{TaskIDs: []primitive.ObjectID{id}})
+	if err != nil || len(sums) == 0 {
+		return model.TaskTime{TaskID: id}, err
+	}
+	return sums[0], nil
+}
+
+// presentTimeEntry shows the entry's times in the request's zone
+// A running timer has no stored duration yet, so its seconds are how long it has run so far
+func presentTimeEntry(entry model.TimeEntry, loc *time.Location, now time.Time) model.TimeEntry {
+	if entry.Running {
+		entry.Seconds = int64(now.Sub(entry.StartedAt) / time.Second)
+		if entry.Seconds < 0 {
+			entry.Seconds = 0
+		}
+	}
+	entry.StartedAt = entry.StartedAt.In(loc)
+	if entry.EndedAt != nil {
+		ended := entry.EndedAt.In(loc)
+		entry.EndedAt = &ended
+	}
+	entry.Metadata.CreatedAt = entry.Metadata.CreatedAt.In(loc)
+	entry.Metadata.UpdatedAt = entry.Metadata.UpdatedAt.In(loc)
+	return entry
+}
+
+// hasTag reports whether the task carries the tag
+func hasTag(task model.Task, tag string) bool {
+	for _, t := range task.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
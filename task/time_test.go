@@ -0,0 +1,154 @@
+package task
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeReportRange(t *testing.T) {
+	api := newTestAPI(t)
+	tests := []struct {
+		query string
+		want  int
+		error string
+	}{
+		{"from=2026-01-01&to=2026-02-01", http.StatusOK, ""},
+		{"from=yesterday&to=2026-02-01", http.StatusBadRequest, "Invalid from"},
+		{"from=2026-01-01&to=soon", http.StatusBadRequest, "Invalid to"},
+		// With both wrong, the answer must not depend on map iteration order
+		{"from=yesterday&to=soon", http.StatusBadRequest, "Invalid from"},
+		{"from=2026-02-01&to=2026-01-01", http.StatusBadRequest, "from must be before to"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			for range 20 {
+				code, body := api.do(http.MethodGet, "/time/report?"+tt.query, nil)
+				if code != tt.want {
+					t.Fatalf("got %d %v, want %d", code, body, tt.want)
+				}
+				if msg, _ := body["error"].(string); !strings.HasPrefix(msg, tt.error) {
+					t.Fatalf("error = %q, want it to start with %q", msg, tt.error)
+				}
+			}
+		})
+	}
+}
+
+func TestTimer(t *testing.T) {
+	api := newTestAPI(t)
+	first, second := api.create("first"), api.create("second")
+
+	if code, _ := api.do(http.MethodPost, "/timer/stop", nil); code != http.StatusNotFound {
+		t.Errorf("stop with no timer: got %d, want 404", code)
+	}
+	if _, body := api.do(http.MethodGet, "/timer", nil); body["timer"] != nil {
+		t.Errorf("current timer before starting: %v", body["timer"])
+	}
+
+	code, body := api.do(http.MethodPost, "/tasks/"+first+"/timer/start", nil)
+	if code != http.StatusCreated {
+		t.Fatalf("start: got %d %v", code, body)
+	}
+	timerID := body["timer"].(map[string]interface{})["id"]
+
+	// One timer at a time: the conflict names the one that runs
+	code, body = api.do(http.MethodPost, "/tasks/"+second+"/timer/start", nil)
+	if code != http.StatusConflict || body["timer"].(map[string]interface{})["id"] != timerID {
+		t.Errorf("second start: got %d %v, want 409 with the running timer", code, body)
+	}
+	if _, body := api.do(http.MethodGet, "/timer", nil); body["timer"].(map[string]interface{})["task_id"] != first {
+		t.Errorf("current timer = %v", body["timer"])
+	}
+
+	// Timers are per user, so someone else may start one at the same time
+	other := api.newUser("other")
+	_, body = api.doAs(other, http.MethodPost, "/tasks", gin.H{"title": "theirs", "description": "d", "priority": "low", "tags": []string{"a"}})
+	if code, _ := api.doAs(other, http.MethodPost, "/tasks/"+body["id"].(string)+"/timer/start", nil); code != http.StatusCreated {
+		t.Errorf("another user's timer: got %d, want 201", code)
+	}
+
+	code, body = api.do(http.MethodPost, "/timer/stop", nil)
+	if code != http.StatusOK || body["entry"].(map[string]interface{})["running"] != false {
+		t.Fatalf("stop: got %d %v", code, body)
+	}
+	if code, _ := api.do(http.MethodPost, "/timer/stop", nil); code != http.StatusNotFound {
+		t.Errorf("stop twice: got %d, want 404", code)
+	}
+	if code, _ := api.do(http.MethodPost, "/tasks/"+second+"/timer/start", nil); code != http.StatusCreated {
+		t.Errorf("start after stop: got %d, want 201", code)
+	}
+}
+
+func TestLogTime(t *testing.T) {
+	api := newTestAPI(t)
+	id := api.create("logged")
+
+	tests := []struct {
+		name string
+		body gin.H
+		want int
+	}{
+		{"duration only", gin.H{"duration": "45m"}, http.StatusCreated},
+		{"with start and note", gin.H{"duration": "1h30m", "started_at": time.Now().Add(-3 * time.Hour), "note": "review"}, http.StatusCreated},
+		{"missing duration", gin.H{"note": "?"}, http.StatusBadRequest},
+		{"not a duration", gin.H{"duration": "an hour"}, http.StatusBadRequest},
+		{"negative", gin.H{"duration": "-5m"}, http.StatusBadRequest},
+		{"under a second", gin.H{"duration": "500ms"}, http.StatusBadRequest},
+		{"over a day", gin.H{"duration": "25h"}, http.StatusBadRequest},
+		{"ends in the future", gin.H{"duration": "1h", "started_at": time.Now().Add(-30 * time.Minute)}, http.StatusBadRequest},
+		{"long note", gin.H{"duration": "5m", "note": strings.Repeat("n", maxTimeNote+1)}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if code, body := api.do(http.MethodPost, "/tasks/"+id+"/time", tt.body); code != tt.want {
+			t.Errorf("%s: got %d %v, want %d", tt.name, code, body, tt.want)
+		}
+	}
+
+	code, body := api.do(http.MethodGet, "/tasks/"+id+"/time", nil)
+	if code != http.StatusOK || body["time"].(map[string]interface{})["seconds"] != float64(135*60) {
+		t.Fatalf("entries: got %d %v", code, body)
+	}
+
+	entry := body["entries"].([]interface{})[0].(map[string]interface{})["id"].(string)
+	if code, _ := api.do(http.MethodDelete, "/tasks/"+id+"/time/"+entry, nil); code != http.StatusOK {
+		t.Errorf("delete entry: got %d", code)
+	}
+	if code, _ := api.do(http.MethodDelete, "/tasks/"+id+"/time/"+entry, nil); code != http.StatusNotFound {
+		t.Errorf("delete entry twice: got %d, want 404", code)
+	}
+}
+
+func TestTimeReportTotals(t *testing.T) {
+	api := newTestAPI(t)
+	create := func(title string, tags ...string) string {
+		_, body := api.do(http.MethodPost, "/tasks", gin.H{"title": title, "description": "d", "priority": "low", "tags": tags})
+		return body["id"].(string)
+	}
+	design, build := create("design", "ui", "q1"), create("build", "q1")
+	api.do(http.MethodPost, "/tasks/"+design+"/time", gin.H{"duration": "30m"})
+	api.do(http.MethodPost, "/tasks/"+build+"/time", gin.H{"duration": "1h"})
+	api.do(http.MethodPost, "/tasks/"+build+"/time", gin.H{"duration": "15m"})
+	api.do(http.MethodPost, "/tasks/"+design+"/timer/start", nil) // Running, so not reported
+
+	_, body := api.do(http.MethodGet, "/time/report", nil)
+	if body["total_seconds"] != float64(105*60) {
+		t.Errorf("total = %v, want 105 minutes", body["total_seconds"])
+	}
+	byTask := body["by_task"].([]interface{})
+	if len(byTask) != 2 || byTask[0].(map[string]interface{})["task_id"] != build || byTask[0].(map[string]interface{})["entries"] != float64(2) {
+		t.Errorf("by task = %v, want build first with two entries", byTask)
+	}
+	byTag := body["by_tag"].([]interface{})
+	if len(byTag) != 2 || byTag[0].(map[string]interface{})["tag"] != "q1" || byTag[0].(map[string]interface{})["seconds"] != float64(105*60) {
+		t.Errorf("by tag = %v, want q1 first with everything", byTag)
+	}
+
+	_, body = api.do(http.MethodGet, "/time/report?tag=ui", nil)
+	if body["total_seconds"] != float64(30*60) {
+		t.Errorf("total for ui = %v, want 30 minutes", body["total_seconds"])
+	}
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/blob"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// DeleteUser removes another user's account together with all of their tasks, projects, comments and uploaded files
+func (h *Handler) DeleteUser(ctx *gin.Context) {
+	id, ok := targetUser(ctx, "delete")
+	if !ok {
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	// Check the user exists first, so a typo in the ID doesn't report success
+	if _, err := h.Users.GetUserByID(dbCtx, id); errors.Is(err, store.ErrUserNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user: " + err.Error()})
+		return
+	}
+
+	// Delete the tasks before the account: if this fails the user still exists
+	// and the request can simply be retried
+	deletedTasks, err := h.Tasks.DeleteByOwner(dbCtx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the user's tasks: " + err.Error()})
+		return
+	}
+	if _, err := h.Projects.DeleteProjectsByOwner(dbCtx, id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the user's projects: " + err.Error()})
+		return
+	}
+	if _, err := h.Comments.DeleteCommentsByOwner(dbCtx, id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the comments on the user's tasks: " + err.Error()})
+		return
+	}
+	if _, err := h.TimeEntries.DeleteTimeEntriesByOwner(dbCtx, id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the time tracked on the user's tasks: " + err.Error()})
+		return
+	}
+	if _, err := h.Fields.DeleteCustomFieldsByOwner(dbCtx, id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the user's custom fields: " + err.Error()})
+		return
+	}
+	// The attachment metadata went with the tasks; the files can't be reached anymore,
+	// so failing to remove them only leaves garbage behind and doesn't stop the deletion
+	if err := h.Blobs.DeletePrefix(dbCtx, blob.OwnerPrefix(id)); err != nil {
+		fmt.Printf("Warning: failed to delete the files of user %s: %v\n", id.Hex(), err)
+	}
+
+	err = h.Users.DeleteUser(dbCtx, id)
+	if errors.Is(err, store.ErrUserNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       "User deleted successfully",
+		"deleted_tasks": deletedTasks,
+	})
+}
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/auth"
+	"github.com/joshua-takyi/todo/blob"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Handler groups the user management handlers used by admins
+// It needs the task, project, comment, time entry and custom field stores and the blob store too, because deleting a user also deletes their data
+type Handler struct {
+	Users       store.UserStore
+	Tasks       store.TaskStore
+	Projects    store.ProjectStore
+	Comments    store.CommentStore
+	TimeEntries store.TimeEntryStore
+	Fields      store.CustomFieldStore
+	Blobs       blob.Store
+}
+
+// NewHandler creates a Handler backed by the given stores
+func NewHandler(stores store.Stores, blobs blob.Store) *Handler {
+	return &Handler{
+		Users: stores.Users, Tasks: stores.Tasks, Projects: stores.Projects, Comments: stores.Comments,
+		TimeEntries: stores.TimeEntries, Fields: stores.CustomFields, Blobs: blobs,
+	}
+}
+
+// targetUser parses the :id path parameter and refuses to act on the caller's own account,
+// so an admin can't lock themselves out by demoting or deleting themselves
+// It writes the error response itself and returns false when the request must stop
+func targetUser(ctx *gin.Context, action string) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return primitive.NilObjectID, false
+	}
+
+	current, ok := auth.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return primitive.NilObjectID, false
+	}
+	if current.ID == id {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "You cannot " + action + " your own account"})
+		return primitive.NilObjectID, false
+	}
+	return id, true
+}
+
+// withEffectiveRole fills in the role for accounts created before roles existed
+func withEffectiveRole(u model.User) model.User {
+	u.Role = u.EffectiveRole()
+	return u
+}
@@ -0,0 +1,53 @@
+package user
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// ListUsers returns every account with the same pagination as the task list
+// Query parameters:
+// - page: current page number (default: 1)
+// - limit: number of users per page (default: 10, max 100)
+func (h *Handler) ListUsers(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	users, total, err := h.Users.ListUsers(dbCtx, store.ListOptions{Page: page, Limit: limit})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users: " + err.Error()})
+		return
+	}
+	for i := range users {
+		users[i] = withEffectiveRole(users[i])
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Users retrieved successfully",
+		"users":   users,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": totalPages,
+			"hasMore":    page < totalPages,
+		},
+	})
+}
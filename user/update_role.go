@@ -0,0 +1,54 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshua-takyi/todo/helpers"
+	"github.com/joshua-takyi/todo/model"
+	"github.com/joshua-takyi/todo/store"
+)
+
+// roleRequest is the JSON body accepted by UpdateRole
+type roleRequest struct {
+	Role model.Role `json:"role"`
+}
+
+// UpdateRole changes another user's role (admin, member or viewer)
+// The new role applies on the user's next request, because the auth middleware
+// loads the user from the store every time
+func (h *Handler) UpdateRole(ctx *gin.Context) {
+	id, ok := targetUser(ctx, "change the role of")
+	if !ok {
+		return
+	}
+
+	var req roleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Role.Valid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'admin', 'member' or 'viewer'"})
+		return
+	}
+
+	dbCtx, cancel := helpers.DBContext(ctx)
+	defer cancel()
+
+	updated, err := h.Users.UpdateUserRole(dbCtx, id, req.Role)
+	if errors.Is(err, store.ErrUserNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"user":    updated,
+	})
+}
@@ -0,0 +1,113 @@
+// Package workflow decides which status changes a task may go through
+// The statuses themselves are fixed (see model.Statuses); the allowed moves between
+// them are configured with STATUS_TRANSITIONS so each team can match its own process
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joshua-takyi/todo/model"
+)
+
+// DefaultTransitions is used when STATUS_TRANSITIONS is not set
+// Work can be finished or cancelled from any open status, and finished work can be reopened
+const DefaultTransitions = "todo:in_progress,done,cancelled;" +
+	"in_progress:todo,in_review,done,cancelled;" +
+	"in_review:in_progress,done,cancelled;" +
+	"done:todo,in_progress;" +
+	"cancelled:todo"
+
+// Workflow holds the allowed transitions, from one status to the statuses it may move to
+type Workflow struct {
+	transitions map[model.Status]map[model.Status]bool
+}
+
+// Default returns the workflow described by DefaultTransitions
+func Default() Workflow {
+	w, err := Parse(DefaultTransitions)
+	if err != nil {
+		panic("workflow: invalid DefaultTransitions: " + err.Error())
+	}
+	return w
+}
+
+// FromEnv reads the workflow from STATUS_TRANSITIONS, falling back to Default
+func FromEnv() (Workflow, error) {
+	spec := os.Getenv("STATUS_TRANSITIONS")
+	if strings.TrimSpace(spec) == "" {
+		return Default(), nil
+	}
+	w, err := Parse(spec)
+	if err != nil {
+		return Workflow{}, fmt.Errorf("STATUS_TRANSITIONS: %w", err)
+	}
+	return w, nil
+}
+
+// Parse reads a spec like "todo:in_progress,done;in_progress:done"
+// Each ";"-separated rule lists the statuses the status before ":" may move to
+// Statuses without a rule can't be left once entered
+func Parse(spec string) (Workflow, error) {
+	w := Workflow{transitions: make(map[model.Status]map[model.Status]bool)}
+
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		fromPart, toPart, found := strings.Cut(rule, ":")
+		if !found {
+			return Workflow{}, fmt.Errorf("rule %q must look like from:to1,to2", rule)
+		}
+		from := model.Status(strings.TrimSpace(fromPart))
+		if !from.Valid() {
+			return Workflow{}, fmt.Errorf("unknown status %q", from)
+		}
+		if w.transitions[from] == nil {
+			w.transitions[from] = make(map[model.Status]bool)
+		}
+
+		for _, name := range strings.Split(toPart, ",") {
+			to := model.Status(strings.TrimSpace(name))
+			if to == "" {
+				continue
+			}
+			if !to.Valid() {
+				return Workflow{}, fmt.Errorf("unknown status %q", to)
+			}
+			if to == from {
+				return Workflow{}, fmt.Errorf("status %q cannot move to itself", from)
+			}
+			w.transitions[from][to] = true
+		}
+	}
+	return w, nil
+}
+
+// Allows reports whether a task may move from one status to another
+func (w Workflow) Allows(from, to model.Status) bool {
+	return w.transitions[from][to]
+}
+
+// Next returns the statuses a task in `from` may move to, in workflow order
+func (w Workflow) Next(from model.Status) []model.Status {
+	next := []model.Status{}
+	for _, status := range model.Statuses {
+		if w.Allows(from, status) {
+			next = append(next, status)
+		}
+	}
+	return next
+}
+
+// Transitions returns the whole workflow as status -> allowed next statuses
+func (w Workflow) Transitions() map[model.Status][]model.Status {
+	all := make(map[model.Status][]model.Status, len(model.Statuses))
+	for _, status := range model.Statuses {
+		all[status] = w.Next(status)
+	}
+	return all
+}
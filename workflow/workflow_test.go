@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joshua-takyi/todo/model"
+)
+
+func TestParse(t *testing.T) {
+	w, err := Parse(" todo : in_progress, done ;; in_progress:done,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.Next(model.StatusTodo), []model.Status{model.StatusInProgress, model.StatusDone}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Next(todo) = %v, want %v", got, want)
+	}
+	// Statuses without a rule can't be left
+	if got := w.Next(model.StatusDone); len(got) != 0 {
+		t.Errorf("Next(done) = %v, want none", got)
+	}
+	if w.Allows(model.StatusInProgress, model.StatusTodo) {
+		t.Error("in_progress -> todo allowed without a rule for it")
+	}
+
+	for _, spec := range []string{
+		"todo",
+		"todo:started",
+		"waiting:todo",
+		"todo:done,todo",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) accepted", spec)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	w := Default()
+	tests := []struct {
+		from, to model.Status
+		ok       bool
+	}{
+		{model.StatusTodo, model.StatusInProgress, true},
+		{model.StatusTodo, model.StatusInReview, false},
+		{model.StatusInReview, model.StatusDone, true},
+		{model.StatusDone, model.StatusTodo, true},
+		{model.StatusDone, model.StatusCancelled, false},
+		{model.StatusCancelled, model.StatusTodo, true},
+		{model.StatusCancelled, model.StatusDone, false},
+	}
+	for _, tt := range tests {
+		if got := w.Allows(tt.from, tt.to); got != tt.ok {
+			t.Errorf("Allows(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.ok)
+		}
+	}
+
+	all := w.Transitions()
+	if len(all) != len(model.Statuses) {
+		t.Errorf("Transitions has %d statuses, want %d", len(all), len(model.Statuses))
+	}
+	if got, want := all[model.StatusCancelled], []model.Status{model.StatusTodo}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Transitions()[cancelled] = %v, want %v", got, want)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("STATUS_TRANSITIONS", " ")
+	w, err := FromEnv()
+	if err != nil || !reflect.DeepEqual(w, Default()) {
+		t.Errorf("blank STATUS_TRANSITIONS: got %v, %v; want the default workflow", w, err)
+	}
+
+	t.Setenv("STATUS_TRANSITIONS", "todo:done")
+	w, err = FromEnv()
+	if err != nil || !w.Allows(model.StatusTodo, model.StatusDone) || w.Allows(model.StatusTodo, model.StatusInProgress) {
+		t.Errorf("custom STATUS_TRANSITIONS: got %v, %v", w, err)
+	}
+
+	t.Setenv("STATUS_TRANSITIONS", "todo:nowhere")
+	if _, err := FromEnv(); err == nil {
+		t.Error("invalid STATUS_TRANSITIONS accepted")
+	}
+}